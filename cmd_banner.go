@@ -4,13 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/atotto/clipboard"
 	"github.com/ddmoney420/moji/internal/banner"
+	"github.com/ddmoney420/moji/internal/config"
 	"github.com/ddmoney420/moji/internal/export"
+	"github.com/ddmoney420/moji/internal/figlet"
+	"github.com/ddmoney420/moji/internal/fontgen"
 	"github.com/ddmoney420/moji/internal/gradient"
 	"github.com/ddmoney420/moji/internal/styles"
+	"github.com/ddmoney420/moji/internal/terminal"
+	"github.com/ddmoney420/moji/internal/ux"
 	"github.com/ddmoney420/moji/internal/watch"
 	"github.com/spf13/cobra"
 )
@@ -23,10 +29,47 @@ func newBannerCmd() *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			gradientTheme, _ := cmd.Flags().GetString("gradient")
 			watchFlag, _ := cmd.Flags().GetBool("watch")
+			layoutName, _ := cmd.Flags().GetString("layout")
+			missingName, _ := cmd.Flags().GetString("missing")
+			verticalName, _ := cmd.Flags().GetString("vertical-layout")
+			fit, _ := cmd.Flags().GetBool("fit")
+			controlFiles, _ := cmd.Flags().GetStringSlice("control")
+
+			layout, err := figlet.ParseLayout(layoutName)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			verticalLayout, err := figlet.ParseLayout(verticalName)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			missing, err := figlet.ParseMissingGlyph(missingName)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			var control *figlet.Control
+			if len(controlFiles) > 0 {
+				if control, err = banner.LoadControl(controlFiles...); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					return
+				}
+			}
+			opts := banner.Options{
+				Layout:         layout,
+				VerticalLayout: verticalLayout,
+				Missing:        missing,
+				Width:          widthFlag,
+				Control:        control,
+			}
+			text := strings.ReplaceAll(args[0], `\n`, "\n")
+
 			if watchFlag {
-				handleBannerWatch(args[0], gradientTheme)
+				handleBannerWatch(text, gradientTheme, opts, fit)
 			} else {
-				handleBanner(args[0], gradientTheme)
+				handleBanner(text, gradientTheme, opts, fit)
 			}
 		},
 	}
@@ -34,12 +77,15 @@ func newBannerCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&styleFlag, "style", "s", "none", "Color style")
 	cmd.Flags().StringVarP(&borderFlag, "border", "b", "none", "Border style: single, double, round, bold, ascii, stars, hash")
 	cmd.Flags().StringVarP(&alignFlag, "align", "a", "left", "Text alignment: left, center, right")
-	cmd.Flags().IntVarP(&widthFlag, "width", "w", 0, "Output width (0 for auto)")
-	cmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Save to file (supports .png, .txt)")
-	cmd.Flags().StringVar(&bgColorFlag, "bg", "#282a36", "Background color for PNG export (hex)")
-	cmd.Flags().StringVar(&fgColorFlag, "fg", "#f8f8f2", "Foreground color for PNG export (hex)")
+	cmd.Flags().IntVarP(&widthFlag, "width", "w", 0, "Output width; wraps text to fit (0 for auto)")
+	addOutputFlags(cmd)
 	cmd.Flags().String("gradient", "", "Apply color gradient theme (rainbow, neon, fire, etc.)")
 	cmd.Flags().BoolVar(&watchFlag, "watch", false, "Watch for changes and re-render in real-time")
+	cmd.Flags().String("layout", "default", "Horizontal layout: default (font's own), full, kerning, smush")
+	cmd.Flags().String("missing", "substitute", "Missing glyphs: substitute, transliterate, error")
+	cmd.Flags().String("vertical-layout", "full", "How wrapped lines stack: full, kerning, smush, default (font's own)")
+	cmd.Flags().Bool("fit", false, "Fit the terminal (or --width): try tighter layouts, then narrower fonts, then wrapping")
+	cmd.Flags().StringSliceP("control", "C", nil, "FIGlet control file (.flc) or built-in ("+strings.Join(banner.ListControls(), ", ")+"); repeatable")
 	return cmd
 }
 
@@ -53,6 +99,108 @@ func newListFontsCmd() *cobra.Command {
 	}
 }
 
+func newFontsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fonts",
+		Short: "Manage banner fonts",
+		Long: `List, install, and remove banner fonts.
+
+User fonts are .flf or .tlf files (plain or zip-compressed) in the user
+font directory or in the font_paths listed in the config file. A user font
+with the same name as a built-in font replaces it.
+
+Examples:
+  moji fonts list
+  moji fonts list --category retro --min-height 8
+  moji fonts info slant
+  moji fonts install brand.flf
+  moji fonts install ~/Downloads/logo.flf --name acme
+  moji fonts remove acme
+  moji fonts from-ttf Brand.ttf --size 10 -o brand.flf
+  moji fonts from-ttf gomono --charset halfblock --install`,
+		Run: func(cmd *cobra.Command, args []string) {
+			handleFontsList(banner.FontFilter{})
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List built-in and user fonts",
+		Run: func(cmd *cobra.Command, args []string) {
+			category, _ := cmd.Flags().GetString("category")
+			minHeight, _ := cmd.Flags().GetInt("min-height")
+			handleFontsList(banner.FontFilter{Category: category, MinHeight: minHeight})
+		},
+	}
+	listCmd.Flags().String("category", "", "Only fonts in this category or with this tag")
+	listCmd.Flags().Int("min-height", 0, "Only fonts at least this many rows tall")
+
+	infoCmd := &cobra.Command{
+		Use:   "info [name]",
+		Short: "Show font metadata",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			handleFontsInfo(args[0])
+		},
+	}
+
+	installCmd := &cobra.Command{
+		Use:   "install [file]",
+		Short: "Install a .flf font file",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			name, _ := cmd.Flags().GetString("name")
+			force, _ := cmd.Flags().GetBool("force")
+			handleFontsInstall(args[0], name, force)
+		},
+	}
+	installCmd.Flags().String("name", "", "Font name (defaults to the file name)")
+	installCmd.Flags().Bool("force", false, "Replace an existing font with the same name")
+
+	removeCmd := &cobra.Command{
+		Use:   "remove [name]",
+		Short: "Remove an installed font",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			handleFontsRemove(args[0])
+		},
+	}
+
+	fromTTFCmd := &cobra.Command{
+		Use:   "from-ttf [file]",
+		Short: "Generate a .flf font from a TrueType or OpenType font",
+		Long: `Rasterise a TrueType or OpenType font into a FIGlet font.
+
+The argument is a .ttf/.otf file or one of the embedded Go fonts
+(` + strings.Join(fontgen.GoFontNames(), ", ") + `); goregular is used when it is omitted.
+ASCII and the German umlauts are always generated; --chars adds more
+characters, which are written as code-tagged glyphs.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			source := "goregular"
+			if len(args) > 0 {
+				source = args[0]
+			}
+			size, _ := cmd.Flags().GetInt("size")
+			charset, _ := cmd.Flags().GetString("charset")
+			chars, _ := cmd.Flags().GetString("chars")
+			output, _ := cmd.Flags().GetString("output")
+			install, _ := cmd.Flags().GetBool("install")
+			force, _ := cmd.Flags().GetBool("force")
+			handleFontsFromTTF(source, fontgen.Options{Height: size, Charset: fontgen.Charset(charset), Chars: chars}, output, install, force)
+		},
+	}
+	fromTTFCmd.Flags().Int("size", 8, "Glyph height in rows")
+	fromTTFCmd.Flags().String("charset", string(fontgen.CharsetBlocks), "Characters to draw with: blocks, halfblock, ascii")
+	fromTTFCmd.Flags().String("chars", "", "Extra characters to include")
+	fromTTFCmd.Flags().StringP("output", "o", "", "Output file (defaults to <font>.flf)")
+	fromTTFCmd.Flags().Bool("install", false, "Install the generated font")
+	fromTTFCmd.Flags().Bool("force", false, "With --install, replace an existing font with the same name")
+
+	cmd.AddCommand(listCmd, infoCmd, installCmd, removeCmd, fromTTFCmd)
+	return cmd
+}
+
 func newPreviewCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "preview [text]",
@@ -65,15 +213,15 @@ func newPreviewCmd() *cobra.Command {
 		},
 	}
 	cmd.Flags().IntP("limit", "l", 5, "Number of fonts to preview")
-	cmd.Flags().StringP("category", "c", "", "Font category: 3d, graffiti, retro, big, clean, decorative, fun, small")
+	cmd.Flags().StringP("category", "c", "", "Font category or tag: 3d, graffiti, retro, big, clean, decorative, fun, small, user")
 	return cmd
 }
 
-func handleBannerWatch(text string, gradientTheme string) {
+func handleBannerWatch(text string, gradientTheme string, opts banner.Options, fit bool) {
 	fmt.Println("Watching for changes (Press Ctrl+C to exit)...")
 	renderBanner := func() {
 		fmt.Print("\033[2J\033[H")
-		handleBanner(text, gradientTheme)
+		handleBanner(text, gradientTheme, opts, fit)
 	}
 
 	err := watch.Watch(".", renderBanner)
@@ -82,13 +230,37 @@ func handleBannerWatch(text string, gradientTheme string) {
 	}
 }
 
-func handleBanner(text string, gradientTheme string) {
-	art, err := banner.Generate(text, fontFlag)
+func handleBanner(text string, gradientTheme string, opts banner.Options, fit bool) {
+	fontName := fontFlag
+	var fitResult *banner.FitResult
+	var art string
+	var err error
+	if fit {
+		var result banner.FitResult
+		result, err = banner.Fit(text, fontFlag, bannerFitWidth(), opts, bannerFitFonts())
+		art, fontName, fitResult = result.Art, result.Font, &result
+	} else {
+		art, err = banner.GenerateWithOptions(text, fontFlag, opts)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error generating banner: %v\n", err)
 		return
 	}
 
+	if fitResult != nil && !jsonFlag {
+		if !fitResult.Fits {
+			ux.Warn("Banner is %d columns wide and does not fit %d even when wrapped", fitResult.Width, fitResult.Target)
+		} else if verboseFlag && fitResult.Step != banner.FitAsRequested {
+			ux.Info("Fit %d columns using font '%s' (%s layout, %s)", fitResult.Target, fitResult.Font, fitResult.Layout, fitResult.Step)
+		}
+	}
+
+	if opts.Missing == figlet.MissingSubstitute {
+		if missing, _ := banner.MissingGlyphs(opts.Control.Translate(text), fontName); len(missing) > 0 {
+			ux.Warn("Font '%s' has no glyphs for %q (try --missing transliterate)", fontName, string(missing))
+		}
+	}
+
 	if widthFlag > 0 {
 		art = styles.ApplyAlignment(art, alignFlag, widthFlag)
 	}
@@ -105,35 +277,26 @@ func handleBanner(text string, gradientTheme string) {
 	}
 
 	if outputFlag != "" {
-		lower := strings.ToLower(outputFlag)
-		switch {
-		case strings.HasSuffix(lower, ".png"):
-			if err := export.ToPNG(art, outputFlag, bgColorFlag, fgColorFlag); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to export PNG: %v\n", err)
-				return
-			}
-		case strings.HasSuffix(lower, ".svg"):
-			if err := export.ToSVG(art, outputFlag, bgColorFlag, fgColorFlag); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to export SVG: %v\n", err)
-				return
-			}
-		case strings.HasSuffix(lower, ".html"), strings.HasSuffix(lower, ".htm"):
-			if err := export.ToHTML(art, outputFlag, bgColorFlag, fgColorFlag, text); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to export HTML: %v\n", err)
-				return
-			}
-		default:
-			if err := os.WriteFile(outputFlag, []byte(art), 0644); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to write file: %v\n", err)
-				return
-			}
+		metadata := map[string]string{"text": text, "font": fontName}
+		if gradientTheme != "" {
+			metadata["gradient"] = gradientTheme
+		} else if styleFlag != "none" {
+			metadata["style"] = styleFlag
+		}
+		if err := saveOutput(styledArt, text, metadata); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save banner: %v\n", err)
+			return
 		}
 		fmt.Printf("Saved banner to %s\n", outputFlag)
 		return
 	}
 
 	if jsonFlag {
-		data := map[string]string{"text": text, "font": fontFlag, "style": styleFlag, "output": art}
+		data := map[string]interface{}{"text": text, "font": fontName, "style": styleFlag, "layout": opts.Layout.String(), "output": art}
+		if fitResult != nil {
+			data["layout"] = fitResult.Layout
+			data["fit"] = fitResult
+		}
 		json.NewEncoder(os.Stdout).Encode(data)
 		return
 	}
@@ -149,6 +312,49 @@ func handleBanner(text string, gradientTheme string) {
 	}
 }
 
+// addImageFlags adds the colors and, for .png and .gif output, the text
+// rendering flags used when exporting to images
+func addImageFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&bgColorFlag, "bg", export.DefaultBackground, "Background color for image export (hex)")
+	cmd.Flags().StringVar(&fgColorFlag, "fg", export.DefaultForeground, "Foreground color for image export (hex)")
+	cmd.Flags().Float64Var(&fontSizeFlag, "font-size", export.DefaultFontSize, "Font size in points for image export")
+	cmd.Flags().IntVar(&paddingFlag, "padding", export.DefaultPadding, "Margin in pixels around image export")
+}
+
+// pngOptions returns the PNG rendering options set by the flags
+func pngOptions() export.PNGOptions {
+	return export.PNGOptions{FontSize: fontSizeFlag, DPI: export.DefaultDPI, Padding: paddingFlag}
+}
+
+// bannerFitWidth returns the width banner --fit targets: --width, or the
+// terminal width, less any border
+func bannerFitWidth() int {
+	width := widthFlag
+	if width <= 0 {
+		caps := terminal.Detect()
+		desired := caps.Width
+		if desired <= 0 {
+			desired = 80
+		}
+		// Leave the last column free so terminals don't wrap early
+		width = caps.FitWidth(desired, 1)
+	}
+	if borderFlag != "" && borderFlag != "none" {
+		width -= 4
+	}
+	return width
+}
+
+// bannerFitFonts returns the configured --fit fallback chain, or nil for the
+// built-in one
+func bannerFitFonts() []string {
+	cfg, err := config.Load()
+	if err != nil || len(cfg.Defaults.BannerFitFonts) == 0 {
+		return nil
+	}
+	return cfg.Defaults.BannerFitFonts
+}
+
 func handleListFonts() {
 	fmt.Println("Available fonts:")
 	for _, f := range banner.ListFonts() {
@@ -166,15 +372,166 @@ func handleListFonts() {
 	}
 }
 
-func handlePreview(text string, limit int, category string) {
-	fonts := banner.ListFonts()
-	count := 0
+func handleFontsList(filter banner.FontFilter) {
+	fonts := banner.FilterFonts(banner.Catalog(), filter)
+
+	if jsonFlag {
+		if fonts == nil {
+			fonts = []banner.FontMeta{}
+		}
+		json.NewEncoder(os.Stdout).Encode(fonts)
+		return
+	}
 
+	fmt.Println("Built-in fonts:")
 	for _, f := range fonts {
-		if category != "" && !strings.Contains(strings.ToLower(f.Desc), strings.ToLower(category)) {
+		if f.Source != banner.SourceEmbedded {
 			continue
 		}
+		fmt.Printf("  %-12s %2d rows  %-10s - %s\n", f.Name, f.Height, f.Category, f.Desc)
+	}
+
+	fmt.Printf("\nUser fonts (%s):\n", banner.UserFontDir())
+	userCount := 0
+	for _, f := range fonts {
+		if f.Source == banner.SourceEmbedded {
+			continue
+		}
+		userCount++
+		if f.Error != "" {
+			fmt.Printf("  %-12s - %s [%s] (invalid: %s)\n", f.Name, f.File, f.Source, f.Error)
+			continue
+		}
+		fmt.Printf("  %-12s %2d rows  - %s [%s]\n", f.Name, f.Height, f.File, f.Source)
+	}
+	if userCount == 0 {
+		fmt.Println("  (none) - install one with 'moji fonts install <file.flf>'")
+	}
+}
+
+func handleFontsInfo(name string) {
+	meta, err := banner.FontDetails(name)
+	if err != nil {
+		ux.Error("%v", err)
+		return
+	}
+
+	if jsonFlag {
+		json.NewEncoder(os.Stdout).Encode(meta)
+		return
+	}
+
+	fmt.Printf("Name:      %s\n", meta.Name)
+	if len(meta.Aliases) > 0 {
+		fmt.Printf("Aliases:   %s\n", strings.Join(meta.Aliases, ", "))
+	}
+	fmt.Printf("File:      %s [%s]\n", meta.File, meta.Source)
+	fmt.Printf("About:     %s\n", meta.Desc)
+	fmt.Printf("Category:  %s\n", meta.Category)
+	if len(meta.Tags) > 0 {
+		fmt.Printf("Tags:      %s\n", strings.Join(meta.Tags, ", "))
+	}
+	if meta.Error != "" {
+		fmt.Printf("Error:     %s\n", meta.Error)
+		return
+	}
+
+	direction := "left to right"
+	if meta.RightToLeft {
+		direction = "right to left"
+	}
+	fmt.Printf("Format:    %s\n", meta.Format)
+	fmt.Printf("Height:    %d rows (baseline %d)\n", meta.Height, meta.Baseline)
+	fmt.Printf("Layout:    %s, %s\n", meta.Layout, direction)
+	fmt.Printf("Glyphs:    %d (%d/95 ASCII", meta.Glyphs, meta.ASCII)
+	if meta.Deutsch {
+		fmt.Print(", German umlauts")
+	}
+	if meta.Unicode {
+		fmt.Print(", drawn with Unicode")
+	}
+	fmt.Println(")")
+	if meta.Comment != "" {
+		fmt.Printf("\n%s\n", meta.Comment)
+	}
+}
+
+func handleFontsInstall(path, name string, force bool) {
+	entry, err := banner.InstallFont(path, name, force)
+	if err != nil {
+		ux.Error("%v", err)
+		return
+	}
+	ux.Success("Installed font '%s' to %s", entry.Name, entry.File)
+	fmt.Printf("Try it: moji banner \"Hello\" --font %s\n", entry.Name)
+}
+
+func handleFontsRemove(name string) {
+	if err := banner.RemoveFont(name); err != nil {
+		ux.Error("%v", err)
+		return
+	}
+	ux.Success("Removed font '%s'", name)
+}
+
+func handleFontsFromTTF(source string, opts fontgen.Options, output string, install, force bool) {
+	charset, err := fontgen.ParseCharset(string(opts.Charset))
+	if err != nil {
+		ux.Error("%v", err)
+		return
+	}
+	opts.Charset = charset
+
+	// A file on disk wins over an embedded Go font of the same name
+	data, err := os.ReadFile(source)
+	if err != nil {
+		goFont, ok := fontgen.GoFont(source)
+		if !ok {
+			ux.Error("%v", err)
+			return
+		}
+		data = goFont
+	}
+
+	name := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	opts.Source = filepath.Base(source)
+	font, err := fontgen.FromTTF(data, opts)
+	if err != nil {
+		ux.Error("%v", err)
+		return
+	}
 
+	if output == "" {
+		output = name + ".flf"
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		ux.Error("%v", err)
+		return
+	}
+	if err := figlet.WriteFont(f, font); err != nil {
+		f.Close()
+		ux.Error("write %s: %v", output, err)
+		return
+	}
+	if err := f.Close(); err != nil {
+		ux.Error("write %s: %v", output, err)
+		return
+	}
+	ux.Success("Wrote %d glyphs, %d rows tall, to %s", len(font.Characters), font.Header.Height, output)
+
+	if !install {
+		fmt.Printf("Install it: moji fonts install %s\n", output)
+		return
+	}
+	handleFontsInstall(output, "", force)
+}
+
+func handlePreview(text string, limit int, category string) {
+	fonts := banner.FilterFonts(banner.Catalog(), banner.FontFilter{Category: category})
+	count := 0
+
+	for _, f := range fonts {
 		art, err := banner.Generate(text, f.Name)
 		if err != nil {
 			continue
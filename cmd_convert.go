@@ -1,17 +1,29 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"image"
+	"image/color"
 	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/atotto/clipboard"
+	"github.com/ddmoney420/moji/internal/animate"
 	"github.com/ddmoney420/moji/internal/batch"
+	"github.com/ddmoney420/moji/internal/config"
 	"github.com/ddmoney420/moji/internal/convert"
-	"github.com/ddmoney420/moji/internal/export"
+	"github.com/ddmoney420/moji/internal/dither"
+	"github.com/ddmoney420/moji/internal/fetch"
 	"github.com/ddmoney420/moji/internal/imgproto"
+	"github.com/ddmoney420/moji/internal/terminal"
+	"github.com/ddmoney420/moji/internal/themes"
 	"github.com/ddmoney420/moji/internal/ux"
 	"github.com/ddmoney420/moji/internal/watch"
 	"github.com/spf13/cobra"
@@ -30,7 +42,19 @@ Examples:
   moji convert --url https://example.com/cat.png
   moji convert photo.png --width 100 --edge
   moji convert photo.png --color --charset blocks
-  moji convert photo.png -o art.txt`,
+  moji convert lineart.png --dither floyd-steinberg
+  moji convert photo.png --dither bayer8x8 --levels 4
+  moji convert photo.png --mode shape
+  moji convert photo.png --mode quadrant --color
+  moji convert photo.png --color --colors 256 --color-dither floyd-steinberg
+  moji convert photo.png --bg-color --charset detailed
+  moji convert scan.jpg --auto-levels --contrast 0.3 --sharpen 1
+  moji convert logo.png --background "#ffffff" --crop 10,10,200,100 --rotate 90
+  moji convert logo.png --mode braille --threshold 0.4 --dither atkinson
+  moji convert photo.png -o art.txt
+  moji convert photo.png --color -o art.html
+  moji convert anim.gif --play --loops 3
+  moji convert anim.gif --mode braille -o anim.json   # then: moji animate anim.json`,
 		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			url, _ := cmd.Flags().GetString("url")
@@ -39,9 +63,49 @@ Examples:
 			charset, _ := cmd.Flags().GetString("charset")
 			edge, _ := cmd.Flags().GetBool("edge")
 			colorFlag, _ := cmd.Flags().GetBool("color")
+			bgColor, _ := cmd.Flags().GetBool("bg-color")
 			invert, _ := cmd.Flags().GetBool("invert")
 			protocol, _ := cmd.Flags().GetString("protocol")
 			watchFlag, _ := cmd.Flags().GetBool("watch")
+			levels, _ := cmd.Flags().GetInt("levels")
+			modeName, _ := cmd.Flags().GetString("mode")
+			threshold, _ := cmd.Flags().GetFloat64("threshold")
+			play, _ := cmd.Flags().GetBool("play")
+			loops, _ := cmd.Flags().GetInt("loops")
+			algo, err := convertDither(cmd)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			mode, err := convert.ParseMode(modeName)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			colorLevel, colorDither, err := convertColors(cmd)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			brightnessWeight, _ := cmd.Flags().GetFloat64("brightness-weight")
+			edgeThreshold, _ := cmd.Flags().GetFloat64("edge-threshold")
+			if edgeThreshold < 0 || edgeThreshold > 1 {
+				fmt.Fprintf(os.Stderr, "Error: --edge-threshold must be between 0 and 1, got %g\n", edgeThreshold)
+				return
+			}
+			if edgeThreshold == 0 {
+				edgeThreshold = convert.NoEdgeThreshold
+			}
+			edgeStyleName, _ := cmd.Flags().GetString("edge-style")
+			edgeStyle, err := convert.ParseEdgeStyle(edgeStyleName)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			charsetChars := convert.GetCharset(charset)
+			if mode == convert.ModeShape && !cmd.Flags().Changed("charset") {
+				charsetChars = "" // all of printable ASCII
+			}
 
 			if len(args) == 0 && url == "" {
 				fmt.Fprintln(os.Stderr, "Error: provide an image file or --url")
@@ -54,22 +118,69 @@ Examples:
 				source = args[0]
 			}
 
-			if watchFlag && source != "" {
-				handleConvertWatch(source, url, width, height, charset, edge, colorFlag, invert, protocol)
+			opts := convert.Options{
+				Width:            width,
+				Height:           height,
+				Charset:          charsetChars,
+				EdgeDetect:       edge,
+				EdgeThreshold:    edgeThreshold,
+				EdgeStyle:        edgeStyle,
+				Color:            (colorFlag || bgColor) && colorLevel != terminal.NoColor,
+				BgColor:          bgColor,
+				Invert:           invert,
+				Dither:           algo,
+				Levels:           levels,
+				Mode:             mode,
+				Threshold:        threshold,
+				BrightnessWeight: brightnessWeight,
+				ColorLevel:       colorLevel,
+				ColorDither:      colorDither,
+			}
+
+			if err := preprocessOptions(cmd, &opts); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+
+			if noCache, _ := cmd.Flags().GetBool("no-cache"); noCache {
+				convert.SetFetcher(fetch.New(fetch.Options{}))
+			}
+
+			animated := strings.EqualFold(filepath.Ext(source), ".gif") && strings.EqualFold(filepath.Ext(outputFlag), ".json") && formatFlag == ""
+			if (play || animated) && source == "" {
+				fmt.Fprintln(os.Stderr, "Error: --play needs an image file")
+				return
+			}
+
+			if play || animated {
+				handleConvertAnimation(source, opts, play, loops)
+			} else if watchFlag && source != "" {
+				handleConvertWatch(source, url, opts, protocol)
 			} else {
-				handleConvert(source, url, width, height, charset, edge, colorFlag, invert, protocol)
+				handleConvert(source, url, opts, protocol)
 			}
 		},
 	}
 	cmd.Flags().String("url", "", "Image URL to convert")
+	cmd.Flags().Bool("no-cache", false, "Download --url images again instead of using the cache")
 	cmd.Flags().Int("width", 80, "Output width in characters")
 	cmd.Flags().Int("height", 0, "Output height (0 = auto based on aspect ratio)")
 	cmd.Flags().String("charset", "standard", "Character set: standard, blocks, simple, detailed, binary, dots, ascii, shade")
-	cmd.Flags().Bool("edge", false, "Use edge detection for line art style")
+	cmd.Flags().Bool("edge", false, "Draw edges as lines (| / - \\ _) for a line drawing")
+	cmd.Flags().Float64("edge-threshold", convert.DefaultEdgeThreshold, "Edge strength (0-1) below which --edge leaves cells blank")
+	cmd.Flags().String("edge-style", "ascii", "Line glyphs for --edge: "+strings.Join(convert.ListEdgeStyles(), ", "))
 	cmd.Flags().Bool("color", false, "Preserve colors using ANSI codes")
+	addColorFlags(cmd)
 	cmd.Flags().Bool("invert", false, "Invert brightness (for light backgrounds)")
 	cmd.Flags().String("protocol", "ascii", "Image protocol: ascii, sixel, kitty, iterm2, auto")
-	cmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Save to file")
+	cmd.Flags().String("mode", "charset", "Cell mode: "+strings.Join(convert.ListModes(), ", ")+" (shape matches glyph outlines; sub-cell modes draw pixel patterns)")
+	cmd.Flags().Float64("threshold", 0.5, "Brightness (0-1) above which a sub-cell pixel is on")
+	cmd.Flags().Float64("brightness-weight", 0.3, "Shape mode: weight of brightness against shape (0-1)")
+	cmd.Flags().Bool("play", false, "Play an animated GIF in place, frame by frame")
+	cmd.Flags().Int("loops", 0, "With --play, times to loop the animation (0 = until Ctrl+C)")
+	addDitherFlags(cmd)
+	addPreprocessFlags(cmd)
+	addOutputFlags(cmd)
 	cmd.Flags().BoolVar(&watchFlag, "watch", false, "Watch for changes and re-render in real-time")
 	return cmd
 }
@@ -93,7 +204,9 @@ func newBatchCmd() *cobra.Command {
 Examples:
   moji batch "*.jpg" "*.png"
   moji batch photos/*.jpg --width 60 --output-dir ascii_art
-  moji batch *.png --charset blocks --workers 8`,
+  moji batch *.png --charset blocks --workers 8
+  moji batch scans/*.png --dither atkinson --levels 3
+  moji batch *.png --color --colors 16 --color-dither atkinson`,
 		Args: cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			width, _ := cmd.Flags().GetInt("width")
@@ -102,7 +215,30 @@ Examples:
 			outDir, _ := cmd.Flags().GetString("output-dir")
 			workers, _ := cmd.Flags().GetInt("workers")
 			color, _ := cmd.Flags().GetBool("color")
-			handleBatch(args, width, height, charset, outDir, workers, color)
+			bgColor, _ := cmd.Flags().GetBool("bg-color")
+			levels, _ := cmd.Flags().GetInt("levels")
+			algo, err := convertDither(cmd)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			colorLevel, colorDither, err := convertColors(cmd)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return
+			}
+			opts := convert.Options{
+				Width:       width,
+				Height:      height,
+				Charset:     convert.GetCharset(charset),
+				Color:       (color || bgColor) && colorLevel != terminal.NoColor,
+				BgColor:     bgColor,
+				Dither:      algo,
+				Levels:      levels,
+				ColorLevel:  colorLevel,
+				ColorDither: colorDither,
+			}
+			handleBatch(args, opts, outDir, workers)
 		},
 	}
 	cmd.Flags().Int("width", 80, "Output width")
@@ -111,14 +247,115 @@ Examples:
 	cmd.Flags().String("output-dir", "", "Output directory (prints to stdout if empty)")
 	cmd.Flags().Int("workers", 4, "Number of concurrent workers")
 	cmd.Flags().Bool("color", false, "Preserve colors")
+	addColorFlags(cmd)
+	addDitherFlags(cmd)
 	return cmd
 }
 
-func handleConvertWatch(file, url string, width, height int, charset string, edge, color, invert bool, protocol string) {
+// addDitherFlags adds the --dither and --levels flags shared by convert and batch
+func addDitherFlags(cmd *cobra.Command) {
+	cmd.Flags().String("dither", "none", "Dithering across the charset: "+strings.Join(dither.ListAlgorithms(), ", ")+" (default from convert_dither in config)")
+	cmd.Flags().Int("levels", 0, "Number of charset characters to quantize to (0 = all)")
+}
+
+// convertDither returns the --dither algorithm, falling back to the
+// convert_dither config default when the flag isn't set
+func convertDither(cmd *cobra.Command) (dither.Algorithm, error) {
+	name, _ := cmd.Flags().GetString("dither")
+	if !cmd.Flags().Changed("dither") {
+		if cfg, err := config.Load(); err == nil && cfg.Defaults.ConvertDither != "" {
+			name = cfg.Defaults.ConvertDither
+		}
+	}
+	return dither.ParseAlgorithm(name)
+}
+
+// addPreprocessFlags adds the flags that adjust the source image before it
+// is converted
+func addPreprocessFlags(cmd *cobra.Command) {
+	cmd.Flags().Float64("brightness", 0, "Brightness adjustment (-1 to 1)")
+	cmd.Flags().Float64("contrast", 0, "Contrast adjustment (-1 to 1)")
+	cmd.Flags().Float64("gamma", 1, "Gamma correction (above 1 brightens midtones)")
+	cmd.Flags().Bool("auto-levels", false, "Stretch each color channel to the full range")
+	cmd.Flags().String("crop", "", "Crop to x,y,width,height in pixels")
+	cmd.Flags().Int("rotate", 0, "Rotate clockwise: 90, 180 or 270 degrees")
+	cmd.Flags().Bool("flip-h", false, "Mirror the image left to right")
+	cmd.Flags().Bool("flip-v", false, "Mirror the image top to bottom")
+	cmd.Flags().Float64("sharpen", 0, "Unsharp mask amount (0 = off, 1 = strong)")
+	cmd.Flags().Float64("sharpen-radius", 1, "Unsharp mask radius in pixels")
+	cmd.Flags().String("background", "", "Background color for transparent pixels, e.g. #ffffff (default black)")
+}
+
+// preprocessOptions reads the preprocessing flags into opts
+func preprocessOptions(cmd *cobra.Command, opts *convert.Options) error {
+	opts.Brightness, _ = cmd.Flags().GetFloat64("brightness")
+	opts.Contrast, _ = cmd.Flags().GetFloat64("contrast")
+	opts.Gamma, _ = cmd.Flags().GetFloat64("gamma")
+	opts.AutoLevels, _ = cmd.Flags().GetBool("auto-levels")
+	opts.Rotate, _ = cmd.Flags().GetInt("rotate")
+	opts.FlipH, _ = cmd.Flags().GetBool("flip-h")
+	opts.FlipV, _ = cmd.Flags().GetBool("flip-v")
+	opts.Sharpen, _ = cmd.Flags().GetFloat64("sharpen")
+	opts.SharpenRadius, _ = cmd.Flags().GetFloat64("sharpen-radius")
+
+	if opts.Rotate%90 != 0 {
+		return fmt.Errorf("--rotate must be a multiple of 90, got %d", opts.Rotate)
+	}
+	if opts.Gamma <= 0 {
+		return fmt.Errorf("--gamma must be positive, got %g", opts.Gamma)
+	}
+
+	if crop, _ := cmd.Flags().GetString("crop"); crop != "" {
+		rect, err := convert.ParseCrop(crop)
+		if err != nil {
+			return err
+		}
+		opts.Crop = rect
+	}
+
+	if bg, _ := cmd.Flags().GetString("background"); bg != "" {
+		r, g, b, err := themes.HexToRGB("#" + strings.TrimPrefix(bg, "#"))
+		if err != nil {
+			return fmt.Errorf("--background: %w", err)
+		}
+		opts.Background = color.RGBA{r, g, b, 255}
+	}
+	return nil
+}
+
+// addColorFlags adds the --colors and --color-dither flags shared by convert and batch
+func addColorFlags(cmd *cobra.Command) {
+	cmd.Flags().String("colors", "auto", "Color palette for --color: auto (detect), truecolor, 256, 16, none")
+	cmd.Flags().String("color-dither", "none", "Dithering of colors reduced to 256 or 16: "+strings.Join(dither.ListAlgorithms(), ", "))
+	cmd.Flags().Bool("bg-color", false, "Color cell backgrounds, drawing glyphs in a darker shade (implies --color)")
+}
+
+// convertColors returns the --colors level and --color-dither algorithm.
+// When auto detection finds no color support, an explicit --color still
+// gets 24-bit color; only --colors none turns it off.
+func convertColors(cmd *cobra.Command) (terminal.ColorLevel, dither.Algorithm, error) {
+	name, _ := cmd.Flags().GetString("colors")
+	level, err := terminal.ParseColorLevel(name)
+	if err != nil {
+		return terminal.NoColor, dither.None, err
+	}
+	if auto := strings.ToLower(strings.TrimSpace(name)); (auto == "" || auto == "auto") && level == terminal.NoColor {
+		level = terminal.TrueColor
+	}
+
+	ditherName, _ := cmd.Flags().GetString("color-dither")
+	algo, err := dither.ParseAlgorithm(ditherName)
+	if err != nil {
+		return terminal.NoColor, dither.None, err
+	}
+	return level, algo, nil
+}
+
+func handleConvertWatch(file, url string, opts convert.Options, protocol string) {
 	fmt.Println("Watching for changes (Press Ctrl+C to exit)...")
 	renderConvert := func() {
 		fmt.Print("\033[2J\033[H")
-		handleConvert(file, url, width, height, charset, edge, color, invert, protocol)
+		handleConvert(file, url, opts, protocol)
 	}
 
 	err := watch.Watch(file, renderConvert)
@@ -127,7 +364,46 @@ func handleConvertWatch(file, url string, width, height int, charset string, edg
 	}
 }
 
-func handleConvert(file, url string, width, height int, charset string, edge, color, invert bool, protocol string) {
+func handleConvertAnimation(file string, opts convert.Options, play bool, loops int) {
+	anim, err := convert.LoadAnimationFile(file)
+	if err != nil {
+		ux.Error("Failed to load image: %v", err)
+		return
+	}
+
+	spinner := ux.NewSpinner(fmt.Sprintf("Converting %d frames", len(anim.Frames)))
+	spinner.Start()
+	frames, err := convert.FromAnimation(anim, opts)
+	if err != nil {
+		spinner.StopFail("Failed to convert image")
+		ux.Error("Failed to convert image: %v", err)
+		return
+	}
+	spinner.StopSuccess(fmt.Sprintf("Converted %d frames", len(frames)))
+
+	bundle := &animate.Bundle{Frames: frames}
+	for _, frame := range anim.Frames {
+		bundle.Delays = append(bundle.Delays, int(frame.Delay.Milliseconds()))
+	}
+	// GIF loop counts are repeats after the first play, with -1 for none
+	if anim.LoopCount > 0 {
+		bundle.Loops = anim.LoopCount + 1
+	} else if anim.LoopCount < 0 {
+		bundle.Loops = 1
+	}
+
+	if outputFlag != "" && !saveAnimation(outputFlag, bundle) {
+		return
+	}
+
+	if play {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		animate.PlayBundle(ctx, os.Stdout, bundle, loops)
+	}
+}
+
+func handleConvert(file, url string, opts convert.Options, protocol string) {
 	proto := imgproto.ParseProtocol(protocol)
 
 	if proto != imgproto.ASCII {
@@ -152,21 +428,16 @@ func handleConvert(file, url string, width, height int, charset string, edge, co
 			}
 		}
 
-		if err := imgproto.WriteToTerminal(img, proto, width); err != nil {
+		if img, err = convert.Preprocess(img, opts); err != nil {
+			ux.Error("Failed to preprocess image: %v", err)
+			return
+		}
+		if err := imgproto.WriteToTerminal(img, proto, opts.Width); err != nil {
 			ux.Error("Failed to render image: %v", err)
 		}
 		return
 	}
 
-	opts := convert.Options{
-		Width:      width,
-		Height:     height,
-		Charset:    convert.GetCharset(charset),
-		EdgeDetect: edge,
-		Color:      color,
-		Invert:     invert,
-	}
-
 	var art string
 	var err error
 
@@ -190,19 +461,16 @@ func handleConvert(file, url string, width, height int, charset string, edge, co
 	}
 
 	if outputFlag != "" {
-		if strings.HasSuffix(strings.ToLower(outputFlag), ".png") {
-			if err := export.ToPNG(art, outputFlag, bgColorFlag, fgColorFlag); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to export PNG: %v\n", err)
-				return
-			}
-			fmt.Printf("Saved to %s\n", outputFlag)
-		} else {
-			if err := os.WriteFile(outputFlag, []byte(art), 0644); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to write file: %v\n", err)
-				return
-			}
-			fmt.Printf("Saved to %s\n", outputFlag)
+		source, title := file, strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		if url != "" {
+			source, title = url, path.Base(url)
+		}
+		metadata := map[string]string{"source": source, "mode": string(opts.Mode), "width": strconv.Itoa(opts.Width)}
+		if err := saveOutput(art, title, metadata); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save: %v\n", err)
+			return
 		}
+		fmt.Printf("Saved to %s\n", outputFlag)
 		return
 	}
 
@@ -287,14 +555,7 @@ func handleListCharsets() {
 	}
 }
 
-func handleBatch(filePatterns []string, width, height int, charset, outDir string, workers int, color bool) {
-	opts := convert.Options{
-		Width:   width,
-		Height:  height,
-		Charset: convert.GetCharset(charset),
-		Color:   color,
-	}
-
+func handleBatch(filePatterns []string, opts convert.Options, outDir string, workers int) {
 	spinner := ux.NewSpinner("Processing images")
 	spinner.Start()
 	results := batch.ConvertImages(filePatterns, opts, workers)
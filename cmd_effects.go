@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/atotto/clipboard"
+	"github.com/ddmoney420/moji/internal/animate"
 	"github.com/ddmoney420/moji/internal/effects"
 	"github.com/ddmoney420/moji/internal/filters"
 	"github.com/spf13/cobra"
@@ -45,7 +46,8 @@ Examples:
   moji filter rainbow "Hello World"
   moji filter metal,border "Text"
   echo "Hello" | moji filter glitch
-  moji banner "Hi" | moji filter neon`,
+  moji banner "Hi" | moji filter neon
+  moji filter fire "Hot" -o hot.ans --author me`,
 		Args: cobra.MaximumNArgs(2),
 		Run: func(cmd *cobra.Command, args []string) {
 			listFlag, _ := cmd.Flags().GetBool("list")
@@ -72,6 +74,7 @@ Examples:
 		},
 	}
 	cmd.Flags().Bool("list", false, "List available filters")
+	addOutputFlags(cmd)
 	return cmd
 }
 
@@ -79,7 +82,15 @@ func newLolcatCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "lolcat [text]",
 		Short: "Rainbow color text (lolcat-style)",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Rainbow color text, lolcat-style. With -o, the rainbow is saved in any
+export format; with --animate as well, it is saved as an animated GIF or a
+frame bundle for moji animate.
+
+Examples:
+  moji lolcat "Hello, World!"
+  fortune | moji lolcat --animate
+  moji lolcat --animate "Hello" -o rainbow.gif`,
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			animateFlag, _ := cmd.Flags().GetBool("animate")
 			speed, _ := cmd.Flags().GetFloat64("speed")
@@ -97,6 +108,7 @@ func newLolcatCmd() *cobra.Command {
 	}
 	cmd.Flags().BoolP("animate", "a", false, "Animate the rainbow")
 	cmd.Flags().Float64P("speed", "s", 0.1, "Animation speed")
+	addOutputFlags(cmd)
 	return cmd
 }
 
@@ -139,6 +151,16 @@ func handleFilter(filterSpec, text string) {
 	filterNames := filters.ParseChain(filterSpec)
 	result := filters.Chain(text, filterNames)
 
+	if outputFlag != "" {
+		metadata := map[string]string{"filters": strings.Join(filterNames, ",")}
+		if err := saveOutput(result, strings.TrimSpace(stripANSI(text)), metadata); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save: %v\n", err)
+			return
+		}
+		fmt.Printf("Saved to %s\n", outputFlag)
+		return
+	}
+
 	if copyFlag {
 		plain := stripANSI(result)
 		if err := clipboard.WriteAll(plain); err != nil {
@@ -150,29 +172,66 @@ func handleFilter(filterSpec, text string) {
 	fmt.Println(result)
 }
 
-func handleLolcat(text string, animate bool, speed float64) {
-	if animate {
+// Animated lolcat shows lolcatCycles frames, lolcatDelayMs apart
+const (
+	lolcatCycles  = 50
+	lolcatDelayMs = 50
+)
+
+func handleLolcat(text string, animated bool, speed float64) {
+	if outputFlag != "" && animated {
+		saveAnimation(outputFlag, lolcatBundle(text, speed))
+		return
+	}
+	if outputFlag != "" {
+		if err := saveOutput(filters.Rainbow(text), strings.TrimSpace(text), nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save: %v\n", err)
+			return
+		}
+		fmt.Printf("Saved to %s\n", outputFlag)
+		return
+	}
+
+	if animated {
 		lines := strings.Split(text, "\n")
-		for cycle := 0; cycle < 50; cycle++ {
+		for cycle := 0; cycle < lolcatCycles; cycle++ {
 			fmt.Print("\033[H")
-			for lineIdx, line := range lines {
-				for i, r := range line {
-					if r == ' ' || r == '\t' {
-						fmt.Print(string(r))
-						continue
-					}
-					phase := (float64(i+lineIdx) + float64(cycle)*speed) * 0.1
-					red := uint8(math.Sin(phase)*127 + 128)
-					green := uint8(math.Sin(phase+2*math.Pi/3)*127 + 128)
-					blue := uint8(math.Sin(phase+4*math.Pi/3)*127 + 128)
-					fmt.Printf("\033[38;2;%d;%d;%dm%c\033[0m", red, green, blue, r)
-				}
-				fmt.Println()
-			}
-			time.Sleep(50 * time.Millisecond)
+			fmt.Print(lolcatFrame(lines, cycle, speed))
+			time.Sleep(lolcatDelayMs * time.Millisecond)
 		}
 	} else {
 		result := filters.Rainbow(text)
 		fmt.Println(result)
 	}
 }
+
+// lolcatBundle returns the frames of animated lolcat, looping forever
+func lolcatBundle(text string, speed float64) *animate.Bundle {
+	lines := strings.Split(text, "\n")
+	bundle := &animate.Bundle{Delays: []int{lolcatDelayMs}}
+	for cycle := 0; cycle < lolcatCycles; cycle++ {
+		bundle.Frames = append(bundle.Frames, lolcatFrame(lines, cycle, speed))
+	}
+	return bundle
+}
+
+// lolcatFrame returns lines in a rainbow shifted along by cycle steps of
+// speed
+func lolcatFrame(lines []string, cycle int, speed float64) string {
+	var sb strings.Builder
+	for lineIdx, line := range lines {
+		for i, r := range line {
+			if r == ' ' || r == '\t' {
+				sb.WriteRune(r)
+				continue
+			}
+			phase := (float64(i+lineIdx) + float64(cycle)*speed) * 0.1
+			red := uint8(math.Sin(phase)*127 + 128)
+			green := uint8(math.Sin(phase+2*math.Pi/3)*127 + 128)
+			blue := uint8(math.Sin(phase+4*math.Pi/3)*127 + 128)
+			fmt.Fprintf(&sb, "\033[38;2;%d;%d;%dm%c\033[0m", red, green, blue, r)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -1,18 +1,25 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/atotto/clipboard"
 	"github.com/ddmoney420/moji/internal/animate"
 	"github.com/ddmoney420/moji/internal/artdb"
+	"github.com/ddmoney420/moji/internal/banner"
 	"github.com/ddmoney420/moji/internal/calendar"
+	"github.com/ddmoney420/moji/internal/cast"
 	"github.com/ddmoney420/moji/internal/config"
 	"github.com/ddmoney420/moji/internal/demo"
+	"github.com/ddmoney420/moji/internal/export"
 	"github.com/ddmoney420/moji/internal/gradient"
 	"github.com/ddmoney420/moji/internal/patterns"
 	"github.com/ddmoney420/moji/internal/qrcode"
@@ -143,15 +150,21 @@ func newListPatternsCmd() *cobra.Command {
 
 func newAnimateCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "animate [preset]",
+		Use:   "animate [preset|bundle.json]",
 		Short: "Display animated ASCII effects",
-		Long: `Display animated ASCII effects and spinners.
+		Long: `Display animated ASCII effects and spinners, or play a frame bundle
+saved by moji convert. With -o, the animation is saved as an animated GIF
+or a frame bundle instead of played; saved animations loop forever unless
+--loops is set.
 
 Examples:
   moji animate spinner --loops 5
+  moji animate anim.json
   moji animate dots --text "Loading..."
   moji animate --typewriter "Hello, World!"
-  moji animate --scroll "This is scrolling text"`,
+  moji animate --scroll "This is scrolling text"
+  moji animate --matrix --width 60 --duration 3000
+  moji animate spinner --text Loading -o loading.gif`,
 		Run: func(cmd *cobra.Command, args []string) {
 			listFlag, _ := cmd.Flags().GetBool("list")
 			loops, _ := cmd.Flags().GetInt("loops")
@@ -161,6 +174,12 @@ Examples:
 			scroll, _ := cmd.Flags().GetString("scroll")
 			width, _ := cmd.Flags().GetInt("width")
 			blink, _ := cmd.Flags().GetString("blink")
+			matrix, _ := cmd.Flags().GetBool("matrix")
+			durationMs, _ := cmd.Flags().GetInt("duration")
+
+			if outputFlag != "" && !cmd.Flags().Changed("loops") {
+				loops = 0 // saved animations loop forever
+			}
 
 			if listFlag {
 				handleAnimateList()
@@ -170,6 +189,13 @@ Examples:
 				handleScroll(scroll, width, loops, delayMs)
 			} else if blink != "" {
 				handleBlink(blink, loops, delayMs)
+			} else if matrix {
+				handleMatrix(width, durationMs)
+			} else if len(args) > 0 && isBundleFile(args[0]) {
+				if !cmd.Flags().Changed("loops") {
+					loops = -1 // the bundle's own loop count
+				}
+				handleAnimateBundle(args[0], loops)
 			} else if len(args) > 0 {
 				handleAnimate(args[0], text, loops, delayMs)
 			} else {
@@ -184,7 +210,34 @@ Examples:
 	cmd.Flags().String("typewriter", "", "Text to type out character by character")
 	cmd.Flags().String("scroll", "", "Text to scroll horizontally")
 	cmd.Flags().String("blink", "", "Text to blink")
-	cmd.Flags().Int("width", 40, "Width for scroll animation")
+	cmd.Flags().Bool("matrix", false, "Matrix rain")
+	cmd.Flags().Int("duration", 5000, "Duration of matrix rain (ms)")
+	cmd.Flags().Int("width", 40, "Width for scroll animation and matrix rain")
+	cmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Save to file: .gif or .json frame bundle")
+	addImageFlags(cmd)
+	return cmd
+}
+
+func newPlayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "play <file.cast>",
+		Short: "Replay an asciinema recording",
+		Long: `Replay an asciinema (asciicast v2) recording in the terminal, such as one
+made with --record.
+
+Examples:
+  moji banner "Hello" --gradient rainbow --record hello.cast
+  moji play hello.cast
+  moji play demo.cast --speed 2 --idle-limit 1`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			speed, _ := cmd.Flags().GetFloat64("speed")
+			idleLimit, _ := cmd.Flags().GetFloat64("idle-limit")
+			handlePlay(args[0], speed, idleLimit)
+		},
+	}
+	cmd.Flags().Float64("speed", 1, "Playback speed multiplier")
+	cmd.Flags().Float64("idle-limit", 0, "Longest pause between output in seconds (0 = as recorded)")
 	return cmd
 }
 
@@ -292,6 +345,7 @@ Checks:
   - Unicode/UTF-8 locale
   - Clipboard availability
   - Shell type
+  - Font cache statistics (with --verbose)
 
 This is helpful for troubleshooting display or functionality issues.`,
 		Run: func(cmd *cobra.Command, args []string) {
@@ -496,6 +550,7 @@ func handleAnimateList() {
 	fmt.Println("  --typewriter  Type text character by character")
 	fmt.Println("  --scroll      Scroll text horizontally")
 	fmt.Println("  --blink       Blink text on and off")
+	fmt.Println("  --matrix      Matrix rain")
 }
 
 func handleAnimate(preset, text string, loops, delayMs int) {
@@ -505,6 +560,11 @@ func handleAnimate(preset, text string, loops, delayMs int) {
 		return
 	}
 
+	if outputFlag != "" {
+		saveAnimation(outputFlag, animate.PresetBundle(frames, text, loops, delayMs))
+		return
+	}
+
 	if text != "" {
 		animate.PlayWithText(frames, text, loops, delayMs)
 	} else {
@@ -513,10 +573,45 @@ func handleAnimate(preset, text string, loops, delayMs int) {
 	fmt.Println()
 }
 
+// isBundleFile reports whether an animate argument names a frame bundle
+// rather than a preset
+func isBundleFile(arg string) bool {
+	if _, ok := animate.GetPreset(arg); ok {
+		return false
+	}
+	info, err := os.Stat(arg)
+	return err == nil && !info.IsDir()
+}
+
+func handleAnimateBundle(path string, loops int) {
+	bundle, err := animate.LoadBundle(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+	if loops < 0 {
+		loops = bundle.Loops
+	}
+
+	if outputFlag != "" {
+		bundle.Loops = loops
+		saveAnimation(outputFlag, bundle)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	animate.PlayBundle(ctx, os.Stdout, bundle, loops)
+}
+
 func handleTypewriter(text string, delayMs int) {
 	if delayMs <= 0 {
 		delayMs = 50
 	}
+	if outputFlag != "" {
+		saveAnimation(outputFlag, animate.TypewriterBundle(text, delayMs))
+		return
+	}
 	animate.Typewriter(text, delayMs)
 }
 
@@ -524,6 +619,10 @@ func handleScroll(text string, width, loops, delayMs int) {
 	if delayMs <= 0 {
 		delayMs = 100
 	}
+	if outputFlag != "" {
+		saveAnimation(outputFlag, animate.ScrollBundle(text, width, loops, delayMs))
+		return
+	}
 	animate.ScrollText(text, width, loops, delayMs)
 }
 
@@ -531,9 +630,63 @@ func handleBlink(text string, loops, delayMs int) {
 	if delayMs <= 0 {
 		delayMs = 500
 	}
+	if outputFlag != "" {
+		saveAnimation(outputFlag, animate.BlinkBundle(text, loops, delayMs))
+		return
+	}
 	animate.Blink(text, loops, delayMs)
 }
 
+// matrixDropLength is how many rows a matrix rain drop falls before it ends
+const matrixDropLength = 20
+
+func handleMatrix(width, durationMs int) {
+	if width <= 0 {
+		width = 40
+	}
+	if outputFlag != "" {
+		saveAnimation(outputFlag, animate.MatrixRainBundle(width, matrixDropLength, durationMs, time.Now().UnixNano()))
+		return
+	}
+	animate.MatrixRain(width, matrixDropLength, durationMs)
+}
+
+// saveAnimation saves an animation as an animated GIF, or as a frame
+// bundle for moji animate when the path ends in .json, and reports whether
+// it was saved
+func saveAnimation(path string, bundle *animate.Bundle) bool {
+	var err error
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".gif":
+		err = export.ToGIF(bundle.Frames, bundle.Delays, bundle.Loops, path, bgColorFlag, fgColorFlag, pngOptions())
+	case ".json":
+		err = animate.SaveBundle(path, bundle)
+	default:
+		err = fmt.Errorf("unsupported animation format %q (use .gif or .json)", ext)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save animation: %v\n", err)
+		return false
+	}
+	fmt.Printf("Saved %d frames to %s\n", len(bundle.Frames), path)
+	return true
+}
+
+func handlePlay(path string, speed, idleLimit float64) {
+	recording, err := cast.Load(path)
+	if err != nil {
+		ux.Error("%v", err)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	cast.Play(ctx, os.Stdout, recording, cast.PlayOptions{
+		Speed:     speed,
+		IdleLimit: time.Duration(idleLimit * float64(time.Second)),
+	})
+}
+
 func handleSysinfo(artName, gradientTheme string) {
 	info := sysinfo.Collect()
 
@@ -678,5 +831,26 @@ func handleTermInfo() {
 
 func handleDoctor() {
 	checks := ux.Doctor()
+	if verboseFlag {
+		checks = append(checks, checkFontCache())
+	}
 	fmt.Print(ux.FormatDoctorReport(checks))
 }
+
+// checkFontCache renders a test banner and reports the parsed font cache
+func checkFontCache() ux.Check {
+	for i := 0; i < 2; i++ {
+		if _, err := banner.Generate("moji", "standard"); err != nil {
+			return ux.Check{Name: "Font cache", Status: ux.CheckFail, Message: "Cannot render banners", Detail: err.Error()}
+		}
+	}
+
+	stats := banner.CacheStats()
+	return ux.Check{
+		Name:   "Font cache",
+		Status: ux.CheckOK,
+		Message: fmt.Sprintf("%d/%d fonts, %d hits, %d misses, %d evictions (%.0f%% hit rate)",
+			stats.Size, stats.Capacity, stats.Hits, stats.Misses, stats.Evictions, stats.HitRate()*100),
+		Detail: "Set font_cache_size in the config file to change the capacity",
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ddmoney420/moji/internal/ansiart"
+	"github.com/ddmoney420/moji/internal/export"
+	"github.com/ddmoney420/moji/internal/terminal"
+	"github.com/ddmoney420/moji/internal/ux"
+	"github.com/spf13/cobra"
+)
+
+func newViewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "view [file.ans]",
+		Short: "Display an ANSI art (.ans) file",
+		Long: `Display ANSI art from the art scene: code page 437 characters, ANSI.SYS
+colors and cursor movement, iCE colors, and the SAUCE record's width.
+
+Examples:
+  moji view logo.ans
+  moji view logo.ans --sauce
+  moji view wide.ans --width 160
+  moji view logo.ans --colors 256`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			width, _ := cmd.Flags().GetInt("width")
+			ice, _ := cmd.Flags().GetBool("ice")
+			utf8, _ := cmd.Flags().GetBool("utf8")
+			showSauce, _ := cmd.Flags().GetBool("sauce")
+			colors, _ := cmd.Flags().GetString("colors")
+
+			level, err := terminal.ParseColorLevel(colors)
+			if err != nil {
+				ux.Error("%v", err)
+				return
+			}
+			if noColorFlag {
+				level = terminal.NoColor
+			}
+			handleView(args[0], ansiart.Options{Width: width, ICE: ice, UTF8: utf8}, level, showSauce)
+		},
+	}
+	cmd.Flags().Int("width", 0, "Columns to wrap at (0 = the SAUCE width, or 80)")
+	cmd.Flags().Bool("ice", false, "Show blink as bright backgrounds (iCE colors) even without the SAUCE flag")
+	cmd.Flags().Bool("utf8", false, "Read the file as UTF-8 instead of code page 437")
+	cmd.Flags().Bool("sauce", false, "Print the SAUCE record after the art")
+	cmd.Flags().String("colors", "auto", "Color palette: auto (detect), truecolor, 256, 16, none")
+	return cmd
+}
+
+func handleView(path string, opts ansiart.Options, level terminal.ColorLevel, showSauce bool) {
+	art, err := ansiart.ReadFile(path, opts)
+	if err != nil {
+		ux.Error("%v", err)
+		return
+	}
+
+	if jsonFlag {
+		data := map[string]interface{}{"file": path, "width": art.Width, "lines": len(art.Rows), "output": art.Text()}
+		if art.Sauce != nil {
+			data["sauce"] = art.Sauce
+		}
+		json.NewEncoder(os.Stdout).Encode(data)
+		return
+	}
+
+	fmt.Print(art.Render(level))
+	if showSauce {
+		printSauce(art.Sauce)
+	}
+}
+
+// printSauce prints the fields of a SAUCE record
+func printSauce(s *ansiart.Sauce) {
+	if s == nil {
+		fmt.Println("\nNo SAUCE record")
+		return
+	}
+	fmt.Println()
+	fmt.Printf("Title:   %s\n", s.Title)
+	fmt.Printf("Author:  %s\n", s.Author)
+	fmt.Printf("Group:   %s\n", s.Group)
+	if !s.Date.IsZero() {
+		fmt.Printf("Date:    %s\n", s.Date.Format("2006-01-02"))
+	}
+	fmt.Printf("Size:    %dx%d\n", s.Width, s.Lines)
+	if s.Font != "" {
+		fmt.Printf("Font:    %s\n", s.Font)
+	}
+	if s.ICEColors {
+		fmt.Println("Colors:  iCE")
+	}
+	for _, c := range s.Comments {
+		fmt.Printf("Comment: %s\n", c)
+	}
+}
+
+// addSauceFlags adds the SAUCE metadata flags used when saving to .ans,
+// the title also naming .html and .json output
+func addSauceFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&sauceTitleFlag, "title", "", "Title for .ans (SAUCE), .html and .json output")
+	cmd.Flags().StringVar(&sauceAuthorFlag, "author", "", "SAUCE author for .ans output")
+	cmd.Flags().StringVar(&sauceGroupFlag, "group", "", "SAUCE group for .ans output")
+}
+
+// addOutputFlags adds -o and --format for saving in any registered export
+// format, and the flags the formats use
+func addOutputFlags(cmd *cobra.Command) {
+	var exts []string
+	for _, e := range export.Exporters() {
+		exts = append(exts, e.Extensions()[0])
+	}
+	cmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Save to file: "+strings.Join(exts, ", ")+" or text")
+	cmd.Flags().StringVar(&formatFlag, "format", "", "Format for --output instead of its extension: "+strings.Join(export.Names(), ", "))
+	cmd.RegisterFlagCompletionFunc("format", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return export.Names(), cobra.ShellCompDirectiveNoFileComp
+	})
+	addImageFlags(cmd)
+	addSauceFlags(cmd)
+}
+
+// saveOutput saves terminal text to --output, in the --format or the
+// format its extension names. title is used when --title isn't set, and
+// metadata goes into formats that keep it.
+func saveOutput(text, title string, metadata map[string]string) error {
+	if sauceTitleFlag != "" {
+		title = sauceTitleFlag
+	}
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	metadata["generator"] = "moji " + ux.Version
+	return export.Save(text, outputFlag, formatFlag, export.Options{
+		Background: bgColorFlag,
+		Foreground: fgColorFlag,
+		Title:      title,
+		Author:     sauceAuthorFlag,
+		Group:      sauceGroupFlag,
+		PNG:        pngOptions(),
+		Metadata:   metadata,
+	})
+}
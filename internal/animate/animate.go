@@ -2,6 +2,7 @@ package animate
 
 import (
 	"fmt"
+	"math/rand"
 	"strings"
 	"time"
 )
@@ -128,30 +129,12 @@ func Blink(text string, n int, delayMs int) {
 
 // Matrix rain effect (simplified single column)
 func MatrixRain(width, height int, durationMs int) {
-	chars := []rune("ｱｲｳｴｵｶｷｸｹｺｻｼｽｾｿﾀﾁﾂﾃﾄﾅﾆﾇﾈﾉﾊﾋﾌﾍﾎﾏﾐﾑﾒﾓﾔﾕﾖﾗﾘﾙﾚﾛﾜﾝ0123456789")
-	columns := make([]int, width)
+	rain := newMatrixRain(width, height, rand.New(rand.NewSource(time.Now().UnixNano())))
 
 	endTime := time.Now().Add(time.Duration(durationMs) * time.Millisecond)
 	for time.Now().Before(endTime) {
-		var line strings.Builder
-		for i := 0; i < width; i++ {
-			if columns[i] > 0 {
-				c := chars[columns[i]%len(chars)]
-				line.WriteString(fmt.Sprintf("\033[32m%c\033[0m", c))
-				columns[i]++
-				if columns[i] > height {
-					columns[i] = 0
-				}
-			} else {
-				line.WriteString(" ")
-				// Random chance to start a new drop
-				if time.Now().UnixNano()%100 < 5 {
-					columns[i] = 1
-				}
-			}
-		}
-		fmt.Printf("\r%s", line.String())
-		time.Sleep(50 * time.Millisecond)
+		fmt.Printf("\r%s", rain.step())
+		time.Sleep(matrixDelayMs * time.Millisecond)
 	}
 	fmt.Println()
 }
@@ -0,0 +1,93 @@
+package animate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// BundleVersion is the frame bundle format written by SaveBundle
+const BundleVersion = 1
+
+// Bundle is a saved sequence of multi-line frames with per-frame delays,
+// such as a converted animated GIF
+type Bundle struct {
+	Version int      `json:"version"`
+	Frames  []string `json:"frames"`
+	Delays  []int    `json:"delays_ms"` // Per-frame delay; missing entries use the last one
+	Loops   int      `json:"loops"`     // Suggested loop count (0 = forever)
+}
+
+// Delay returns how long frame i stays on screen
+func (b *Bundle) Delay(i int) time.Duration {
+	if len(b.Delays) == 0 {
+		return 100 * time.Millisecond
+	}
+	if i >= len(b.Delays) {
+		i = len(b.Delays) - 1
+	}
+	return time.Duration(b.Delays[i]) * time.Millisecond
+}
+
+// SaveBundle writes a frame bundle as JSON
+func SaveBundle(path string, b *Bundle) error {
+	b.Version = BundleVersion
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// LoadBundle reads a frame bundle written by SaveBundle
+func LoadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle %s: %w", path, err)
+	}
+	if len(b.Frames) == 0 {
+		return nil, fmt.Errorf("bundle %s has no frames", path)
+	}
+	if b.Version > BundleVersion {
+		return nil, fmt.Errorf("bundle %s is version %d, newer than this moji supports", path, b.Version)
+	}
+	return &b, nil
+}
+
+// PlayBundle plays the frames in place, redrawing from the top-left corner
+// each time, until they have looped n times (n <= 0 loops until ctx is
+// done). The cursor is hidden while playing.
+func PlayBundle(ctx context.Context, w io.Writer, b *Bundle, n int) {
+	if len(b.Frames) == 0 {
+		return
+	}
+	fmt.Fprint(w, "\033[?25l\033[H\033[2J") // Hide cursor, clear screen
+	defer fmt.Fprint(w, "\033[0m\033[?25h")
+
+	for loop := 0; n <= 0 || loop < n; loop++ {
+		for i, frame := range b.Frames {
+			// Clear to the end of each line so shorter frames leave nothing behind
+			frame = strings.ReplaceAll(strings.TrimRight(frame, "\n"), "\n", "\033[K\n")
+			fmt.Fprintf(w, "\033[H%s\033[K\033[J", frame)
+
+			select {
+			case <-ctx.Done():
+				fmt.Fprintln(w)
+				return
+			case <-time.After(b.Delay(i)):
+			}
+		}
+	}
+	fmt.Fprintln(w)
+}
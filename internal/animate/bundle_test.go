@@ -0,0 +1,62 @@
+package animate
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "anim.json")
+	want := &Bundle{Frames: []string{"ab\ncd\n", "ef\ngh\n"}, Delays: []int{40, 80}, Loops: 2}
+	if err := SaveBundle(path, want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadBundle(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != BundleVersion || got.Loops != 2 || len(got.Frames) != 2 || got.Frames[1] != "ef\ngh\n" {
+		t.Errorf("LoadBundle = %+v, want %+v", got, want)
+	}
+	if got.Delay(1) != 80*time.Millisecond || got.Delay(5) != 80*time.Millisecond {
+		t.Errorf("Delay = %v, %v; want 80ms", got.Delay(1), got.Delay(5))
+	}
+
+	if err := SaveBundle(path, &Bundle{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBundle(path); err == nil {
+		t.Error("LoadBundle should reject a bundle with no frames")
+	}
+}
+
+func TestPlayBundle(t *testing.T) {
+	var buf bytes.Buffer
+	b := &Bundle{Frames: []string{"one\n", "two\n"}, Delays: []int{1}}
+	PlayBundle(context.Background(), &buf, b, 2)
+
+	out := buf.String()
+	if n := strings.Count(out, "\033[H"); n != 5 {
+		t.Errorf("got %d cursor-home redraws, want 5 (clear + 4 frames)", n)
+	}
+	if !strings.Contains(out, "\033[Hone\033[K") || !strings.Contains(out, "\033[Htwo\033[K") {
+		t.Errorf("frames not drawn from the top-left corner: %q", out)
+	}
+	if !strings.Contains(out, "\033[?25h") {
+		t.Error("PlayBundle should show the cursor again")
+	}
+
+	// A cancelled context stops after the first frame, even when looping forever
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	buf.Reset()
+	PlayBundle(ctx, &buf, &Bundle{Frames: b.Frames, Delays: []int{1000}}, 0)
+	if strings.Contains(buf.String(), "two") {
+		t.Error("PlayBundle should stop when the context is done")
+	}
+}
@@ -2,7 +2,10 @@
 //
 // It offers a variety of preset animation sequences (spinners, dots, bounces) that can be played
 // with text, as well as specialized effects like typewriter text, scrolling text, fade in, blinking,
-// and matrix rain. Each animation is customizable with timing and display options.
+// and matrix rain. Each animation is customizable with timing and display options. Frame bundles
+// saved as JSON (such as converted animated GIFs) are played in place with PlayBundle. The
+// presets, typewriter, scrolling, blinking and matrix rain can also be built as bundles
+// (PresetBundle, TypewriterBundle, ...) to save or export instead of playing live.
 //
 // Example usage:
 //
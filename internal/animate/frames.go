@@ -0,0 +1,112 @@
+package animate
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// holdMs is how long the finished text of a typewriter bundle stays before
+// it loops
+const holdMs = 2000
+
+// matrixDelayMs is the frame delay of matrix rain
+const matrixDelayMs = 50
+
+// PresetBundle returns the frames of a preset, followed by text when it is
+// set, as a bundle that plays loops times (0 = forever)
+func PresetBundle(frames Frames, text string, loops, delayMs int) *Bundle {
+	b := &Bundle{Delays: []int{delayMs}, Loops: loops}
+	for _, frame := range frames {
+		if text != "" {
+			frame += " " + text
+		}
+		b.Frames = append(b.Frames, frame)
+	}
+	return b
+}
+
+// TypewriterBundle returns text typed out one character at a time, the
+// finished text staying for two seconds before the bundle loops
+func TypewriterBundle(text string, delayMs int) *Bundle {
+	b := &Bundle{}
+	runes := []rune(text)
+	for i := range runes {
+		b.Frames = append(b.Frames, string(runes[:i+1]))
+		b.Delays = append(b.Delays, delayMs)
+	}
+	if len(b.Delays) > 0 {
+		b.Delays[len(b.Delays)-1] = max(delayMs, holdMs)
+	}
+	return b
+}
+
+// ScrollBundle returns text scrolling right to left through a window of
+// width columns, as a bundle that plays loops times (0 = forever)
+func ScrollBundle(text string, width, loops, delayMs int) *Bundle {
+	if width <= 0 {
+		width = 40
+	}
+	padded := []rune(strings.Repeat(" ", width) + text + strings.Repeat(" ", width))
+
+	b := &Bundle{Delays: []int{delayMs}, Loops: loops}
+	for i := 0; i <= len(padded)-width; i++ {
+		b.Frames = append(b.Frames, string(padded[i:i+width]))
+	}
+	return b
+}
+
+// BlinkBundle returns text blinking on and off, as a bundle that plays
+// loops times (0 = forever)
+func BlinkBundle(text string, loops, delayMs int) *Bundle {
+	blank := strings.Repeat(" ", len([]rune(text)))
+	return &Bundle{Frames: []string{text, blank}, Delays: []int{delayMs}, Loops: loops}
+}
+
+// MatrixRainBundle returns durationMs of matrix rain, with drops started by
+// a random source seeded with seed
+func MatrixRainBundle(width, height, durationMs int, seed int64) *Bundle {
+	rain := newMatrixRain(width, height, rand.New(rand.NewSource(seed)))
+	b := &Bundle{Delays: []int{matrixDelayMs}}
+	for t := 0; t < durationMs; t += matrixDelayMs {
+		b.Frames = append(b.Frames, rain.step())
+	}
+	return b
+}
+
+// matrixChars are the characters the rain falls in
+var matrixChars = []rune("ｱｲｳｴｵｶｷｸｹｺｻｼｽｾｿﾀﾁﾂﾃﾄﾅﾆﾇﾈﾉﾊﾋﾌﾍﾎﾏﾐﾑﾒﾓﾔﾕﾖﾗﾘﾙﾚﾛﾜﾝ0123456789")
+
+// matrixRain is a row of columns, each either empty or counting through a
+// falling drop
+type matrixRain struct {
+	columns []int
+	height  int
+	rng     *rand.Rand
+}
+
+func newMatrixRain(width, height int, rng *rand.Rand) *matrixRain {
+	return &matrixRain{columns: make([]int, width), height: height, rng: rng}
+}
+
+// step advances the drops and returns the next line
+func (m *matrixRain) step() string {
+	var line strings.Builder
+	for i, pos := range m.columns {
+		if pos > 0 {
+			c := matrixChars[pos%len(matrixChars)]
+			line.WriteString(fmt.Sprintf("\033[32m%c\033[0m", c))
+			m.columns[i]++
+			if m.columns[i] > m.height {
+				m.columns[i] = 0
+			}
+		} else {
+			line.WriteString(" ")
+			// Random chance to start a new drop
+			if m.rng.Intn(100) < 5 {
+				m.columns[i] = 1
+			}
+		}
+	}
+	return line.String()
+}
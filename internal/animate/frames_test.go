@@ -0,0 +1,61 @@
+package animate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPresetBundle(t *testing.T) {
+	b := PresetBundle(Frames{"|", "/"}, "Loading", 0, 80)
+	if len(b.Frames) != 2 || b.Frames[1] != "/ Loading" {
+		t.Errorf("Frames = %q, want the preset followed by the text", b.Frames)
+	}
+	if b.Loops != 0 || b.Delay(1).Milliseconds() != 80 {
+		t.Errorf("Loops = %d, Delay = %v; want 0 and 80ms", b.Loops, b.Delay(1))
+	}
+}
+
+func TestTypewriterBundle(t *testing.T) {
+	b := TypewriterBundle("héy", 50)
+	want := []string{"h", "hé", "héy"}
+	if strings.Join(b.Frames, ",") != strings.Join(want, ",") {
+		t.Errorf("Frames = %q, want %q", b.Frames, want)
+	}
+	// The finished text is held before looping
+	if b.Delay(0).Milliseconds() != 50 || b.Delay(2).Milliseconds() != holdMs {
+		t.Errorf("Delays = %v, want 50ms then %dms at the end", b.Delays, holdMs)
+	}
+}
+
+func TestScrollBundle(t *testing.T) {
+	b := ScrollBundle("ab", 3, 2, 100)
+	// "   ab   " through a 3-column window
+	if len(b.Frames) != 6 || b.Frames[0] != "   " || b.Frames[3] != "ab " {
+		t.Errorf("Frames = %q", b.Frames)
+	}
+	if b.Loops != 2 {
+		t.Errorf("Loops = %d, want 2", b.Loops)
+	}
+}
+
+func TestBlinkBundle(t *testing.T) {
+	b := BlinkBundle("on", 4, 500)
+	if len(b.Frames) != 2 || b.Frames[0] != "on" || b.Frames[1] != "  " || b.Loops != 4 {
+		t.Errorf("BlinkBundle = %+v", b)
+	}
+}
+
+func TestMatrixRainBundle(t *testing.T) {
+	a := MatrixRainBundle(20, 8, 1000, 1)
+	b := MatrixRainBundle(20, 8, 1000, 1)
+	if len(a.Frames) != 1000/matrixDelayMs {
+		t.Fatalf("got %d frames, want %d", len(a.Frames), 1000/matrixDelayMs)
+	}
+	// The same seed gives the same rain
+	if strings.Join(a.Frames, "\n") != strings.Join(b.Frames, "\n") {
+		t.Error("MatrixRainBundle with the same seed differs")
+	}
+	if !strings.Contains(strings.Join(a.Frames, ""), "\033[32m") {
+		t.Error("no drops fell in a second of rain")
+	}
+}
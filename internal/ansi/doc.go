@@ -0,0 +1,18 @@
+// Package ansi writes colored terminal text compactly, and reads the escapes in it back.
+//
+// Writer tracks the colors already in effect and only emits an SGR escape when the foreground or
+// background actually changes, joining both into one sequence. Runs of same-colored cells, which
+// are common in converted images, cost a single escape instead of one per character. Colors are
+// reduced to the 256 or 16 color palette when the writer's terminal.ColorLevel asks for it.
+//
+// ReadEscape splits an escape sequence into its final byte and parameters, and Style.ApplySGR
+// follows the colors and attributes SGR sequences set. The ANSI art decoder and the exporters
+// share them, each resolving colors to its own palette.
+//
+// Example usage:
+//
+//	w := ansi.NewWriter(terminal.TrueColor)
+//	w.SetFg(255, 0, 0)
+//	w.WriteString("red\n")
+//	fmt.Print(w.String())
+package ansi
@@ -0,0 +1,176 @@
+package ansi
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ddmoney420/moji/internal/terminal"
+)
+
+// Sequence is an escape sequence read by ReadEscape
+type Sequence struct {
+	Final   byte  // Final byte of a control sequence, such as 'm' for SGR (0 for other escapes)
+	Params  []int // Control sequence parameters; missing ones are -1
+	Private bool  // The parameters start with ?, > or =, as private modes such as hiding the cursor do
+}
+
+// ReadEscape reads the escape sequence after an ESC and returns it with its
+// length. Operating system commands, such as hyperlinks, are skipped up to
+// the BEL or ST that ends them; an unfinished sequence runs to the end.
+func ReadEscape(seq []byte) (Sequence, int) {
+	if len(seq) == 0 {
+		return Sequence{}, 0
+	}
+	switch seq[0] {
+	case '[':
+	case ']':
+		for i := 1; i < len(seq); i++ {
+			if seq[i] == 0x07 {
+				return Sequence{}, i + 1
+			}
+			if seq[i] == 0x1B && i+1 < len(seq) && seq[i+1] == '\\' {
+				return Sequence{}, i + 2
+			}
+		}
+		return Sequence{}, len(seq)
+	default:
+		return Sequence{}, 1
+	}
+
+	// Control sequence: parameters, then a final byte in 0x40-0x7E
+	end := 1
+	for end < len(seq) && (seq[end] < 0x40 || seq[end] > 0x7E) {
+		end++
+	}
+	if end == len(seq) {
+		return Sequence{}, len(seq)
+	}
+	raw := string(seq[1:end])
+	if strings.HasPrefix(raw, "?") || strings.HasPrefix(raw, ">") || strings.HasPrefix(raw, "=") {
+		return Sequence{Final: seq[end], Private: true}, end + 1
+	}
+	return Sequence{Final: seq[end], Params: parseParams(raw)}, end + 1
+}
+
+// parseParams splits control sequence parameters; missing ones are -1
+func parseParams(raw string) []int {
+	if raw == "" {
+		return nil
+	}
+	parts := strings.Split(raw, ";")
+	params := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			n = -1
+		}
+		params[i] = n
+	}
+	return params
+}
+
+// ColorKind says how an SGR color was set
+type ColorKind int
+
+const (
+	ColorDefault ColorKind = iota // The terminal's default color
+	ColorSystem                   // One of the 16 system colors (30-37, 90-97 and their backgrounds)
+	ColorPalette                  // A 256-color palette index (38;5;n)
+	ColorRGB                      // A 24-bit color (38;2;r;g;b)
+)
+
+// Color is a foreground or background color set by SGR
+type Color struct {
+	Kind    ColorKind
+	Index   int   // Palette index of ColorSystem and ColorPalette
+	R, G, B uint8 // Value of ColorRGB
+}
+
+// RGB returns the color's value, using xterm's defaults for palette
+// indexes. ok is false for the default color.
+func (c Color) RGB() (r, g, b uint8, ok bool) {
+	switch c.Kind {
+	case ColorSystem, ColorPalette:
+		r, g, b = terminal.PaletteRGB(c.Index)
+		return r, g, b, true
+	case ColorRGB:
+		return c.R, c.G, c.B, true
+	}
+	return 0, 0, 0, false
+}
+
+// Style is the graphic rendition built up by SGR sequences
+type Style struct {
+	Fg, Bg                                  Color
+	Bold, Italic, Underline, Blink, Inverse bool
+}
+
+// ApplySGR updates the style with the parameters of an SGR sequence, as
+// read by ReadEscape. No parameters, 0 or a missing one reset it.
+func (s *Style) ApplySGR(params []int) {
+	if len(params) == 0 {
+		*s = Style{}
+		return
+	}
+	for i := 0; i < len(params); i++ {
+		switch n := params[i]; {
+		case n <= 0:
+			*s = Style{}
+		case n == 1:
+			s.Bold = true
+		case n == 2 || n == 22:
+			s.Bold = false
+		case n == 3:
+			s.Italic = true
+		case n == 23:
+			s.Italic = false
+		case n == 4 || n == 21:
+			s.Underline = true
+		case n == 24:
+			s.Underline = false
+		case n == 5 || n == 6:
+			s.Blink = true
+		case n == 25:
+			s.Blink = false
+		case n == 7:
+			s.Inverse = true
+		case n == 27:
+			s.Inverse = false
+		case n >= 30 && n <= 37:
+			s.Fg = Color{Kind: ColorSystem, Index: n - 30}
+		case n == 39:
+			s.Fg = Color{}
+		case n >= 40 && n <= 47:
+			s.Bg = Color{Kind: ColorSystem, Index: n - 40}
+		case n == 49:
+			s.Bg = Color{}
+		case n >= 90 && n <= 97:
+			s.Fg = Color{Kind: ColorSystem, Index: n - 90 + 8}
+		case n >= 100 && n <= 107:
+			s.Bg = Color{Kind: ColorSystem, Index: n - 100 + 8}
+		case n == 38 || n == 48:
+			c, used := extendedColor(params[i+1:])
+			i += used
+			if c.Kind == ColorDefault {
+				continue
+			}
+			if n == 38 {
+				s.Fg = c
+			} else {
+				s.Bg = c
+			}
+		}
+	}
+}
+
+// extendedColor reads the 5;n or 2;r;g;b parameters after 38 or 48 and
+// returns how many it used
+func extendedColor(params []int) (Color, int) {
+	if len(params) >= 2 && params[0] == 5 {
+		return Color{Kind: ColorPalette, Index: params[1]}, 2
+	}
+	if len(params) >= 4 && params[0] == 2 {
+		return Color{Kind: ColorRGB, R: uint8(params[1]), G: uint8(params[2]), B: uint8(params[3])}, 4
+	}
+	return Color{}, len(params)
+}
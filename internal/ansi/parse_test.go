@@ -0,0 +1,58 @@
+package ansi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadEscape(t *testing.T) {
+	tests := []struct {
+		seq  string
+		want Sequence
+		n    int
+	}{
+		{"[1;31mx", Sequence{Final: 'm', Params: []int{1, 31}}, 6},
+		{"[mx", Sequence{Final: 'm'}, 2},
+		{"[;5Hx", Sequence{Final: 'H', Params: []int{-1, 5}}, 4},
+		{"[?25lx", Sequence{Final: 'l', Private: true}, 5},
+		{"]8;;https://example.com\x07x", Sequence{}, 24},
+		{"]0;title\x1b\\x", Sequence{}, 10},
+		{"[1;3", Sequence{}, 4},
+		{"7x", Sequence{}, 1},
+	}
+	for _, tt := range tests {
+		got, n := ReadEscape([]byte(tt.seq))
+		if !reflect.DeepEqual(got, tt.want) || n != tt.n {
+			t.Errorf("ReadEscape(%q) = %+v, %d; want %+v, %d", tt.seq, got, n, tt.want, tt.n)
+		}
+	}
+}
+
+func TestApplySGR(t *testing.T) {
+	var s Style
+	s.ApplySGR([]int{1, 3, 4, 31, 48, 5, 200})
+	want := Style{
+		Fg:   Color{Kind: ColorSystem, Index: 1},
+		Bg:   Color{Kind: ColorPalette, Index: 200},
+		Bold: true, Italic: true, Underline: true,
+	}
+	if s != want {
+		t.Errorf("ApplySGR() = %+v, want %+v", s, want)
+	}
+
+	s.ApplySGR([]int{23, 24, 97, 38, 2, 1, 2, 3, 7})
+	if s.Italic || s.Underline || !s.Inverse || s.Fg != (Color{Kind: ColorRGB, R: 1, G: 2, B: 3}) {
+		t.Errorf("ApplySGR() = %+v", s)
+	}
+	if r, g, b, ok := s.Bg.RGB(); !ok || r != 255 || g != 0 || b != 215 {
+		t.Errorf("palette 200 RGB() = %d,%d,%d,%v; want 255,0,215", r, g, b, ok)
+	}
+
+	s.ApplySGR(nil)
+	if s != (Style{}) {
+		t.Errorf("ApplySGR() without parameters should reset, got %+v", s)
+	}
+	if _, _, _, ok := s.Fg.RGB(); ok {
+		t.Error("the default color should have no RGB value")
+	}
+}
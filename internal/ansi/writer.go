@@ -0,0 +1,104 @@
+package ansi
+
+import (
+	"strings"
+
+	"github.com/ddmoney420/moji/internal/terminal"
+)
+
+// Reset is the escape that restores the default colors
+const Reset = "\x1b[0m"
+
+// Writer builds colored text, emitting escapes only when colors change.
+// Set the colors for the text that follows with SetFg and SetBg; they are
+// written lazily, just before the next visible character.
+type Writer struct {
+	sb    strings.Builder
+	level terminal.ColorLevel
+
+	fg, bg       string // SGR parameters wanted for the next character ("" = default)
+	curFg, curBg string // SGR parameters in effect
+}
+
+// NewWriter returns a writer that emits colors at the given level
+// (NoColor writes 24-bit color, like TrueColor)
+func NewWriter(level terminal.ColorLevel) *Writer {
+	return &Writer{level: level}
+}
+
+// SetFg sets the foreground color of the text that follows
+func (w *Writer) SetFg(r, g, b uint8) {
+	w.fg = terminal.ColorParams(w.level, r, g, b, false)
+}
+
+// SetBg sets the background color of the text that follows
+func (w *Writer) SetBg(r, g, b uint8) {
+	w.bg = terminal.ColorParams(w.level, r, g, b, true)
+}
+
+// ResetColors returns the text that follows to the default colors
+func (w *Writer) ResetColors() {
+	w.fg, w.bg = "", ""
+}
+
+// WriteRune writes one character in the current colors. A space only
+// shows the background, so it never changes the foreground. Colors are
+// reset before each newline, so every line stands on its own and a
+// background color can't bleed into the rest of the row.
+func (w *Writer) WriteRune(r rune) {
+	if r == '\n' {
+		w.closeColors()
+		w.sb.WriteRune(r)
+		return
+	}
+
+	fg := w.fg
+	if r == ' ' {
+		fg = w.curFg
+	}
+
+	var params []string
+	if fg != w.curFg {
+		if fg == "" {
+			params = append(params, "39")
+		} else {
+			params = append(params, fg)
+		}
+	}
+	if w.bg != w.curBg {
+		if w.bg == "" {
+			params = append(params, "49")
+		} else {
+			params = append(params, w.bg)
+		}
+	}
+	if len(params) > 0 {
+		w.sb.WriteString("\x1b[" + strings.Join(params, ";") + "m")
+		w.curFg, w.curBg = fg, w.bg
+	}
+	w.sb.WriteRune(r)
+}
+
+// WriteString writes text in the current colors
+func (w *Writer) WriteString(s string) {
+	for _, r := range s {
+		w.WriteRune(r)
+	}
+}
+
+// String returns the text written so far, ending with a reset when colors
+// are still in effect
+func (w *Writer) String() string {
+	if w.curFg == "" && w.curBg == "" {
+		return w.sb.String()
+	}
+	return w.sb.String() + Reset
+}
+
+// closeColors resets the colors in effect, if any
+func (w *Writer) closeColors() {
+	if w.curFg != "" || w.curBg != "" {
+		w.sb.WriteString(Reset)
+		w.curFg, w.curBg = "", ""
+	}
+}
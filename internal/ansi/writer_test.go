@@ -0,0 +1,95 @@
+package ansi
+
+import (
+	"testing"
+
+	"github.com/ddmoney420/moji/internal/terminal"
+)
+
+func TestWriterRunLength(t *testing.T) {
+	w := NewWriter(terminal.TrueColor)
+	w.SetFg(255, 0, 0)
+	w.WriteString("ab")
+	w.SetFg(255, 0, 0)
+	w.WriteRune('c')
+	w.SetFg(0, 0, 255)
+	w.WriteRune('d')
+
+	want := "\x1b[38;2;255;0;0mabc\x1b[38;2;0;0;255md" + Reset
+	if got := w.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestWriterBackground(t *testing.T) {
+	w := NewWriter(terminal.TrueColor)
+	w.SetFg(1, 2, 3)
+	w.SetBg(4, 5, 6)
+	w.WriteRune('x')
+	w.SetBg(7, 8, 9)
+	w.WriteRune('y')
+	w.ResetColors()
+	w.WriteRune('z')
+
+	want := "\x1b[38;2;1;2;3;48;2;4;5;6mx\x1b[48;2;7;8;9my\x1b[39;49mz"
+	if got := w.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestWriterSpacesKeepForeground(t *testing.T) {
+	w := NewWriter(terminal.TrueColor)
+	w.SetFg(255, 0, 0)
+	w.WriteRune('a')
+	w.SetFg(0, 255, 0)
+	w.WriteRune(' ')
+	w.SetFg(255, 0, 0)
+	w.WriteRune('b')
+
+	want := "\x1b[38;2;255;0;0ma b" + Reset
+	if got := w.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestWriterNewlines(t *testing.T) {
+	w := NewWriter(terminal.TrueColor)
+	w.SetBg(0, 0, 255)
+	w.WriteString("a\nb\n")
+	w.ResetColors()
+	w.WriteString("plain\n")
+
+	want := "\x1b[48;2;0;0;255ma" + Reset + "\n\x1b[48;2;0;0;255mb" + Reset + "\nplain\n"
+	if got := w.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestWriterPalette(t *testing.T) {
+	w := NewWriter(terminal.Color256)
+	w.SetFg(255, 0, 0)
+	w.WriteRune('a')
+	w.SetFg(250, 5, 5) // the same palette entry, so no new escape
+	w.WriteRune('b')
+
+	want := "\x1b[38;5;196mab" + Reset
+	if got := w.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	w = NewWriter(terminal.Basic)
+	w.SetFg(255, 255, 255)
+	w.SetBg(255, 0, 0)
+	w.WriteRune('x')
+	if got, want := w.String(), "\x1b[97;101mx"+Reset; got != want {
+		t.Errorf("16-color String() = %q, want %q", got, want)
+	}
+}
+
+func TestWriterPlain(t *testing.T) {
+	w := NewWriter(terminal.TrueColor)
+	w.WriteString("no color\n")
+	if got := w.String(); got != "no color\n" {
+		t.Errorf("String() = %q, want the text unchanged", got)
+	}
+}
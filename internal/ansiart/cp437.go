@@ -0,0 +1,63 @@
+package ansiart
+
+// cp437 maps each code page 437 byte to the glyph it shows on a VGA screen.
+// The control range 0x01-0x1F shows the IBM PC graphics characters, as it
+// does in ANSI art.
+var cp437 = [256]rune{
+	0, '☺', '☻', '♥', '♦', '♣', '♠', '•', '◘', '○', '◙', '♂', '♀', '♪', '♫', '☼', // 0x00
+	'►', '◄', '↕', '‼', '¶', '§', '▬', '↨', '↑', '↓', '→', '←', '∟', '↔', '▲', '▼', // 0x10
+	' ', '!', '"', '#', '$', '%', '&', '\'', '(', ')', '*', '+', ',', '-', '.', '/', // 0x20
+	'0', '1', '2', '3', '4', '5', '6', '7', '8', '9', ':', ';', '<', '=', '>', '?', // 0x30
+	'@', 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N', 'O', // 0x40
+	'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z', '[', '\\', ']', '^', '_', // 0x50
+	'`', 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o', // 0x60
+	'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'z', '{', '|', '}', '~', '⌂', // 0x70
+	'Ç', 'ü', 'é', 'â', 'ä', 'à', 'å', 'ç', 'ê', 'ë', 'è', 'ï', 'î', 'ì', 'Ä', 'Å', // 0x80
+	'É', 'æ', 'Æ', 'ô', 'ö', 'ò', 'û', 'ù', 'ÿ', 'Ö', 'Ü', '¢', '£', '¥', '₧', 'ƒ', // 0x90
+	'á', 'í', 'ó', 'ú', 'ñ', 'Ñ', 'ª', 'º', '¿', '⌐', '¬', '½', '¼', '¡', '«', '»', // 0xA0
+	'░', '▒', '▓', '│', '┤', '╡', '╢', '╖', '╕', '╣', '║', '╗', '╝', '╜', '╛', '┐', // 0xB0
+	'└', '┴', '┬', '├', '─', '┼', '╞', '╟', '╚', '╔', '╩', '╦', '╠', '═', '╬', '╧', // 0xC0
+	'╨', '╤', '╥', '╙', '╘', '╒', '╓', '╫', '╪', '┘', '┌', '█', '▄', '▌', '▐', '▀', // 0xD0
+	'α', 'ß', 'Γ', 'π', 'Σ', 'σ', 'µ', 'τ', 'Φ', 'Θ', 'Ω', 'δ', '∞', 'φ', 'ε', '∩', // 0xE0
+	'≡', '±', '≥', '≤', '⌠', '⌡', '÷', '≈', '°', '∙', '·', '√', 'ⁿ', '²', '■', 0x00A0, // 0xF0
+}
+
+// fromCP437 maps glyphs back to their code page 437 byte
+var fromCP437 = func() map[rune]byte {
+	m := make(map[rune]byte, len(cp437))
+	for i := len(cp437) - 1; i >= 0; i-- {
+		m[cp437[i]] = byte(i)
+	}
+	return m
+}()
+
+// cp437Substitutes stands in for common glyphs the code page lacks, such as
+// the rounded and heavy box drawing used by borders
+var cp437Substitutes = map[rune]rune{
+	'╭': '┌', '╮': '┐', '╰': '└', '╯': '┘',
+	'━': '─', '┃': '│', '┏': '┌', '┓': '┐', '┗': '└', '┛': '┘',
+	'’': '\'', '‘': '\'', '“': '"', '”': '"', '–': '-', '—': '-',
+}
+
+// DecodeCP437 returns the glyph a code page 437 byte shows as
+func DecodeCP437(b byte) rune {
+	if b == 0 {
+		return ' '
+	}
+	return cp437[b]
+}
+
+// EncodeCP437 returns the code page 437 byte for a glyph, or for a close
+// substitute. Glyphs with neither come back as '?' with ok false.
+func EncodeCP437(r rune) (b byte, ok bool) {
+	if r == 0 {
+		return 0, true
+	}
+	if b, ok := fromCP437[r]; ok {
+		return b, true
+	}
+	if sub, ok := cp437Substitutes[r]; ok {
+		return fromCP437[sub], true
+	}
+	return '?', false
+}
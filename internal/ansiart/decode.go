@@ -0,0 +1,338 @@
+package ansiart
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ddmoney420/moji/internal/ansi"
+	"github.com/ddmoney420/moji/internal/terminal"
+)
+
+// DefaultWidth is the screen width of ANSI art without a SAUCE width
+const DefaultWidth = 80
+
+// Limits on how far cursor movement can grow the screen
+const (
+	maxRows = 10000
+	maxCols = 1000
+)
+
+// vgaPalette is the 16-color palette of the VGA text mode ANSI art is drawn for
+var vgaPalette = [16][3]uint8{
+	{0x00, 0x00, 0x00}, {0xAA, 0x00, 0x00}, {0x00, 0xAA, 0x00}, {0xAA, 0x55, 0x00},
+	{0x00, 0x00, 0xAA}, {0xAA, 0x00, 0xAA}, {0x00, 0xAA, 0xAA}, {0xAA, 0xAA, 0xAA},
+	{0x55, 0x55, 0x55}, {0xFF, 0x55, 0x55}, {0x55, 0xFF, 0x55}, {0xFF, 0xFF, 0x55},
+	{0x55, 0x55, 0xFF}, {0xFF, 0x55, 0xFF}, {0x55, 0xFF, 0xFF}, {0xFF, 0xFF, 0xFF},
+}
+
+// Color is a cell color. Set is false for the terminal's default color.
+type Color struct {
+	R, G, B uint8
+	Set     bool
+}
+
+// Cell is one character position of the screen
+type Cell struct {
+	Ch     rune
+	Fg, Bg Color
+}
+
+// Art is ANSI art laid out on a grid of cells
+type Art struct {
+	Width int // columns lines wrap at (0 = no wrapping)
+	Rows  [][]Cell
+	Sauce *Sauce
+}
+
+// Options controls how ANSI art is decoded
+type Options struct {
+	Width int  // columns to wrap at (0 = the SAUCE width, or 80)
+	ICE   bool // treat blink as bright backgrounds even without the SAUCE flag
+	UTF8  bool // the content is UTF-8 text rather than CP437
+}
+
+// ReadFile decodes an ANSI art file
+func ReadFile(path string, opts Options) (*Art, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return Decode(data, opts), nil
+}
+
+// Decode lays out ANSI art, interpreting its SGR colors and cursor movement
+// the way ANSI.SYS did
+func Decode(data []byte, opts Options) *Art {
+	content, sauce := SplitSauce(data)
+
+	width := opts.Width
+	ice := opts.ICE
+	if sauce != nil {
+		if width <= 0 && sauce.DataType == DataTypeCharacter && sauce.Width > 0 {
+			width = sauce.Width
+		}
+		ice = ice || sauce.ICEColors
+	}
+	if width <= 0 {
+		width = DefaultWidth
+	}
+
+	art := layout(content, min(width, maxCols), ice, opts.UTF8)
+	art.Sauce = sauce
+	return art
+}
+
+// pen is the current SGR state
+type pen struct {
+	ansi.Style
+}
+
+// colors resolves the pen to cell colors: bold brightens the foreground,
+// and blink brightens the background with iCE colors
+func (p pen) colors(ice bool) (Color, Color) {
+	fg := artColor(p.Fg)
+	switch {
+	case p.Fg.Kind == ansi.ColorSystem && p.Bold && p.Fg.Index < 8:
+		fg = vgaColor(p.Fg.Index + 8)
+	case p.Fg.Kind == ansi.ColorDefault && p.Bold:
+		fg = vgaColor(15)
+	}
+
+	bg := artColor(p.Bg)
+	switch {
+	case p.Bg.Kind == ansi.ColorSystem && p.Blink && ice && p.Bg.Index < 8:
+		bg = vgaColor(p.Bg.Index + 8)
+	case p.Bg.Kind == ansi.ColorDefault && p.Blink && ice:
+		bg = vgaColor(8)
+	}
+
+	if p.Inverse {
+		if !fg.Set {
+			fg = vgaColor(7)
+		}
+		if !bg.Set {
+			bg = vgaColor(0)
+		}
+		fg, bg = bg, fg
+	}
+	return fg, bg
+}
+
+// artColor returns the cell color of an SGR color, taking the 16 system
+// colors from the VGA palette
+func artColor(c ansi.Color) Color {
+	if (c.Kind == ansi.ColorSystem || c.Kind == ansi.ColorPalette) && c.Index >= 0 && c.Index < 16 {
+		return vgaColor(c.Index)
+	}
+	r, g, b, ok := c.RGB()
+	return Color{r, g, b, ok}
+}
+
+func vgaColor(idx int) Color {
+	c := vgaPalette[idx]
+	return Color{c[0], c[1], c[2], true}
+}
+
+// screen is the interpreter state while laying out art
+type screen struct {
+	art            *Art
+	width          int // 0 = no wrapping
+	ice            bool
+	x, y           int
+	savedX, savedY int
+	wrapPending    bool
+	pen            pen
+}
+
+// layout interprets content onto a grid of cells
+func layout(content []byte, width int, ice, isUTF8 bool) *Art {
+	s := &screen{art: &Art{Width: width}, width: width, ice: ice}
+
+	for i := 0; i < len(content); {
+		r, size := rune(content[i]), 1
+		if isUTF8 && r >= utf8.RuneSelf {
+			r, size = utf8.DecodeRune(content[i:])
+		}
+
+		switch {
+		case r == 0x1B:
+			i += s.escape(content[i+1:])
+		case r == '\r':
+			s.x, s.wrapPending = 0, false
+		case r == '\n':
+			s.moveTo(0, s.y+1)
+		case r == '\t':
+			s.moveTo((s.x/8+1)*8, s.y)
+		case r < 0x20 && isUTF8:
+			// Other control characters have no glyph in UTF-8 text
+		case isUTF8:
+			s.put(r)
+		default:
+			s.put(DecodeCP437(byte(r)))
+		}
+		i += size
+	}
+
+	// Drop trailing empty rows
+	rows := s.art.Rows
+	for len(rows) > 0 && len(rows[len(rows)-1]) == 0 {
+		rows = rows[:len(rows)-1]
+	}
+	s.art.Rows = rows
+	return s.art
+}
+
+// put writes a glyph at the cursor and advances it, wrapping at the width
+// after the last column is written, as a terminal does
+func (s *screen) put(r rune) {
+	if s.wrapPending {
+		s.moveTo(0, s.y+1)
+	}
+	if s.width == 0 && s.x >= maxCols {
+		return
+	}
+	fg, bg := s.pen.colors(s.ice)
+	*s.cell(s.x, s.y) = Cell{Ch: r, Fg: fg, Bg: bg}
+	s.x++
+	if s.width > 0 && s.x >= s.width {
+		s.x, s.wrapPending = s.width-1, true
+	}
+}
+
+// cell returns the cell at x, y, growing the grid to reach it
+func (s *screen) cell(x, y int) *Cell {
+	for len(s.art.Rows) <= y {
+		s.art.Rows = append(s.art.Rows, nil)
+	}
+	row := s.art.Rows[y]
+	for len(row) <= x {
+		row = append(row, Cell{Ch: ' '})
+	}
+	s.art.Rows[y] = row
+	return &row[x]
+}
+
+// moveTo moves the cursor, keeping it on the screen
+func (s *screen) moveTo(x, y int) {
+	x = max(x, 0)
+	if s.width > 0 {
+		x = min(x, s.width-1)
+	} else {
+		x = min(x, maxCols)
+	}
+	s.x, s.y = x, min(max(y, 0), maxRows-1)
+	s.wrapPending = false
+}
+
+// escape interprets the escape sequence after an ESC and returns its length
+func (s *screen) escape(seq []byte) int {
+	esc, n := ansi.ReadEscape(seq)
+	if esc.Final != 0 && !esc.Private {
+		s.control(esc.Final, esc.Params)
+	}
+	return n
+}
+
+// param returns parameter i, or def when it is missing or zero
+func param(params []int, i, def int) int {
+	if i < len(params) && params[i] > 0 {
+		return params[i]
+	}
+	return def
+}
+
+// control runs a control sequence
+func (s *screen) control(final byte, params []int) {
+	switch final {
+	case 'm':
+		s.pen.ApplySGR(params)
+	case 'A':
+		s.moveTo(s.x, s.y-param(params, 0, 1))
+	case 'B':
+		s.moveTo(s.x, s.y+param(params, 0, 1))
+	case 'C':
+		s.moveTo(s.x+param(params, 0, 1), s.y)
+	case 'D':
+		s.moveTo(s.x-param(params, 0, 1), s.y)
+	case 'H', 'f':
+		s.moveTo(param(params, 1, 1)-1, param(params, 0, 1)-1)
+	case 's':
+		s.savedX, s.savedY = s.x, s.y
+	case 'u':
+		s.moveTo(s.savedX, s.savedY)
+	case 'J':
+		if param(params, 0, 0) == 2 {
+			s.art.Rows = nil
+			s.moveTo(0, 0)
+		}
+	case 'K':
+		if s.y < len(s.art.Rows) && s.x < len(s.art.Rows[s.y]) {
+			s.art.Rows[s.y] = s.art.Rows[s.y][:s.x]
+		}
+	case 't':
+		// PabloDraw 24-bit color: 0 for background, 1 for foreground
+		if len(params) == 4 {
+			c := ansi.Color{Kind: ansi.ColorRGB, R: uint8(params[1]), G: uint8(params[2]), B: uint8(params[3])}
+			if params[0] == 1 {
+				s.pen.Fg = c
+			} else {
+				s.pen.Bg = c
+			}
+		}
+	}
+}
+
+// Render returns the art as terminal text at the given color level, or
+// as plain text for NoColor
+func (a *Art) Render(level terminal.ColorLevel) string {
+	if level == terminal.NoColor {
+		return a.Text()
+	}
+
+	w := ansi.NewWriter(level)
+	for _, row := range a.Rows {
+		for _, c := range trimRow(row) {
+			w.ResetColors()
+			switch {
+			case c.Fg.Set:
+				w.SetFg(c.Fg.R, c.Fg.G, c.Fg.B)
+			case c.Bg.Set:
+				// A background alone needs the DOS default foreground
+				gray := vgaPalette[7]
+				w.SetFg(gray[0], gray[1], gray[2])
+			}
+			if c.Bg.Set {
+				w.SetBg(c.Bg.R, c.Bg.G, c.Bg.B)
+			}
+			w.WriteRune(c.Ch)
+		}
+		w.WriteRune('\n')
+	}
+	return w.String()
+}
+
+// Text returns the art's characters without colors
+func (a *Art) Text() string {
+	var sb strings.Builder
+	for _, row := range a.Rows {
+		for _, c := range trimRow(row) {
+			sb.WriteRune(c.Ch)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// trimRow drops the blank cells at the end of a row
+func trimRow(row []Cell) []Cell {
+	for len(row) > 0 {
+		c := row[len(row)-1]
+		if c.Ch != ' ' || c.Bg.Set {
+			break
+		}
+		row = row[:len(row)-1]
+	}
+	return row
+}
@@ -0,0 +1,82 @@
+package ansiart
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ddmoney420/moji/internal/terminal"
+)
+
+func TestDecodeColors(t *testing.T) {
+	art := Decode([]byte("\x1b[0;1;31mA\x1b[0;44mB\x1b[5;42mC\x1b[0mD"), Options{})
+
+	row := art.Rows[0]
+	if len(row) != 4 {
+		t.Fatalf("row has %d cells, want 4", len(row))
+	}
+	if want := vgaColor(9); row[0].Fg != want {
+		t.Errorf("bold red = %+v, want bright red %+v", row[0].Fg, want)
+	}
+	if row[1].Fg.Set || row[1].Bg != vgaColor(4) {
+		t.Errorf("blue background cell = %+v", row[1])
+	}
+	// Without iCE colors blink leaves the background dark
+	if row[2].Bg != vgaColor(2) {
+		t.Errorf("blinking green background = %+v, want dark green", row[2].Bg)
+	}
+	if row[3].Fg.Set || row[3].Bg.Set {
+		t.Errorf("reset cell has colors: %+v", row[3])
+	}
+
+	ice := Decode([]byte("\x1b[5;42mC\x1b[0;5mD"), Options{ICE: true})
+	if ice.Rows[0][0].Bg != vgaColor(10) {
+		t.Errorf("iCE blink background = %+v, want bright green", ice.Rows[0][0].Bg)
+	}
+	if ice.Rows[0][1].Bg != vgaColor(8) {
+		t.Errorf("iCE blink on the default background = %+v, want dark gray", ice.Rows[0][1].Bg)
+	}
+}
+
+func TestDecodeCursorAndWrap(t *testing.T) {
+	// The SAUCE width sets where lines wrap; a CRLF right after a full
+	// line doesn't add a blank one
+	sauce := Sauce{DataType: DataTypeCharacter, Width: 4}
+	data := append([]byte("abcdef\r\nxyzw\r\n1\x1b[2C2\x1b[1;3H\xdb\x1a"), sauce.Bytes()...)
+	art := Decode(data, Options{})
+
+	if got := art.Text(); got != "ab█d\nef\nxyzw\n1  2\n" {
+		t.Errorf("Text() = %q", got)
+	}
+	if art.Sauce == nil || art.Sauce.Width != 4 {
+		t.Errorf("Sauce = %+v, want width 4", art.Sauce)
+	}
+
+	if got := Decode([]byte("abcdef"), Options{Width: 3}).Text(); got != "abc\ndef\n" {
+		t.Errorf("--width 3 = %q", got)
+	}
+}
+
+func TestDecodeExtendedColors(t *testing.T) {
+	art := Decode([]byte("\x1b[38;2;1;2;3;48;5;196mX\x1b[1;10;20;30tY"), Options{})
+	row := art.Rows[0]
+	if row[0].Fg != (Color{1, 2, 3, true}) || row[0].Bg != (Color{255, 0, 0, true}) {
+		t.Errorf("extended colors = %+v", row[0])
+	}
+	if row[1].Fg != (Color{10, 20, 30, true}) {
+		t.Errorf("PabloDraw 24-bit color = %+v", row[1].Fg)
+	}
+}
+
+func TestRender(t *testing.T) {
+	art := Decode([]byte("\x1b[1;31mHi\r\n\x1b[0mok"), Options{})
+	out := art.Render(terminal.TrueColor)
+	if !strings.Contains(out, "\x1b[38;2;255;85;85mHi") {
+		t.Errorf("Render = %q, want bright red Hi", out)
+	}
+	if !strings.HasSuffix(out, "\nok\n") {
+		t.Errorf("Render = %q, want the reset line uncolored", out)
+	}
+	if got := art.Render(terminal.NoColor); got != "Hi\nok\n" {
+		t.Errorf("Render(NoColor) = %q", got)
+	}
+}
@@ -0,0 +1,15 @@
+// Package ansiart reads and writes .ans files, the ANSI art format of the art scene.
+//
+// Files hold code page 437 characters with ANSI.SYS escape sequences for color and cursor
+// movement, followed by an end-of-file marker and a SAUCE record carrying the title, author,
+// group, width and font. Decode lays a file out on a grid of cells, wrapping at the SAUCE width
+// and honoring iCE colors (blink as bright backgrounds); Render then draws it on a modern
+// terminal. Encode goes the other way, turning moji's colored output into a 16-color .ans file.
+//
+// Example usage:
+//
+//	art, err := ansiart.ReadFile("logo.ans", ansiart.Options{})
+//	fmt.Print(art.Render(terminal.TrueColor))
+//
+//	err = ansiart.WriteFile("banner.ans", text, ansiart.Sauce{Title: "Banner", Author: "me"})
+package ansiart
@@ -0,0 +1,141 @@
+package ansiart
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Encode converts terminal text, such as moji's colored output, into an
+// .ans file: CP437 characters with 16-color VGA attributes, CRLF line
+// endings and a SAUCE record. Bright foregrounds are written as bold and
+// bright backgrounds as blink, setting the iCE colors flag. The SAUCE width
+// and line count are filled in when zero, and the date when unset.
+func Encode(text string, sauce Sauce) []byte {
+	art := layout([]byte(text), 0, false, true)
+
+	var body bytes.Buffer
+	body.WriteString("\x1b[0m")
+	cur := attr{fg: 7, bg: 0}
+	ice := false
+	width := 0
+	for _, row := range art.Rows {
+		row = trimRow(row)
+		width = max(width, len(row))
+		for _, c := range row {
+			next := cellAttr(c)
+			if c.Ch == ' ' {
+				// A space only shows its background
+				next.fg = cur.fg
+			}
+			body.WriteString(cur.change(next))
+			cur = next
+			ice = ice || next.bg >= 8
+
+			b, _ := EncodeCP437(c.Ch)
+			body.WriteByte(b)
+		}
+		body.WriteString("\r\n")
+	}
+	body.WriteString("\x1b[0m")
+
+	sauce.DataType = DataTypeCharacter
+	sauce.FileType = FileTypeANSI
+	sauce.FileSize = body.Len()
+	if sauce.Width == 0 {
+		sauce.Width = max(width, 1)
+	}
+	if sauce.Lines == 0 {
+		sauce.Lines = len(art.Rows)
+	}
+	if sauce.Date.IsZero() {
+		sauce.Date = time.Now()
+	}
+	if sauce.Font == "" {
+		sauce.Font = DefaultFont
+	}
+	sauce.ICEColors = sauce.ICEColors || ice
+
+	body.WriteByte(eofMarker)
+	body.Write(sauce.Bytes())
+	return body.Bytes()
+}
+
+// WriteFile saves terminal text as an .ans file
+func WriteFile(path, text string, sauce Sauce) error {
+	if err := os.WriteFile(path, Encode(text, sauce), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// attr is a VGA text attribute: palette indexes, where 8-15 are the
+// bright colors
+type attr struct {
+	fg, bg int
+}
+
+// cellAttr maps a cell's colors to the nearest VGA colors, defaulting to
+// light gray on black
+func cellAttr(c Cell) attr {
+	a := attr{fg: 7, bg: 0}
+	if c.Fg.Set {
+		a.fg = nearestVGA(c.Fg)
+	}
+	if c.Bg.Set {
+		a.bg = nearestVGA(c.Bg)
+	}
+	return a
+}
+
+// change returns the SGR sequence that switches from a to next, or ""
+// when nothing changes. Bold and blink can only be turned off by a reset.
+func (a attr) change(next attr) string {
+	if a == next {
+		return ""
+	}
+	bold, blink := next.fg >= 8, next.bg >= 8
+	var params []string
+	if (a.fg >= 8 && !bold) || (a.bg >= 8 && !blink) {
+		params = append(params, "0")
+		a = attr{fg: 7, bg: 0}
+		if bold {
+			params = append(params, "1")
+		}
+		if blink {
+			params = append(params, "5")
+		}
+	} else {
+		if bold && a.fg < 8 {
+			params = append(params, "1")
+		}
+		if blink && a.bg < 8 {
+			params = append(params, "5")
+		}
+	}
+	if next.fg%8 != a.fg%8 {
+		params = append(params, strconv.Itoa(30+next.fg%8))
+	}
+	if next.bg%8 != a.bg%8 {
+		params = append(params, strconv.Itoa(40+next.bg%8))
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
+
+// nearestVGA returns the VGA palette index closest to a color
+func nearestVGA(c Color) int {
+	best, bestDist := 0, -1
+	for i, p := range vgaPalette {
+		dr := int(c.R) - int(p[0])
+		dg := int(c.G) - int(p[1])
+		db := int(c.B) - int(p[2])
+		dist := 3*dr*dr + 4*dg*dg + 2*db*db
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
@@ -0,0 +1,51 @@
+package ansiart
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	text := "\x1b[38;2;255;85;85mred\x1b[0m ─\n\x1b[48;2;255;255;85m \x1b[0m😀\n"
+	date := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	data := Encode(text, Sauce{Title: "Test", Author: "moji", Date: date})
+
+	content, sauce := SplitSauce(data)
+	want := "\x1b[0m\x1b[1;31mred \x1b[0m\xc4\r\n\x1b[5;43m \x1b[0m?\r\n\x1b[0m"
+	if string(content) != want {
+		t.Errorf("content = %q\n want %q", content, want)
+	}
+	if i := bytes.IndexByte(data, eofMarker); i != len(content) {
+		t.Errorf("end-of-file marker at %d, want %d", i, len(content))
+	}
+
+	if sauce == nil {
+		t.Fatal("Encode wrote no SAUCE record")
+	}
+	if sauce.Title != "Test" || sauce.Author != "moji" || !sauce.Date.Equal(date) {
+		t.Errorf("SAUCE = %+v", sauce)
+	}
+	if sauce.Width != 5 || sauce.Lines != 2 || sauce.FileSize != len(content) || sauce.Font != DefaultFont {
+		t.Errorf("SAUCE width %d, lines %d, size %d, font %q; want 5, 2, %d, %q",
+			sauce.Width, sauce.Lines, sauce.FileSize, sauce.Font, len(content), DefaultFont)
+	}
+	if !sauce.ICEColors {
+		t.Error("a bright background should set the iCE colors flag")
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	text := "\x1b[38;2;0;170;0m░▒▓█\x1b[0m\n\x1b[38;2;85;255;255;48;2;0;0;170m▀▄\x1b[0m\n"
+	art := Decode(Encode(text, Sauce{}), Options{})
+
+	if got := art.Text(); got != "░▒▓█\n▀▄\n" {
+		t.Errorf("round trip text = %q", got)
+	}
+	if c := art.Rows[0][0]; c.Fg != vgaColor(2) {
+		t.Errorf("green = %+v, want VGA green", c.Fg)
+	}
+	if c := art.Rows[1][1]; c.Fg != vgaColor(14) || c.Bg != vgaColor(4) {
+		t.Errorf("cyan on blue = %+v", c)
+	}
+}
@@ -0,0 +1,154 @@
+package ansiart
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"time"
+)
+
+const (
+	sauceSize   = 128
+	commentSize = 64
+	eofMarker   = 0x1A
+)
+
+// SAUCE data and file types for ANSI art
+const (
+	DataTypeCharacter = 1
+	FileTypeANSI      = 1
+)
+
+// DefaultFont is the SAUCE font name of the standard DOS text mode font
+const DefaultFont = "IBM VGA"
+
+// Sauce is the SAUCE record (Standard Architecture for Universal Comment
+// Extensions) that ANSI art files carry after their content
+type Sauce struct {
+	Title     string
+	Author    string
+	Group     string
+	Date      time.Time
+	FileSize  int
+	DataType  byte
+	FileType  byte
+	Width     int  // TInfo1: columns
+	Lines     int  // TInfo2: rows
+	ICEColors bool // TFlags bit 0: blink selects bright backgrounds instead
+	Font      string
+	Comments  []string
+}
+
+// SplitSauce separates a file into its content and SAUCE record. The
+// content stops at the DOS end-of-file marker; the record is nil when the
+// file has none.
+func SplitSauce(data []byte) ([]byte, *Sauce) {
+	content := data
+	var sauce *Sauce
+	if n := len(data); n >= sauceSize && string(data[n-sauceSize:n-sauceSize+5]) == "SAUCE" {
+		sauce = parseSauce(data[n-sauceSize:])
+		content = data[:n-sauceSize]
+
+		// Comment lines sit in a COMNT block just before the record
+		if c := len(sauce.Comments); c > 0 {
+			start := len(content) - 5 - c*commentSize
+			if start >= 0 && string(content[start:start+5]) == "COMNT" {
+				for i := range sauce.Comments {
+					line := content[start+5+i*commentSize:]
+					sauce.Comments[i] = decodeField(line[:commentSize])
+				}
+				content = content[:start]
+			} else {
+				sauce.Comments = nil
+			}
+		}
+	}
+	if i := bytes.IndexByte(content, eofMarker); i >= 0 {
+		content = content[:i]
+	}
+	return content, sauce
+}
+
+// parseSauce decodes a 128-byte record. Comments come back as empty lines,
+// which SplitSauce fills in from the comment block.
+func parseSauce(rec []byte) *Sauce {
+	s := &Sauce{
+		Title:     decodeField(rec[7:42]),
+		Author:    decodeField(rec[42:62]),
+		Group:     decodeField(rec[62:82]),
+		FileSize:  int(binary.LittleEndian.Uint32(rec[90:94])),
+		DataType:  rec[94],
+		FileType:  rec[95],
+		Width:     int(binary.LittleEndian.Uint16(rec[96:98])),
+		Lines:     int(binary.LittleEndian.Uint16(rec[98:100])),
+		ICEColors: rec[105]&1 != 0,
+		Font:      decodeField(rec[106:128]),
+	}
+	if date, err := time.Parse("20060102", string(rec[82:90])); err == nil {
+		s.Date = date
+	}
+	if n := int(rec[104]); n > 0 {
+		s.Comments = make([]string, n)
+	}
+	return s
+}
+
+// Bytes encodes the record, preceded by its comment block if it has
+// comments. The end-of-file marker is not included.
+func (s *Sauce) Bytes() []byte {
+	var buf bytes.Buffer
+	comments := s.Comments[:min(len(s.Comments), 255)]
+	if len(comments) > 0 {
+		buf.WriteString("COMNT")
+		for _, c := range comments {
+			buf.Write(encodeField(c, commentSize, ' '))
+		}
+	}
+
+	buf.WriteString("SAUCE00")
+	buf.Write(encodeField(s.Title, 35, ' '))
+	buf.Write(encodeField(s.Author, 20, ' '))
+	buf.Write(encodeField(s.Group, 20, ' '))
+	if s.Date.IsZero() {
+		buf.WriteString("        ")
+	} else {
+		buf.WriteString(s.Date.Format("20060102"))
+	}
+	binary.Write(&buf, binary.LittleEndian, uint32(s.FileSize))
+	buf.WriteByte(s.DataType)
+	buf.WriteByte(s.FileType)
+	binary.Write(&buf, binary.LittleEndian, uint16(s.Width))
+	binary.Write(&buf, binary.LittleEndian, uint16(s.Lines))
+	buf.Write([]byte{0, 0, 0, 0}) // TInfo3, TInfo4
+	buf.WriteByte(byte(len(comments)))
+	var flags byte
+	if s.ICEColors {
+		flags |= 1
+	}
+	buf.WriteByte(flags)
+	buf.Write(encodeField(s.Font, 22, 0))
+	return buf.Bytes()
+}
+
+// encodeField encodes a string as CP437, cut or padded to size
+func encodeField(s string, size int, pad byte) []byte {
+	field := bytes.Repeat([]byte{pad}, size)
+	i := 0
+	for _, r := range s {
+		if i == size {
+			break
+		}
+		field[i], _ = EncodeCP437(r)
+		i++
+	}
+	return field
+}
+
+// decodeField decodes a CP437 field, dropping the padding
+func decodeField(b []byte) string {
+	var sb strings.Builder
+	for _, c := range bytes.TrimRight(b, " \x00") {
+		sb.WriteRune(DecodeCP437(c))
+	}
+	return sb.String()
+}
@@ -0,0 +1,78 @@
+package ansiart
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSauceRoundTrip(t *testing.T) {
+	want := Sauce{
+		Title:     "Café",
+		Author:    "moji",
+		Group:     "ACiD",
+		Date:      time.Date(1996, 8, 1, 0, 0, 0, 0, time.UTC),
+		FileSize:  1234,
+		DataType:  DataTypeCharacter,
+		FileType:  FileTypeANSI,
+		Width:     160,
+		Lines:     50,
+		ICEColors: true,
+		Font:      DefaultFont,
+		Comments:  []string{"first", "second"},
+	}
+	rec := want.Bytes()
+	if len(rec) != 5+2*commentSize+sauceSize {
+		t.Fatalf("record is %d bytes, want %d", len(rec), 5+2*commentSize+sauceSize)
+	}
+
+	file := append([]byte("art\x1a"), rec...)
+	content, got := SplitSauce(file)
+	if string(content) != "art" {
+		t.Errorf("content = %q, want %q", content, "art")
+	}
+	if got == nil {
+		t.Fatal("SplitSauce found no record")
+	}
+	if got.Title != want.Title || got.Author != want.Author || got.Group != want.Group ||
+		!got.Date.Equal(want.Date) || got.FileSize != want.FileSize || got.Width != 160 ||
+		got.Lines != 50 || !got.ICEColors || got.Font != DefaultFont {
+		t.Errorf("SplitSauce = %+v, want %+v", got, want)
+	}
+	if len(got.Comments) != 2 || got.Comments[1] != "second" {
+		t.Errorf("comments = %q, want %q", got.Comments, want.Comments)
+	}
+}
+
+func TestSplitSauceWithout(t *testing.T) {
+	content, sauce := SplitSauce([]byte("plain text"))
+	if sauce != nil || string(content) != "plain text" {
+		t.Errorf("SplitSauce = %q, %v; want the text and no record", content, sauce)
+	}
+
+	// Fields longer than their slot are cut
+	rec := (&Sauce{Title: string(bytes.Repeat([]byte("x"), 50))}).Bytes()
+	if _, sauce := SplitSauce(rec); sauce == nil || len(sauce.Title) != 35 {
+		t.Errorf("long title not cut to 35 characters: %+v", sauce)
+	}
+}
+
+func TestCP437(t *testing.T) {
+	for _, tt := range []struct {
+		b byte
+		r rune
+	}{{0x41, 'A'}, {0xB0, '░'}, {0xDB, '█'}, {0xDF, '▀'}, {0xC4, '─'}, {0x03, '♥'}, {0xE1, 'ß'}} {
+		if got := DecodeCP437(tt.b); got != tt.r {
+			t.Errorf("DecodeCP437(%#x) = %q, want %q", tt.b, got, tt.r)
+		}
+		if got, ok := EncodeCP437(tt.r); !ok || got != tt.b {
+			t.Errorf("EncodeCP437(%q) = %#x, %v; want %#x", tt.r, got, ok, tt.b)
+		}
+	}
+	if b, ok := EncodeCP437('╭'); !ok || b != 0xDA {
+		t.Errorf("EncodeCP437('╭') = %#x, %v; want the ┌ substitute", b, ok)
+	}
+	if b, ok := EncodeCP437('😀'); ok || b != '?' {
+		t.Errorf("EncodeCP437('😀') = %#x, %v; want '?', false", b, ok)
+	}
+}
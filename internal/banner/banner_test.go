@@ -2,7 +2,10 @@ package banner
 
 import (
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/ddmoney420/moji/internal/figlet"
 )
 
 func TestGenerate(t *testing.T) {
@@ -85,3 +88,106 @@ func TestGenerateSpecialChars(t *testing.T) {
 		t.Fatal("Generate(special chars) returned empty")
 	}
 }
+
+func TestGenerateWithOptionsLayout(t *testing.T) {
+	width := func(layout figlet.Layout) int {
+		art, err := GenerateWithOptions("Hello", "slant", Options{Layout: layout})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions(%v) error: %v", layout, err)
+		}
+		return len(strings.Split(art, "\n")[0])
+	}
+
+	full := width(figlet.LayoutFull)
+	kern := width(figlet.LayoutKerning)
+	smush := width(figlet.LayoutSmush)
+	if !(full > kern && kern > smush) {
+		t.Errorf("expected full > kerning > smush widths, got %d, %d, %d", full, kern, smush)
+	}
+	if def := width(figlet.LayoutDefault); def != smush {
+		t.Errorf("slant default layout should smush: got width %d, want %d", def, smush)
+	}
+}
+
+func TestGenerateGermanText(t *testing.T) {
+	missing, err := MissingGlyphs("Größe", "standard")
+	if err != nil {
+		t.Fatalf("MissingGlyphs() error: %v", err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("standard font should have Deutsch glyphs, missing %q", string(missing))
+	}
+
+	withUmlaut, _ := Generate("ö", "standard")
+	plain, _ := Generate(" ", "standard")
+	if withUmlaut == plain {
+		t.Error("ö should not render as a space")
+	}
+}
+
+func TestGenerateMissingError(t *testing.T) {
+	_, err := GenerateWithOptions("☃", "standard", Options{Missing: figlet.MissingError})
+	if err == nil {
+		t.Error("expected error for a character the font lacks")
+	}
+}
+
+func TestGenerateWithWidthWraps(t *testing.T) {
+	art, err := GenerateWithOptions("a long product tagline", "standard", Options{Width: 40})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() error: %v", err)
+	}
+	for _, line := range strings.Split(art, "\n") {
+		if len([]rune(line)) > 40 {
+			t.Errorf("line exceeds width 40: %q", line)
+		}
+	}
+
+	single, _ := Generate("a long product tagline", "standard")
+	if strings.Count(art, "\n") <= strings.Count(single, "\n") {
+		t.Error("wrapped banner should have more rows than the single-line banner")
+	}
+}
+
+func TestGenerateConcurrent(t *testing.T) {
+	fonts := []string{"standard", "slant", "doom", "calvin", "big", "3d"}
+	want := make(map[string]string)
+	for _, f := range fonts {
+		want[f], _ = Generate("Go", f)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 64)
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			font := fonts[i%len(fonts)]
+			art, err := Generate("Go", font)
+			if err != nil || art != want[font] {
+				errs <- font
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for font := range errs {
+		t.Errorf("concurrent Generate with %q differed from sequential output", font)
+	}
+}
+
+func TestCacheCapacity(t *testing.T) {
+	SetCacheCapacity(2)
+	defer SetCacheCapacity(DefaultCacheCapacity)
+
+	for _, f := range []string{"standard", "slant", "doom"} {
+		if _, err := Generate("A", f); err != nil {
+			t.Fatalf("Generate(%q) error: %v", f, err)
+		}
+	}
+	stats := CacheStats()
+	if stats.Size != 2 || stats.Capacity != 2 || stats.Evictions == 0 {
+		t.Errorf("CacheStats() = %+v, want 2 cached fonts after an eviction", stats)
+	}
+}
@@ -0,0 +1,424 @@
+package banner
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ddmoney420/moji/internal/figlet"
+)
+
+// Font categories
+const (
+	Category3D         = "3d"
+	CategoryGraffiti   = "graffiti"
+	CategoryRetro      = "retro"
+	CategoryBig        = "big"
+	CategoryClean      = "clean"
+	CategoryDecorative = "decorative"
+	CategoryFun        = "fun"
+	CategorySmall      = "small"
+	CategoryOther      = "other" // Embedded fonts without a curated entry
+	CategoryUser       = "user"  // User fonts
+)
+
+// curatedFont is the hand-maintained part of a catalog entry: everything
+// else is read from the font file
+type curatedFont struct {
+	Name     string
+	File     string
+	Desc     string
+	Category string
+	Tags     []string
+	Aliases  []string
+}
+
+// curatedFonts lists the embedded fonts in display order
+var curatedFonts = []curatedFont{
+	// 3D Styles
+	{"3d", "3d.flf", "3D block letters", Category3D, []string{"blocks"}, nil},
+	{"3d-ascii", "3D-ASCII.flf", "3D ASCII art", Category3D, nil, nil},
+	{"isometric1", "Isometric1.flf", "3D isometric view", Category3D, []string{"isometric"}, []string{"isometric"}},
+	{"isometric2", "Isometric2.flf", "3D isometric alt", Category3D, []string{"isometric"}, nil},
+	{"isometric3", "Isometric3.flf", "3D isometric blocks", Category3D, []string{"isometric"}, nil},
+	{"henry-3d", "Henry-3D.flf", "Henry 3D style", Category3D, []string{"outline"}, []string{"henry3d"}},
+	{"larry-3d", "Larry-3D.flf", "Larry 3D effect", Category3D, []string{"outline"}, []string{"larry3d"}},
+	{"impossible", "Impossible.flf", "Impossible 3D", Category3D, []string{"outline"}, nil},
+
+	// Graffiti/Street
+	{"graffiti", "graffiti.flf", "Street art graffiti", CategoryGraffiti, []string{"street"}, nil},
+	{"bloody", "bloody.flf", "Dripping blood horror", CategoryGraffiti, []string{"horror", "drip"}, nil},
+	{"poison", "Poison.flf", "Toxic dripping", CategoryGraffiti, []string{"drip"}, nil},
+	{"ghost", "Ghost.flf", "Spooky ghost style", CategoryGraffiti, []string{"horror"}, nil},
+	{"swamp-land", "Swamp-Land.flf", "Swampy dripping", CategoryGraffiti, []string{"drip"}, []string{"swamp"}},
+
+	// Retro/Gaming
+	{"doom", "doom.flf", "DOOM game style", CategoryRetro, []string{"gaming"}, nil},
+	{"dos-rebel", "DOS-Rebel.flf", "DOS Rebel retro", CategoryRetro, []string{"gaming", "blocks"}, []string{"dosrebel"}},
+	{"star-wars", "Star-Wars.flf", "Star Wars crawl", CategoryRetro, []string{"movie"}, []string{"starwars"}},
+	{"electronic", "Electronic.flf", "Electronic/digital", CategoryRetro, []string{"digital", "blocks"}, nil},
+	{"speed", "Speed.flf", "Speed racing", CategoryRetro, []string{"italic"}, nil},
+	{"sub-zero", "Sub-Zero.flf", "Sub-Zero frozen", CategoryRetro, []string{"gaming"}, []string{"subzero"}},
+
+	// Big & Bold
+	{"colossal", "colossal.flf", "Extra large letters", CategoryBig, []string{"large"}, nil},
+	{"epic", "Epic.flf", "Epic massive", CategoryBig, []string{"large"}, nil},
+	{"doh", "Doh.flf", "Homer Simpson DOH!", CategoryBig, []string{"large"}, nil},
+	{"big", "big.flf", "Large block letters", CategoryBig, []string{"large"}, nil},
+	{"banner3-d", "Banner3-D.flf", "3D banner style", CategoryBig, []string{"large", "3d"}, []string{"banner3d"}},
+	{"bulbhead", "Bulbhead.flf", "Bulbous letters", CategoryBig, []string{"round"}, nil},
+
+	// Clean/Classic
+	{"standard", "Standard.flf", "Default FIGlet", CategoryClean, nil, nil},
+	{"slant", "slant.flf", "Italic/slanted", CategoryClean, []string{"italic"}, nil},
+	{"shadow", "shadow.flf", "Letters with shadow", CategoryClean, []string{"blocks", "shadow"}, nil},
+	{"ansi-shadow", "ANSI-Shadow.flf", "ANSI shadow style", CategoryClean, []string{"blocks", "shadow"}, []string{"ansishadow"}},
+	{"ansi-regular", "ANSI-Regular.flf", "ANSI regular", CategoryClean, []string{"blocks"}, []string{"ansi"}},
+	{"roman", "Roman.flf", "Roman style", CategoryClean, []string{"serif"}, nil},
+	{"lean", "Lean.flf", "Lean style", CategoryClean, []string{"italic"}, nil},
+	{"univers", "Univers.flf", "Universal style", CategoryClean, []string{"sans"}, nil},
+
+	// Decorative
+	{"fraktur", "Fraktur.flf", "Gothic fraktur", CategoryDecorative, []string{"gothic", "serif"}, nil},
+	{"alligator", "Alligator.flf", "Alligator scales", CategoryDecorative, []string{"texture"}, nil},
+	{"arrows", "Arrows.flf", "Made of arrows", CategoryDecorative, []string{"symbols"}, nil},
+	{"blocks", "Blocks.flf", "Block elements", CategoryDecorative, []string{"boxed"}, nil},
+	{"rectangles", "Rectangles.flf", "Rectangle shapes", CategoryDecorative, []string{"boxed"}, nil},
+	{"varsity", "Varsity.flf", "Sports varsity", CategoryDecorative, []string{"sports", "serif"}, nil},
+	{"crawford", "Crawford.flf", "Crawford style", CategoryDecorative, []string{"serif"}, nil},
+	{"delta-corps", "Delta-Corps-Priest-1.flf", "Delta Corps military", CategoryDecorative, []string{"military", "blocks"}, []string{"deltacorps"}},
+
+	// Fun/Quirky
+	{"weird", "Weird.flf", "Weird distorted", CategoryFun, []string{"distorted"}, nil},
+	{"twisted", "Twisted.flf", "Twisted letters", CategoryFun, []string{"distorted"}, nil},
+	{"crazy", "Crazy.flf", "Crazy style", CategoryFun, []string{"distorted"}, nil},
+	{"whimsy", "Whimsy.flf", "Whimsical", CategoryFun, []string{"script"}, nil},
+	{"puffy", "Puffy.flf", "Puffy clouds", CategoryFun, []string{"round"}, nil},
+	{"chunky", "Chunky.flf", "Chunky blocks", CategoryFun, []string{"blocks"}, nil},
+	{"pebbles", "Pebbles.flf", "Pebble texture", CategoryFun, []string{"texture"}, nil},
+	{"tinker-toy", "Tinker-Toy.flf", "Tinker toy style", CategoryFun, []string{"outline"}, []string{"tinkertoy"}},
+	{"rozzo", "Rozzo.flf", "Rozzo style", CategoryFun, []string{"round"}, nil},
+	{"fire", "Fire-Font-s.flf", "Fire/flames", CategoryFun, []string{"flames"}, nil},
+	{"cyberlarge", "Cyberlarge.flf", "Cyberpunk large", CategoryFun, []string{"cyberpunk"}, []string{"cyber"}},
+
+	// Small/Compact
+	{"elite", "elite.flf", "Elite/1337 style", CategorySmall, []string{"leet", "blocks"}, nil},
+	{"ogre", "Ogre.flf", "Ogre style", CategorySmall, []string{"compact"}, nil},
+	{"peaks", "Peaks.flf", "Mountain peaks", CategorySmall, []string{"texture"}, nil},
+	{"nancyj", "Nancyj.flf", "Nancy J style", CategorySmall, []string{"compact"}, nil},
+	{"calvin", "Calvin-S.flf", "Calvin style", CategorySmall, []string{"compact", "blocks"}, []string{"calvin-s"}},
+}
+
+// FontMeta describes a font: curated fields plus metadata read from the font file
+type FontMeta struct {
+	Name        string     `json:"name"`
+	File        string     `json:"file"`
+	Source      FontSource `json:"source"`
+	Desc        string     `json:"desc"`
+	Category    string     `json:"category"`
+	Tags        []string   `json:"tags,omitempty"`
+	Aliases     []string   `json:"aliases,omitempty"`
+	Format      string     `json:"format,omitempty"` // flf2a or tlf2a
+	Height      int        `json:"height"`
+	Baseline    int        `json:"baseline"`
+	MaxLength   int        `json:"max_length"`
+	Layout      string     `json:"layout"`  // Default horizontal layout: full, kerning or smush
+	RightToLeft bool       `json:"rtl"`     // Font prints right to left
+	Glyphs      int        `json:"glyphs"`  // Total defined characters
+	ASCII       int        `json:"ascii"`   // Printable ASCII characters defined, out of 95
+	Deutsch     bool       `json:"deutsch"` // Defines all of Ä Ö Ü ä ö ü ß
+	Unicode     bool       `json:"unicode"` // Glyphs are drawn with non-ASCII characters
+	Comment     string     `json:"comment,omitempty"`
+	Error       string     `json:"error,omitempty"` // Set when the font file cannot be parsed
+}
+
+// FontFilter selects catalog entries
+type FontFilter struct {
+	Category  string // Match a category or tag (empty matches all)
+	MinHeight int    // Minimum height in rows (0 matches all)
+	MaxHeight int    // Maximum height in rows (0 matches all)
+}
+
+// Match reports whether a font passes the filter
+func (f FontFilter) Match(m FontMeta) bool {
+	if f.MinHeight > 0 && m.Height < f.MinHeight {
+		return false
+	}
+	if f.MaxHeight > 0 && m.Height > f.MaxHeight {
+		return false
+	}
+	if f.Category == "" || strings.EqualFold(m.Category, f.Category) {
+		return true
+	}
+	for _, tag := range m.Tags {
+		if strings.EqualFold(tag, f.Category) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterFonts returns the fonts that pass the filter
+func FilterFonts(fonts []FontMeta, filter FontFilter) []FontMeta {
+	var out []FontMeta
+	for _, m := range fonts {
+		if filter.Match(m) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// ListCategories returns the font categories in display order
+func ListCategories() []string {
+	return []string{
+		Category3D, CategoryGraffiti, CategoryRetro, CategoryBig, CategoryClean,
+		CategoryDecorative, CategoryFun, CategorySmall, CategoryOther, CategoryUser,
+	}
+}
+
+// embeddedCatalog is built once from the curated list and the embed FS
+type embeddedCatalog struct {
+	fonts   []curatedFont     // Curated fonts followed by uncurated embedded fonts
+	names   map[string]string // Name or alias -> embedded file
+	files   []string          // All embedded font files
+	meta    []FontMeta
+	metaErr error
+}
+
+var (
+	embeddedOnce sync.Once
+	embedded     *embeddedCatalog
+	metaOnce     sync.Once
+)
+
+// embeddedFonts returns the embedded font index
+func embeddedFonts() *embeddedCatalog {
+	embeddedOnce.Do(func() {
+		embedded = buildEmbeddedCatalog(curatedFonts, embeddedFiles())
+	})
+	return embedded
+}
+
+// embeddedFiles lists the font files in the embed FS
+func embeddedFiles() []string {
+	entries, err := fs.ReadDir(fontsFS, "fonts")
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && isFontFile(e.Name()) {
+			files = append(files, e.Name())
+		}
+	}
+	return files
+}
+
+// buildEmbeddedCatalog indexes the curated fonts and gives every uncurated
+// font file a name derived from its file name, unless that name is taken
+func buildEmbeddedCatalog(curated []curatedFont, files []string) *embeddedCatalog {
+	c := &embeddedCatalog{
+		fonts: append([]curatedFont(nil), curated...),
+		names: make(map[string]string),
+		files: files,
+	}
+
+	curatedFiles := make(map[string]bool)
+	for _, f := range curated {
+		curatedFiles[strings.ToLower(f.File)] = true
+		c.names[f.Name] = f.File
+		for _, alias := range f.Aliases {
+			c.names[alias] = f.File
+		}
+	}
+
+	for _, file := range files {
+		if curatedFiles[strings.ToLower(file)] {
+			continue
+		}
+		name := FontNameFromFile(file)
+		if _, taken := c.names[name]; taken {
+			continue
+		}
+		c.names[name] = file
+		c.fonts = append(c.fonts, curatedFont{
+			Name:     name,
+			File:     file,
+			Desc:     strings.TrimSuffix(file, filepath.Ext(file)),
+			Category: CategoryOther,
+		})
+	}
+	return c
+}
+
+// embeddedFontFile maps a font name or alias to an embedded font file
+func embeddedFontFile(name string) string {
+	return embeddedFonts().names[FontNameFromFile(name)]
+}
+
+// UnreachableFonts returns embedded font files that no font name resolves to
+func UnreachableFonts() []string {
+	c := embeddedFonts()
+	reachable := make(map[string]bool)
+	for _, file := range c.names {
+		reachable[strings.ToLower(file)] = true
+	}
+
+	var files []string
+	for _, file := range c.files {
+		if !reachable[strings.ToLower(file)] {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// embeddedMeta returns metadata for the embedded fonts, parsing each font once
+func embeddedMeta() []FontMeta {
+	metaOnce.Do(func() {
+		c := embeddedFonts()
+		c.meta = make([]FontMeta, len(c.fonts))
+		for i, f := range c.fonts {
+			meta := FontMeta{
+				Name:     f.Name,
+				File:     f.File,
+				Source:   SourceEmbedded,
+				Desc:     f.Desc,
+				Category: f.Category,
+				Tags:     f.Tags,
+				Aliases:  f.Aliases,
+			}
+			c.meta[i] = readFontMeta(meta, FontEntry{Name: f.Name, File: f.File, Source: SourceEmbedded})
+		}
+	})
+	return embeddedFonts().meta
+}
+
+// readFontMeta fills in the metadata read from a font file
+func readFontMeta(meta FontMeta, entry FontEntry) FontMeta {
+	data, err := readFontFile(entry)
+	if err != nil {
+		meta.Error = err.Error()
+		return meta
+	}
+	font, err := figlet.ParseFont(string(data))
+	if err != nil {
+		meta.Error = err.Error()
+		return meta
+	}
+
+	h := font.Header
+	meta.Format = h.Signature
+	meta.Height = h.Height
+	meta.Baseline = h.Baseline
+	meta.MaxLength = h.MaxLength
+	meta.Layout = h.HorizontalLayout().String()
+	meta.RightToLeft = h.PrintDirection == 1
+	meta.Glyphs = len(font.Characters)
+	meta.Comment = font.Comment
+
+	for r := rune(32); r <= 126; r++ {
+		if font.HasGlyph(r) {
+			meta.ASCII++
+		}
+	}
+	meta.Deutsch = true
+	for _, r := range "ÄÖÜäöüß" {
+		if !font.HasGlyph(r) {
+			meta.Deutsch = false
+		}
+	}
+	meta.Unicode = usesUnicode(font)
+	return meta
+}
+
+// usesUnicode reports whether any glyph is drawn with non-ASCII characters
+// (other than the hardblank)
+func usesUnicode(font *figlet.Font) bool {
+	for code, lines := range font.Characters {
+		if code < 32 || code > 126 {
+			continue
+		}
+		for _, line := range lines {
+			for _, r := range line {
+				if r > 126 && r != font.Header.HardBlank {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// userFontMeta returns metadata for a user font
+func userFontMeta(entry FontEntry) FontMeta {
+	meta := FontMeta{
+		Name:     entry.Name,
+		File:     entry.File,
+		Source:   entry.Source,
+		Desc:     "User font (" + filepath.Base(entry.File) + ")",
+		Category: CategoryUser,
+	}
+	return readFontMeta(meta, entry)
+}
+
+// Catalog returns metadata for every available font: embedded fonts in
+// display order, then user fonts. A user font that shadows an embedded font
+// takes its place.
+func Catalog() []FontMeta {
+	builtin := embeddedMeta()
+	fonts := make([]FontMeta, len(builtin))
+	copy(fonts, builtin)
+
+	index := make(map[string]int, len(fonts))
+	for i, f := range fonts {
+		index[f.Name] = i
+	}
+
+	for _, entry := range UserFonts() {
+		meta := userFontMeta(entry)
+		if i, shadowed := index[entry.Name]; shadowed {
+			fonts[i] = meta
+			continue
+		}
+		fonts = append(fonts, meta)
+	}
+	return fonts
+}
+
+// FontDetails returns catalog metadata for a font name or alias
+func FontDetails(name string) (FontMeta, error) {
+	entry, ok := LookupFont(name)
+	if !ok {
+		return FontMeta{}, fmt.Errorf("font '%s' not found", name)
+	}
+	if entry.Source != SourceEmbedded {
+		return userFontMeta(entry), nil
+	}
+	for _, meta := range embeddedMeta() {
+		if meta.File == entry.File {
+			return meta, nil
+		}
+	}
+	return FontMeta{}, fmt.Errorf("font '%s' not found", name)
+}
+
+// ListTags returns the tags used by the built-in fonts
+func ListTags() []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, f := range embeddedMeta() {
+		for _, tag := range f.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
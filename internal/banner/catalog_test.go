@@ -0,0 +1,107 @@
+package banner
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCuratedFontFilesExist(t *testing.T) {
+	for _, f := range curatedFonts {
+		if _, err := fontsFS.ReadFile("fonts/" + f.File); err != nil {
+			t.Errorf("curated font %q: %v", f.Name, err)
+		}
+	}
+}
+
+func TestUnreachableFontsAreDuplicates(t *testing.T) {
+	reachable := make(map[string][]byte)
+	for _, meta := range embeddedMeta() {
+		data, _ := fontsFS.ReadFile("fonts/" + meta.File)
+		reachable[meta.Name] = data
+	}
+
+	for _, file := range UnreachableFonts() {
+		data, err := fontsFS.ReadFile("fonts/" + file)
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		duplicate := false
+		for _, other := range reachable {
+			if bytes.Equal(data, other) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			t.Errorf("embedded font %s is not reachable by any name; add it to curatedFonts", file)
+		}
+	}
+}
+
+func TestBuildEmbeddedCatalog(t *testing.T) {
+	curated := []curatedFont{{"ansi-regular", "ANSI-Regular.flf", "ANSI regular", CategoryClean, nil, []string{"ansi"}}}
+	c := buildEmbeddedCatalog(curated, []string{"ANSI-Regular.flf", "ansi.flf", "New_Font.flf"})
+
+	if got := c.names["ansi"]; got != "ANSI-Regular.flf" {
+		t.Errorf("alias ansi = %q, want curated file", got)
+	}
+	if got := c.names["new-font"]; got != "New_Font.flf" {
+		t.Errorf("uncurated font name = %q, want New_Font.flf", got)
+	}
+	last := c.fonts[len(c.fonts)-1]
+	if last.Name != "new-font" || last.Category != CategoryOther {
+		t.Errorf("uncurated font entry = %+v", last)
+	}
+	if len(c.fonts) != 2 {
+		t.Errorf("ansi.flf should be shadowed by the alias, got %d fonts", len(c.fonts))
+	}
+}
+
+func TestCatalogMetadata(t *testing.T) {
+	meta, err := FontDetails("standard")
+	if err != nil {
+		t.Fatalf("FontDetails() error: %v", err)
+	}
+	if meta.Height != 6 || meta.Baseline != 5 {
+		t.Errorf("standard height/baseline = %d/%d, want 6/5", meta.Height, meta.Baseline)
+	}
+	if meta.Layout != "smush" || meta.ASCII != 95 || !meta.Deutsch {
+		t.Errorf("standard layout/ascii/deutsch = %s/%d/%v", meta.Layout, meta.ASCII, meta.Deutsch)
+	}
+	if meta.Comment == "" || meta.Unicode {
+		t.Errorf("standard comment/unicode = %q/%v", meta.Comment, meta.Unicode)
+	}
+
+	meta, err = FontDetails("ansi")
+	if err != nil || meta.Name != "ansi-regular" || !meta.Unicode {
+		t.Errorf("FontDetails(ansi) = %+v, %v; want ansi-regular with unicode glyphs", meta, err)
+	}
+
+	if _, err := FontDetails("nonexistent_font_xyz"); err == nil {
+		t.Error("FontDetails should fail for unknown fonts")
+	}
+}
+
+func TestFilterFonts(t *testing.T) {
+	catalog := Catalog()
+
+	for _, m := range FilterFonts(catalog, FontFilter{Category: "3d"}) {
+		hasTag := false
+		for _, tag := range m.Tags {
+			hasTag = hasTag || tag == "3d"
+		}
+		if m.Category != Category3D && !hasTag {
+			t.Errorf("font %q (%s) matched category 3d", m.Name, m.Category)
+		}
+	}
+
+	tall := FilterFonts(catalog, FontFilter{MinHeight: 10})
+	if len(tall) == 0 || len(tall) == len(catalog) {
+		t.Fatalf("MinHeight filter returned %d of %d fonts", len(tall), len(catalog))
+	}
+	for _, m := range tall {
+		if m.Height < 10 {
+			t.Errorf("font %q height %d passed MinHeight 10", m.Name, m.Height)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package banner
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ddmoney420/moji/internal/figlet"
+)
+
+//go:embed controls/*.flc
+var controlsFS embed.FS
+
+// ListControls returns the names of the embedded control files
+func ListControls() []string {
+	entries, _ := controlsFS.ReadDir("controls")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".flc"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadControl loads and joins control files, each given as a path to a .flc
+// file or the name of an embedded control file. Files are applied in order.
+func LoadControl(names ...string) (*figlet.Control, error) {
+	controls := make([]*figlet.Control, 0, len(names))
+	for _, name := range names {
+		data, err := readControlFile(name)
+		if err != nil {
+			return nil, err
+		}
+		c, err := figlet.ParseControl(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("control file '%s': %w", name, err)
+		}
+		controls = append(controls, c)
+	}
+	return figlet.JoinControls(controls...), nil
+}
+
+// readControlFile reads a control file from disk, falling back to the
+// embedded control files
+func readControlFile(name string) ([]byte, error) {
+	if data, err := os.ReadFile(name); err == nil {
+		return data, nil
+	}
+	base := strings.ToLower(strings.TrimSuffix(filepath.Base(name), ".flc"))
+	if data, err := controlsFS.ReadFile("controls/" + base + ".flc"); err == nil {
+		return data, nil
+	}
+	return nil, fmt.Errorf("control file '%s' not found (built-in: %s)", name, strings.Join(ListControls(), ", "))
+}
@@ -0,0 +1,88 @@
+package banner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ddmoney420/moji/internal/figlet"
+)
+
+func TestEmbeddedControlsParse(t *testing.T) {
+	names := ListControls()
+	if len(names) == 0 {
+		t.Fatal("no embedded control files")
+	}
+	for _, name := range names {
+		if _, err := LoadControl(name); err != nil {
+			t.Errorf("LoadControl(%q) error: %v", name, err)
+		}
+	}
+}
+
+func TestLoadControl(t *testing.T) {
+	c, err := LoadControl("8859-5")
+	if err != nil {
+		t.Fatalf("LoadControl() error: %v", err)
+	}
+	// "\xb4\xd0" is "Да" in ISO 8859-5
+	if got := c.Translate("\xb4\xd0"); got != "Да" {
+		t.Errorf("8859-5 Translate() = %q, want Да", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "swap.flc")
+	if err := os.WriteFile(path, []byte("flc2a\nt A Z\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err = LoadControl("upper", path)
+	if err != nil {
+		t.Fatalf("LoadControl() error: %v", err)
+	}
+	if got := c.Translate("abc"); got != "ZBC" {
+		t.Errorf("joined Translate() = %q, want ZBC", got)
+	}
+
+	if _, err := LoadControl("nonexistent_control_xyz"); err == nil || !strings.Contains(err.Error(), "upper") {
+		t.Errorf("LoadControl of an unknown name should list built-in files, got %v", err)
+	}
+}
+
+func TestKeyboardAndMultibyteControls(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"ilhebrew", "akuo", "שלום"},
+		{"uskata", "t]", "ｶﾟ"},
+		{"jis0201", "\xb6\xc0\\", "ｶﾀ¥"},
+		{"hz", "a~{0!~}b", "a\ub0a1b"},
+	}
+	for _, tt := range tests {
+		c, err := LoadControl(tt.name)
+		if err != nil {
+			t.Fatalf("LoadControl(%q) error: %v", tt.name, err)
+		}
+		if got := c.Translate(tt.in); got != tt.want {
+			t.Errorf("%s Translate(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateWithControl(t *testing.T) {
+	c, err := LoadControl("upper")
+	if err != nil {
+		t.Fatalf("LoadControl() error: %v", err)
+	}
+	got, err := GenerateWithOptions("hi", "standard", Options{Control: c})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() error: %v", err)
+	}
+	want, _ := Generate("HI", "standard")
+	if got != want {
+		t.Errorf("upper control should render like uppercase input:\n%s\nwant:\n%s", got, want)
+	}
+	var none *figlet.Control
+	if plain, _ := GenerateWithOptions("hi", "standard", Options{Control: none}); plain == want {
+		t.Error("a nil control should leave text unchanged")
+	}
+}
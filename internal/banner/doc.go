@@ -1,13 +1,17 @@
 // Package banner provides FIGlet font integration for generating ASCII text banners.
 //
-// It manages embedded FIGlet fonts with caching support, allowing users to render text in various
+// It manages embedded FIGlet fonts with an LRU cache of parsed fonts, allowing users to render text in various
 // decorative ASCII art styles. The package provides access to 40+ fonts including 3D, retro/gaming,
-// graffiti, and other artistic styles.
+// graffiti, and other artistic styles, plus user FIGlet (.flf) and TOIlet (.tlf) fonts from the user font
+// directory and configured font paths. The font catalog combines curated categories and tags with
+// metadata read from each font file (height, layout, glyph coverage, comments). All functions
+// are safe for concurrent use.
 //
 // Example usage:
 //
 //	font := banner.GetFont("shadow")
 //	result := banner.Generate("HELLO", font)
 //	fonts := banner.ListFonts()
-//	banner.SetCustomFontPath("/path/to/fonts")
+//	retro := banner.FilterFonts(banner.Catalog(), banner.FontFilter{Category: "retro"})
+//	banner.SetFontPaths([]string{"/path/to/fonts"})
 package banner
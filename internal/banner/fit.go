@@ -0,0 +1,151 @@
+package banner
+
+import (
+	"strings"
+
+	"github.com/ddmoney420/moji/internal/figlet"
+)
+
+// DefaultFitFonts is the fallback chain used when no other is configured,
+// from widest to narrowest
+var DefaultFitFonts = []string{"standard", "calvin"}
+
+// Fit steps, in the order they are tried
+const (
+	FitAsRequested = "requested" // The requested font and layout fit
+	FitLayout      = "layout"    // The requested font fits with a tighter layout
+	FitFallback    = "fallback"  // A font from the fallback chain fits
+	FitWrap        = "wrap"      // The text was wrapped onto several lines
+)
+
+// FitResult describes the banner chosen by Fit
+type FitResult struct {
+	Art     string `json:"-"`
+	Font    string `json:"font"`
+	Layout  string `json:"layout"`
+	Step    string `json:"step"`
+	Width   int    `json:"width"`   // Width of the art in columns
+	Target  int    `json:"target"`  // Width the art had to fit
+	Fits    bool   `json:"fits"`    // False when even wrapping could not fit the target
+	Wrapped bool   `json:"wrapped"` // Text was wrapped onto several lines
+}
+
+// Fit renders text no wider than width. It tries the requested font with the
+// requested layout, then with kerning and smushing, then each font in the
+// fallback chain (DefaultFitFonts when nil), and finally wraps the text.
+// Fonts in the chain that don't exist are skipped.
+func Fit(text, fontName string, width int, opts Options, fallbacks []string) (FitResult, error) {
+	if fallbacks == nil {
+		fallbacks = DefaultFitFonts
+	}
+	opts.Width = 0
+	requested := opts.Layout
+	layouts := fitLayouts(fontName, requested)
+
+	var last FitResult
+	for i, layout := range layouts {
+		opts.Layout = layout
+		art, err := GenerateWithOptions(text, fontName, opts)
+		if err != nil {
+			return FitResult{}, err
+		}
+		step := FitLayout
+		if i == 0 {
+			step = FitAsRequested
+		}
+		last = newFitResult(art, fontName, layout, step, width)
+		if last.Fits {
+			return last, nil
+		}
+	}
+
+	var chain []string
+	for _, name := range fallbacks {
+		if _, ok := LookupFont(name); ok && !strings.EqualFold(name, fontName) {
+			chain = append(chain, name)
+		}
+	}
+
+	for _, name := range chain {
+		for _, layout := range fitLayouts(name, requested) {
+			opts.Layout = layout
+			art, err := GenerateWithOptions(text, name, opts)
+			if err != nil {
+				continue
+			}
+			if result := newFitResult(art, name, layout, FitFallback, width); result.Fits {
+				return result, nil
+			}
+		}
+	}
+
+	// Wrap with the tightest layout, trying narrower fonts when single
+	// characters of the requested font are too wide
+	opts.Width = width
+	for _, name := range append([]string{fontName}, chain...) {
+		tried := fitLayouts(name, requested)
+		opts.Layout = tried[len(tried)-1]
+		art, err := GenerateWithOptions(text, name, opts)
+		if err != nil {
+			continue
+		}
+		last = newFitResult(art, name, opts.Layout, FitWrap, width)
+		last.Wrapped = true
+		if last.Fits {
+			return last, nil
+		}
+	}
+	return last, nil
+}
+
+// fitLayouts returns the layouts to try for a font, starting with the
+// requested one and getting tighter. Smushing is only tried for fonts that
+// define smushing rules or smush by default: universal smushing of other
+// fonts overlaps strokes and makes the text unreadable.
+func fitLayouts(fontName string, requested figlet.Layout) []figlet.Layout {
+	tighter := []figlet.Layout{figlet.LayoutKerning}
+	if font, err := loadFont(fontName); err == nil {
+		h := font.Header
+		if h.SmushRules() != 0 || h.HorizontalLayout() == figlet.LayoutSmush {
+			tighter = append(tighter, figlet.LayoutSmush)
+		}
+	}
+
+	layouts := []figlet.Layout{requested}
+	for _, l := range tighter {
+		if l != requested {
+			layouts = append(layouts, l)
+		}
+	}
+	return layouts
+}
+
+// newFitResult measures art against the target width
+func newFitResult(art, fontName string, layout figlet.Layout, step string, target int) FitResult {
+	if layout == figlet.LayoutDefault {
+		if font, err := loadFont(fontName); err == nil {
+			layout = font.Header.HorizontalLayout()
+		}
+	}
+	width := artWidth(art)
+	return FitResult{
+		Art:    art,
+		Font:   FontNameFromFile(fontName),
+		Layout: layout.String(),
+		Step:   step,
+		Width:  width,
+		Target: target,
+		Fits:   width <= target,
+	}
+}
+
+// artWidth returns the width of the widest line in columns
+func artWidth(art string) int {
+	width := 0
+	for _, line := range strings.Split(art, "\n") {
+		if n := len([]rune(line)); n > width {
+			width = n
+		}
+	}
+	return width
+}
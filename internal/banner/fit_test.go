@@ -0,0 +1,52 @@
+package banner
+
+import (
+	"testing"
+
+	"github.com/ddmoney420/moji/internal/figlet"
+)
+
+func TestFitAsRequested(t *testing.T) {
+	result, err := Fit("Hi", "slant", 80, Options{}, nil)
+	if err != nil {
+		t.Fatalf("Fit() error: %v", err)
+	}
+	want, _ := Generate("Hi", "slant")
+	if result.Step != FitAsRequested || result.Font != "slant" || result.Art != want {
+		t.Errorf("Fit() = %+v, want slant as requested", result)
+	}
+}
+
+func TestFitFallbackFont(t *testing.T) {
+	result, err := Fit("Hello World", "doom", 40, Options{}, nil)
+	if err != nil {
+		t.Fatalf("Fit() error: %v", err)
+	}
+	if result.Step != FitFallback || result.Font != "calvin" || !result.Fits {
+		t.Errorf("Fit() = %+v, want calvin from the fallback chain", result)
+	}
+	if result.Width > 40 || artWidth(result.Art) != result.Width {
+		t.Errorf("Fit() width = %d, art width %d", result.Width, artWidth(result.Art))
+	}
+}
+
+func TestFitWraps(t *testing.T) {
+	result, err := Fit("Hello World", "doh", 30, Options{}, []string{"nonexistent_font_xyz", "standard"})
+	if err != nil {
+		t.Fatalf("Fit() error: %v", err)
+	}
+	if result.Step != FitWrap || !result.Wrapped || !result.Fits || result.Font != "standard" {
+		t.Errorf("Fit() = %+v, want standard wrapped to fit", result)
+	}
+}
+
+func TestFitLayouts(t *testing.T) {
+	if got := fitLayouts("standard", figlet.LayoutDefault); len(got) != 3 {
+		t.Errorf("standard layouts = %v, want default, kerning, smush", got)
+	}
+	// calvin has no smushing rules, so universal smushing is never tried
+	got := fitLayouts("calvin", figlet.LayoutKerning)
+	if len(got) != 1 || got[0] != figlet.LayoutKerning {
+		t.Errorf("calvin layouts = %v, want kerning only", got)
+	}
+}
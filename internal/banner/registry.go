@@ -0,0 +1,296 @@
+package banner
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ddmoney420/moji/internal/config"
+	"github.com/ddmoney420/moji/internal/figlet"
+)
+
+// FontSource identifies where a font was found
+type FontSource string
+
+const (
+	SourceEmbedded FontSource = "embedded" // Shipped inside the moji binary
+	SourceUser     FontSource = "user"     // Installed into the user font directory
+	SourcePath     FontSource = "path"     // Found in a configured font path
+)
+
+// FontEntry describes a font known to the registry
+type FontEntry struct {
+	Name   string
+	File   string // Embedded file name, or the full path for user fonts
+	Source FontSource
+}
+
+// fontExtensions are the file extensions scanned for user fonts: FIGlet
+// fonts and TOIlet fonts
+var fontExtensions = []string{".flf", ".tlf"}
+
+// registry resolves font names to embedded or user font files.
+//
+// Collision rules: fonts in the user font directory win over fonts in the
+// configured font paths, earlier font paths win over later ones, and any
+// user font shadows an embedded font (or alias) with the same name.
+type registry struct {
+	mu        sync.RWMutex
+	paths     []string
+	userFonts map[string]FontEntry
+	scanned   bool
+}
+
+var fontRegistry = &registry{}
+
+// SetFontPaths sets additional directories to search for user fonts
+func SetFontPaths(paths []string) {
+	fontRegistry.mu.Lock()
+	defer fontRegistry.mu.Unlock()
+
+	fontRegistry.paths = append([]string(nil), paths...)
+	fontRegistry.scanned = false
+}
+
+// UserFontDir returns the directory fonts are installed into
+func UserFontDir() string {
+	return config.FontDir()
+}
+
+// RescanFonts forgets previously discovered user fonts so the next lookup
+// scans the font directories again
+func RescanFonts() {
+	fontRegistry.mu.Lock()
+	defer fontRegistry.mu.Unlock()
+
+	fontRegistry.scanned = false
+}
+
+// LookupFont resolves a font name to a user or embedded font
+func LookupFont(name string) (FontEntry, bool) {
+	key := FontNameFromFile(name)
+	if entry, ok := fontRegistry.user()[key]; ok {
+		return entry, true
+	}
+	if file := embeddedFontFile(name); file != "" {
+		return FontEntry{Name: key, File: file, Source: SourceEmbedded}, true
+	}
+	return FontEntry{}, false
+}
+
+// UserFonts returns fonts found in the user font directory and font paths,
+// sorted by name
+func UserFonts() []FontEntry {
+	fonts := fontRegistry.user()
+	entries := make([]FontEntry, 0, len(fonts))
+	for _, entry := range fonts {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// user returns the user font table, scanning the font directories if needed
+func (r *registry) user() map[string]FontEntry {
+	r.mu.RLock()
+	if r.scanned {
+		fonts := r.userFonts
+		r.mu.RUnlock()
+		return fonts
+	}
+	r.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.scanned {
+		r.userFonts = make(map[string]FontEntry)
+		if dir := UserFontDir(); dir != "" {
+			scanFontDir(r.userFonts, dir, SourceUser)
+		}
+		for _, dir := range r.paths {
+			scanFontDir(r.userFonts, dir, SourcePath)
+		}
+		r.scanned = true
+	}
+	return r.userFonts
+}
+
+// scanFontDir adds fonts in dir to fonts, keeping entries that already exist
+func scanFontDir(fonts map[string]FontEntry, dir string, source FontSource) {
+	entries, err := os.ReadDir(expandHome(dir))
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || !isFontFile(e.Name()) {
+			continue
+		}
+		name := FontNameFromFile(e.Name())
+		if _, exists := fonts[name]; exists {
+			continue
+		}
+		fonts[name] = FontEntry{
+			Name:   name,
+			File:   filepath.Join(expandHome(dir), e.Name()),
+			Source: source,
+		}
+	}
+}
+
+// isFontFile reports whether a file name has a supported font extension
+func isFontFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, fontExt := range fontExtensions {
+		if ext == fontExt {
+			return true
+		}
+	}
+	return false
+}
+
+// expandHome expands a leading ~ to the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// FontNameFromFile derives a font name from a file name: "Brand Logo.flf"
+// becomes "brand-logo"
+func FontNameFromFile(file string) string {
+	name := filepath.Base(file)
+	if isFontFile(name) {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "-", "_", "-").Replace(name)
+}
+
+// readFontFile reads a font file, unpacking it if it is zip-compressed
+// (figlet distributes many fonts as zip archives with a .flf extension)
+func readFontFile(entry FontEntry) ([]byte, error) {
+	var data []byte
+	var err error
+	if entry.Source == SourceEmbedded {
+		data, err = fontsFS.ReadFile("fonts/" + entry.File)
+		if err != nil {
+			// Try lowercase
+			data, err = fontsFS.ReadFile("fonts/" + strings.ToLower(entry.File))
+		}
+	} else {
+		data, err = os.ReadFile(entry.File)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unzipFont(data)
+}
+
+// unzipFont returns the first file of a zip archive, or data unchanged if it
+// is not a zip archive
+func unzipFont(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return data, nil
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip font: %w", err)
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("invalid zip font: %w", err)
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("zip font archive is empty")
+}
+
+// InstallFont copies a font file into the user font directory under name
+// (derived from the file name when empty). Installing over an existing font
+// name, embedded or user, requires force.
+func InstallFont(path, name string, force bool) (FontEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FontEntry{}, fmt.Errorf("failed to read font: %w", err)
+	}
+
+	content, err := unzipFont(data)
+	if err != nil {
+		return FontEntry{}, err
+	}
+	if _, err := figlet.ParseFont(string(content)); err != nil {
+		return FontEntry{}, fmt.Errorf("not a valid font: %w", err)
+	}
+
+	if name == "" {
+		name = path
+	}
+	name = FontNameFromFile(name)
+	if name == "" {
+		return FontEntry{}, fmt.Errorf("font name is empty")
+	}
+
+	if existing, ok := LookupFont(name); ok && !force {
+		return FontEntry{}, fmt.Errorf("font '%s' already exists (%s); use --force to replace it or --name to pick another name", name, existing.Source)
+	}
+
+	dir := UserFontDir()
+	if dir == "" {
+		return FontEntry{}, fmt.Errorf("cannot determine user font directory")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return FontEntry{}, fmt.Errorf("failed to create font directory: %w", err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if !isFontFile(path) {
+		ext = fontExtensions[0]
+	}
+	dest := filepath.Join(dir, name+ext)
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return FontEntry{}, fmt.Errorf("failed to install font: %w", err)
+	}
+
+	RescanFonts()
+	return FontEntry{Name: name, File: dest, Source: SourceUser}, nil
+}
+
+// RemoveFont deletes a font from the user font directory
+func RemoveFont(name string) error {
+	entry, ok := fontRegistry.user()[FontNameFromFile(name)]
+	if !ok {
+		if _, embedded := LookupFont(name); embedded {
+			return fmt.Errorf("font '%s' is built in and cannot be removed", name)
+		}
+		return fmt.Errorf("font '%s' is not installed", name)
+	}
+	if entry.Source != SourceUser {
+		return fmt.Errorf("font '%s' comes from configured font path %s; remove it there", name, filepath.Dir(entry.File))
+	}
+
+	if err := os.Remove(entry.File); err != nil {
+		return fmt.Errorf("failed to remove font: %w", err)
+	}
+
+	RescanFonts()
+	return nil
+}
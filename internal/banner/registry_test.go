@@ -0,0 +1,187 @@
+package banner
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setupFontDirs points the user font directory at a temp dir and returns a
+// path to a copy of an embedded font
+func setupFontDirs(t *testing.T) string {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	SetFontPaths(nil)
+	t.Cleanup(func() { SetFontPaths(nil) })
+
+	data, err := fontsFS.ReadFile("fonts/slant.flf")
+	if err != nil {
+		t.Fatalf("read embedded font: %v", err)
+	}
+	src := filepath.Join(t.TempDir(), "Brand Logo.flf")
+	if err := os.WriteFile(src, data, 0644); err != nil {
+		t.Fatalf("write font: %v", err)
+	}
+	return src
+}
+
+func TestFontNameFromFile(t *testing.T) {
+	tests := map[string]string{
+		"Brand Logo.flf":     "brand-logo",
+		"/fonts/my_font.flf": "my-font",
+		"Standard":           "standard",
+		"ANSI-Shadow.FLF":    "ansi-shadow",
+		"future.tlf":         "future",
+	}
+	for in, want := range tests {
+		if got := FontNameFromFile(in); got != want {
+			t.Errorf("FontNameFromFile(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestInstallAndRemoveFont(t *testing.T) {
+	src := setupFontDirs(t)
+
+	entry, err := InstallFont(src, "", false)
+	if err != nil {
+		t.Fatalf("InstallFont() error: %v", err)
+	}
+	if entry.Name != "brand-logo" || entry.Source != SourceUser {
+		t.Errorf("InstallFont() = %+v", entry)
+	}
+
+	art, err := Generate("Hi", "brand-logo")
+	if err != nil {
+		t.Fatalf("Generate with user font error: %v", err)
+	}
+	slant, _ := Generate("Hi", "slant")
+	if art != slant {
+		t.Error("user font copy of slant should render like slant")
+	}
+
+	if _, err := InstallFont(src, "", false); err == nil {
+		t.Error("installing over an existing user font without force should fail")
+	}
+	if _, err := InstallFont(src, "", true); err != nil {
+		t.Errorf("InstallFont(force) error: %v", err)
+	}
+
+	if err := RemoveFont("brand-logo"); err != nil {
+		t.Fatalf("RemoveFont() error: %v", err)
+	}
+	if _, ok := LookupFont("brand-logo"); ok {
+		t.Error("removed font should not be found")
+	}
+	if err := RemoveFont("standard"); err == nil {
+		t.Error("removing a built-in font should fail")
+	}
+}
+
+func TestInstallFontCollidesWithEmbedded(t *testing.T) {
+	src := setupFontDirs(t)
+
+	if _, err := InstallFont(src, "standard", false); err == nil {
+		t.Fatal("installing as 'standard' without force should fail")
+	}
+	if _, err := InstallFont(src, "standard", true); err != nil {
+		t.Fatalf("InstallFont(force) error: %v", err)
+	}
+
+	entry, _ := LookupFont("standard")
+	if entry.Source != SourceUser {
+		t.Errorf("user font should shadow embedded font, got source %s", entry.Source)
+	}
+}
+
+func TestInstallFontRejectsInvalid(t *testing.T) {
+	setupFontDirs(t)
+
+	bad := filepath.Join(t.TempDir(), "bad.flf")
+	os.WriteFile(bad, []byte("not a font"), 0644)
+	if _, err := InstallFont(bad, "", false); err == nil {
+		t.Error("InstallFont should reject files that are not fonts")
+	}
+}
+
+func TestFontPathsPrecedence(t *testing.T) {
+	src := setupFontDirs(t)
+	data, _ := os.ReadFile(src)
+
+	first, second := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(first, "shared.flf"), data, 0644)
+	os.WriteFile(filepath.Join(second, "shared.flf"), data, 0644)
+	os.WriteFile(filepath.Join(second, "notes.txt"), []byte("ignored"), 0644)
+
+	SetFontPaths([]string{first, second})
+
+	entry, ok := LookupFont("shared")
+	if !ok {
+		t.Fatal("font from font path not found")
+	}
+	if entry.Source != SourcePath || filepath.Dir(entry.File) != first {
+		t.Errorf("earlier font path should win, got %+v", entry)
+	}
+	if len(UserFonts()) != 1 {
+		t.Errorf("UserFonts() = %d entries, want 1", len(UserFonts()))
+	}
+	if err := RemoveFont("shared"); err == nil {
+		t.Error("fonts from configured paths should not be removable")
+	}
+}
+
+func TestZipCompressedFont(t *testing.T) {
+	src := setupFontDirs(t)
+	data, _ := os.ReadFile(src)
+
+	dir := t.TempDir()
+	f, _ := os.Create(filepath.Join(dir, "zipped.flf"))
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("zipped.flf")
+	w.Write(data)
+	zw.Close()
+	f.Close()
+
+	SetFontPaths([]string{dir})
+
+	art, err := Generate("Hi", "zipped")
+	if err != nil {
+		t.Fatalf("Generate with zipped font error: %v", err)
+	}
+	slant, _ := Generate("Hi", "slant")
+	if art != slant {
+		t.Error("zipped font should render like the original")
+	}
+}
+
+func TestUserFontCacheKey(t *testing.T) {
+	src := setupFontDirs(t)
+	entry, err := InstallFont(src, "", false)
+	if err != nil {
+		t.Fatalf("InstallFont error: %v", err)
+	}
+
+	slant, _ := Generate("Hi", "slant")
+	if art, _ := Generate("Hi", "brand-logo"); art != slant {
+		t.Fatal("installed font should render like slant")
+	}
+	hits := CacheStats().Hits
+	Generate("Hi", "brand-logo")
+	if CacheStats().Hits != hits+1 {
+		t.Error("rendering an unchanged user font again should hit the cache")
+	}
+
+	data, _ := fontsFS.ReadFile("fonts/doom.flf")
+	if err := os.WriteFile(entry.File, data, 0644); err != nil {
+		t.Fatalf("rewrite font: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(entry.File, later, later)
+
+	doom, _ := Generate("Hi", "doom")
+	if art, _ := Generate("Hi", "brand-logo"); art != doom {
+		t.Error("an edited user font should be re-parsed")
+	}
+}
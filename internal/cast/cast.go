@@ -0,0 +1,200 @@
+package cast
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// Version is the asciicast format version written and read
+const Version = 2
+
+// Event types
+const (
+	EventOutput = "o" // data written to the terminal
+	EventInput  = "i" // data typed by the user
+	EventResize = "r" // the terminal was resized to data, as "COLSxROWS"
+	EventMarker = "m" // a named point in the recording
+)
+
+// Header is the first line of an asciicast v2 file
+type Header struct {
+	Version       int               `json:"version"`
+	Width         int               `json:"width"`
+	Height        int               `json:"height"`
+	Timestamp     int64             `json:"timestamp,omitempty"`       // Unix time the recording started
+	IdleTimeLimit float64           `json:"idle_time_limit,omitempty"` // Longest pause on playback, in seconds
+	Command       string            `json:"command,omitempty"`
+	Title         string            `json:"title,omitempty"`
+	Env           map[string]string `json:"env,omitempty"`
+}
+
+// Event is one line after the header: what happened, and when in seconds
+// since the recording started. It is stored as a [time, type, data] array.
+type Event struct {
+	Time float64
+	Type string
+	Data string
+}
+
+// MarshalJSON encodes an event as [time, type, data], with the time to the
+// microsecond
+func (e Event) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode([]any{math.Round(e.Time*1e6) / 1e6, e.Type, e.Data}); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// UnmarshalJSON decodes an event from [time, type, data]
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) != 3 {
+		return fmt.Errorf("event has %d fields, want 3", len(fields))
+	}
+	if err := json.Unmarshal(fields[0], &e.Time); err != nil {
+		return fmt.Errorf("bad event time: %w", err)
+	}
+	if err := json.Unmarshal(fields[1], &e.Type); err != nil {
+		return fmt.Errorf("bad event type: %w", err)
+	}
+	if err := json.Unmarshal(fields[2], &e.Data); err != nil {
+		return fmt.Errorf("bad event data: %w", err)
+	}
+	return nil
+}
+
+// Writer writes an asciicast v2 stream, one JSON line per event
+type Writer struct {
+	enc *json.Encoder
+}
+
+// NewWriter writes the header and returns a writer for the events
+func NewWriter(w io.Writer, h Header) (*Writer, error) {
+	h.Version = Version
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(h); err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+	return &Writer{enc: enc}, nil
+}
+
+// WriteEvent writes one event
+func (w *Writer) WriteEvent(e Event) error {
+	if err := w.enc.Encode(e); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return nil
+}
+
+// Cast is a whole recording
+type Cast struct {
+	Header Header
+	Events []Event
+}
+
+// Read reads an asciicast v2 stream
+func Read(r io.Reader) (*Cast, error) {
+	br := bufio.NewReader(r)
+	var c Cast
+	seenHeader := false
+	for lineNo := 1; ; lineNo++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read cast: %w", err)
+		}
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if !seenHeader {
+				if jerr := json.Unmarshal(line, &c.Header); jerr != nil {
+					return nil, fmt.Errorf("bad header: %w", jerr)
+				}
+				if c.Header.Version != Version {
+					return nil, fmt.Errorf("asciicast version %d is not supported, only %d", c.Header.Version, Version)
+				}
+				seenHeader = true
+			} else {
+				var e Event
+				if jerr := json.Unmarshal(line, &e); jerr != nil {
+					return nil, fmt.Errorf("line %d: %w", lineNo, jerr)
+				}
+				c.Events = append(c.Events, e)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if !seenHeader {
+		return nil, fmt.Errorf("cast is empty")
+	}
+	return &c, nil
+}
+
+// Load reads an asciicast v2 file
+func Load(path string) (*Cast, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cast: %w", err)
+	}
+	defer f.Close()
+
+	c, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// PlayOptions controls playback speed
+type PlayOptions struct {
+	Speed     float64       // Playback speed multiplier (0 = 1)
+	IdleLimit time.Duration // Longest pause between events (0 = the header's idle_time_limit, if any)
+}
+
+// Play writes the output events of a recording to w at the pace they were
+// recorded, until they run out or ctx is done
+func Play(ctx context.Context, w io.Writer, c *Cast, opts PlayOptions) {
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	limit := opts.IdleLimit
+	if limit <= 0 && c.Header.IdleTimeLimit > 0 {
+		limit = time.Duration(c.Header.IdleTimeLimit * float64(time.Second))
+	}
+
+	prev := 0.0
+	for _, e := range c.Events {
+		if e.Type != EventOutput {
+			continue
+		}
+		wait := time.Duration((e.Time - prev) * float64(time.Second))
+		if limit > 0 {
+			wait = min(wait, limit)
+		}
+		prev = e.Time
+
+		select {
+		case <-ctx.Done():
+			// Leave the terminal usable if playback stopped mid-way
+			fmt.Fprint(w, "\033[?1049l\033[0m\033[?25h\n")
+			return
+		case <-time.After(time.Duration(float64(wait) / speed)):
+		}
+		io.WriteString(w, e.Data)
+	}
+}
@@ -0,0 +1,115 @@
+package cast
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRead(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Header{Width: 80, Height: 24, Env: map[string]string{"TERM": "xterm"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []Event{
+		{Time: 0.25, Type: EventOutput, Data: "\033[31m<hi>\033[0m\r\n"},
+		{Time: 1.0000004, Type: EventResize, Data: "100x30"},
+	}
+	for _, e := range events {
+		if err := w.WriteEvent(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want a header and 2 events:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], `{"version":2,"width":80,"height":24,`) {
+		t.Errorf("header = %s", lines[0])
+	}
+	if want := `[0.25,"o","\u001b[31m<hi>\u001b[0m\r\n"]`; lines[1] != want {
+		t.Errorf("event = %s, want %s", lines[1], want)
+	}
+	if want := `[1,"r","100x30"]`; lines[2] != want {
+		t.Errorf("event = %s, want %s (times are rounded to microseconds)", lines[2], want)
+	}
+
+	c, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Header.Version != Version || c.Header.Width != 80 || c.Header.Env["TERM"] != "xterm" {
+		t.Errorf("Header = %+v", c.Header)
+	}
+	if len(c.Events) != 2 || c.Events[0] != events[0] || c.Events[1].Data != "100x30" {
+		t.Errorf("Events = %+v, want %+v", c.Events, events)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name, cast string
+	}{
+		{"empty", ""},
+		{"version 1", `{"version":1,"width":80,"height":24}`},
+		{"bad event", "{\"version\":2}\n[0.5,\"o\"]\n"},
+		{"not json", "{\"version\":2}\nhello\n"},
+	}
+	for _, tt := range tests {
+		if _, err := Read(strings.NewReader(tt.cast)); err == nil {
+			t.Errorf("%s: Read should fail", tt.name)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.cast")); err == nil {
+		t.Error("Load should fail for a missing file")
+	}
+}
+
+func TestPlay(t *testing.T) {
+	c := &Cast{
+		Header: Header{Version: Version, IdleTimeLimit: 0.01},
+		Events: []Event{
+			{Time: 0, Type: EventOutput, Data: "a"},
+			{Time: 0.1, Type: EventResize, Data: "10x10"},
+			{Time: 60, Type: EventOutput, Data: "b"},
+		},
+	}
+
+	// The header's idle limit cuts the minute's pause short
+	var buf bytes.Buffer
+	start := time.Now()
+	Play(context.Background(), &buf, c, PlayOptions{})
+	if buf.String() != "ab" {
+		t.Errorf("Play wrote %q, want only the output events", buf.String())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Play took %v despite the idle limit", elapsed)
+	}
+
+	// Speed divides the recorded pauses
+	c.Header.IdleTimeLimit = 0
+	c.Events[2].Time = 2
+	buf.Reset()
+	start = time.Now()
+	Play(context.Background(), &buf, c, PlayOptions{Speed: 100})
+	if elapsed := time.Since(start); elapsed > time.Second || buf.String() != "ab" {
+		t.Errorf("Play at 100x took %v and wrote %q", elapsed, buf.String())
+	}
+
+	// A cancelled context stops playback and resets the terminal
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	buf.Reset()
+	Play(ctx, &buf, c, PlayOptions{})
+	if strings.Contains(buf.String(), "b") || !strings.Contains(buf.String(), "\033[?25h") {
+		t.Errorf("cancelled Play wrote %q", buf.String())
+	}
+}
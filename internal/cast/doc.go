@@ -0,0 +1,17 @@
+// Package cast records and replays terminal output in the asciicast v2 format used by asciinema.
+//
+// A Recorder swaps os.Stdout for a pseudo-terminal (or a pipe where there is none) and writes
+// everything printed to it, timestamped, to a .cast file while still passing it through to the
+// terminal, so animations and full-screen programs are recorded as they play. Load reads a
+// recording back and Play replays its output at the recorded pace, optionally faster or with long
+// pauses cut short.
+//
+// Example usage:
+//
+//	r, err := cast.Record("out.cast", "demo")
+//	fmt.Println("Hello")
+//	err = r.Stop()
+//
+//	c, err := cast.Load("out.cast")
+//	cast.Play(ctx, os.Stdout, c, cast.PlayOptions{Speed: 2})
+package cast
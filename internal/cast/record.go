@@ -0,0 +1,175 @@
+package cast
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/creack/pty"
+	"golang.org/x/term"
+)
+
+// Recorder records everything written to os.Stdout to an asciicast file,
+// while still passing it through to the terminal.
+//
+// When stdout is a terminal and the platform has pseudo-terminals,
+// os.Stdout is swapped for one the same size as the real terminal, so
+// terminal detection, colors and full-screen programs behave as they would
+// unrecorded. Otherwise it is swapped for a pipe.
+type Recorder struct {
+	file   *os.File
+	w      *Writer
+	stdout *os.File // the real stdout, restored by Stop
+	out    *os.File // os.Stdout while recording
+	in     *os.File // where what is written to out comes back
+	isPTY  bool
+	start  time.Time
+
+	mu         sync.Mutex // guards w and err
+	err        error
+	done       chan struct{}
+	stopResize func()
+}
+
+// Record starts recording os.Stdout to path, titled title
+func Record(path, title string) (*Recorder, error) {
+	isTerminal := term.IsTerminal(int(os.Stdout.Fd()))
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	start := time.Now()
+	w, err := NewWriter(file, Header{
+		Width:     width,
+		Height:    height,
+		Timestamp: start.Unix(),
+		Command:   strings.Join(os.Args, " "),
+		Title:     title,
+		Env:       map[string]string{"SHELL": os.Getenv("SHELL"), "TERM": os.Getenv("TERM")},
+	})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	r := &Recorder{file: file, w: w, stdout: os.Stdout, start: start, done: make(chan struct{})}
+	if isTerminal {
+		if master, slave, err := pty.Open(); err == nil {
+			pty.Setsize(slave, &pty.Winsize{Cols: uint16(width), Rows: uint16(height)})
+			r.in, r.out, r.isPTY = master, slave, true
+		}
+	}
+	if !r.isPTY {
+		if r.in, r.out, err = os.Pipe(); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to capture output: %w", err)
+		}
+	}
+
+	os.Stdout = r.out
+	go r.copy()
+	if r.isPTY {
+		r.stopResize = notifyResize(r.resize)
+	}
+	return r, nil
+}
+
+// copy passes output through to the real stdout and records it, until the
+// recorded side is closed
+func (r *Recorder) copy() {
+	defer close(r.done)
+	buf := make([]byte, 32*1024)
+	var pending []byte
+	for {
+		n, err := r.in.Read(buf)
+		if n > 0 {
+			r.stdout.Write(buf[:n])
+			data := append(pending, buf[:n]...)
+			// A character split across reads waits for the rest of it
+			whole := completeUTF8(data)
+			r.output(string(data[:whole]))
+			pending = append([]byte(nil), data[whole:]...)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if len(pending) > 0 {
+		r.output(string(pending))
+	}
+}
+
+// output records output written now
+func (r *Recorder) output(data string) {
+	if data == "" {
+		return
+	}
+	if !r.isPTY {
+		// A pseudo-terminal turns newlines into CRLF, as a terminal would
+		// see them; a pipe doesn't
+		data = strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\n", "\r\n")
+	}
+	r.record(Event{Type: EventOutput, Data: data})
+}
+
+// resize matches the pseudo-terminal to the real terminal's new size, and
+// records the change
+func (r *Recorder) resize() {
+	width, height, err := term.GetSize(int(r.stdout.Fd()))
+	if err != nil {
+		return
+	}
+	pty.Setsize(r.out, &pty.Winsize{Cols: uint16(width), Rows: uint16(height)})
+	r.record(Event{Type: EventResize, Data: fmt.Sprintf("%dx%d", width, height)})
+}
+
+func (r *Recorder) record(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e.Time = time.Since(r.start).Seconds()
+	if err := r.w.WriteEvent(e); err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+// Stop restores os.Stdout, waits for the last output to be recorded and
+// closes the file
+func (r *Recorder) Stop() error {
+	if r.stopResize != nil {
+		r.stopResize()
+	}
+	os.Stdout = r.stdout
+	r.out.Close()
+	<-r.done
+	r.in.Close()
+
+	r.mu.Lock()
+	err := r.err
+	r.mu.Unlock()
+	if cerr := r.file.Close(); cerr != nil && err == nil {
+		err = fmt.Errorf("failed to write file: %w", cerr)
+	}
+	return err
+}
+
+// completeUTF8 returns the length of data up to any character cut off at
+// the end
+func completeUTF8(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if utf8.FullRune(data[i:]) {
+				return len(data)
+			}
+			return i
+		}
+	}
+	return len(data)
+}
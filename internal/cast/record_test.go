@@ -0,0 +1,63 @@
+package cast
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.cast")
+	stdout := os.Stdout
+	r, err := Record(path, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Print("hello\nwörld\n")
+	if err := r.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if os.Stdout != stdout {
+		t.Error("Stop should restore os.Stdout")
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Header.Title != "test" || c.Header.Width <= 0 || c.Header.Height <= 0 || c.Header.Timestamp == 0 {
+		t.Errorf("Header = %+v", c.Header)
+	}
+	var out strings.Builder
+	for _, e := range c.Events {
+		if e.Type == EventOutput {
+			out.WriteString(e.Data)
+		}
+	}
+	// Newlines are recorded as a terminal receives them
+	if out.String() != "hello\r\nwörld\r\n" {
+		t.Errorf("recorded %q", out.String())
+	}
+}
+
+func TestCompleteUTF8(t *testing.T) {
+	ö := []byte("ö")
+	tests := []struct {
+		data []byte
+		want int
+	}{
+		{nil, 0},
+		{[]byte("abc"), 3},
+		{append([]byte("ab"), ö...), 4},
+		{append([]byte("ab"), ö[0]), 2},
+		{[]byte("ab\xe2\x94"), 2}, // the first two bytes of │
+		{[]byte("ab\xff"), 3},     // invalid bytes aren't held back
+	}
+	for _, tt := range tests {
+		if got := completeUTF8(tt.data); got != tt.want {
+			t.Errorf("completeUTF8(%q) = %d, want %d", tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package cast
+
+// notifyResize does nothing where there is no resize signal
+func notifyResize(resize func()) func() {
+	return func() {}
+}
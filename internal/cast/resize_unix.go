@@ -0,0 +1,29 @@
+//go:build unix
+
+package cast
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize calls resize whenever the terminal is resized, until the
+// returned function is called. That function returns once any resize in
+// progress has finished.
+func notifyResize(resize func()) func() {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGWINCH)
+	go func() {
+		defer close(done)
+		for range ch {
+			resize()
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(ch)
+		<-done
+	}
+}
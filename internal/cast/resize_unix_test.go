@@ -0,0 +1,36 @@
+//go:build unix
+
+package cast
+
+import (
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNotifyResizeStopWaits(t *testing.T) {
+	started := make(chan struct{}, 1)
+	var finished atomic.Bool
+	stop := notifyResize(func() {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+	})
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGWINCH)
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		stop()
+		t.Fatal("resize was not called on SIGWINCH")
+	}
+
+	stop()
+	if !finished.Load() {
+		t.Error("stop returned while a resize was still running")
+	}
+}
@@ -26,6 +26,9 @@ type Config struct {
 	// Custom font paths
 	FontPaths []string `json:"font_paths" yaml:"font_paths"`
 
+	// Number of parsed fonts kept in memory (0 uses the built-in default)
+	FontCacheSize int `json:"font_cache_size" yaml:"font_cache_size"`
+
 	// Custom cowfile paths
 	CowfilePaths []string `json:"cowfile_paths" yaml:"cowfile_paths"`
 
@@ -40,6 +43,10 @@ type Defaults struct {
 	BannerStyle  string `json:"banner_style" yaml:"banner_style"`
 	BannerBorder string `json:"banner_border" yaml:"banner_border"`
 
+	// Fonts tried by banner --fit when the requested font is too wide,
+	// widest first (empty uses the built-in chain)
+	BannerFitFonts []string `json:"banner_fit_fonts" yaml:"banner_fit_fonts"`
+
 	// Convert defaults
 	ConvertWidth   int    `json:"convert_width" yaml:"convert_width"`
 	ConvertCharset string `json:"convert_charset" yaml:"convert_charset"`
@@ -141,6 +148,16 @@ func ConfigPath() string {
 	return filepath.Join(home, ".config", "moji", "config.yaml")
 }
 
+// FontDir returns the directory user fonts are installed into
+// (next to the config file, e.g. ~/.config/moji/fonts)
+func FontDir() string {
+	configPath := ConfigPath()
+	if configPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(configPath), "fonts")
+}
+
 // LegacyConfigPath returns the legacy config path (~/.mojirc)
 func LegacyConfigPath() string {
 	home, err := os.UserHomeDir()
@@ -69,6 +69,18 @@ func TestConfigPath_WithoutXDGConfigHome(t *testing.T) {
 	}
 }
 
+func TestFontDir(t *testing.T) {
+	original := os.Getenv("XDG_CONFIG_HOME")
+	defer os.Setenv("XDG_CONFIG_HOME", original)
+
+	os.Setenv("XDG_CONFIG_HOME", "/custom/config")
+
+	expected := filepath.Join("/custom/config", "moji", "fonts")
+	if dir := FontDir(); dir != expected {
+		t.Errorf("FontDir() = %q, want %q", dir, expected)
+	}
+}
+
 func TestLegacyConfigPath(t *testing.T) {
 	path := LegacyConfigPath()
 	home, _ := os.UserHomeDir()
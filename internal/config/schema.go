@@ -50,6 +50,13 @@ func GenerateSchema() string {
 						"default":     "none",
 						"enum":        []string{"none", "single", "double", "round", "bold", "stars", "hash"},
 					},
+					"banner_fit_fonts": map[string]interface{}{
+						"type":        "array",
+						"title":       "Banner Fit Fonts",
+						"description": "Fonts tried by banner --fit when the requested font is too wide, widest first (empty uses standard, calvin)",
+						"items":       map[string]interface{}{"type": "string"},
+						"examples":    [][]string{{"standard", "calvin"}},
+					},
 					"convert_width": map[string]interface{}{
 						"type":        "integer",
 						"title":       "Convert Width",
@@ -68,7 +75,7 @@ func GenerateSchema() string {
 					"convert_dither": map[string]interface{}{
 						"type":        "string",
 						"title":       "Convert Dither",
-						"description": "Default dithering algorithm (none, floyd, bayer, etc.)",
+						"description": "Default --dither algorithm for convert and batch (none, floyd, atkinson, bayer, etc.)",
 						"default":     "none",
 						"examples":    []string{"none", "floyd", "bayer"},
 					},
@@ -189,6 +196,13 @@ func GenerateSchema() string {
 				},
 				"examples": [][]string{{"/usr/local/share/fonts", "/home/user/.moji/fonts"}},
 			},
+			"font_cache_size": map[string]interface{}{
+				"type":        "integer",
+				"title":       "Font Cache Size",
+				"description": "Number of parsed fonts kept in memory (0 uses the default of 16)",
+				"default":     0,
+				"minimum":     0,
+			},
 			"cowfile_paths": map[string]interface{}{
 				"type":        "array",
 				"title":       "Cowfile Paths",
@@ -1,30 +1,57 @@
 package convert
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"image"
+	"image/color"
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
 	"io"
-	"math"
-	"net/http"
 	"os"
-	"strings"
 
+	"github.com/ddmoney420/moji/internal/ansi"
+	"github.com/ddmoney420/moji/internal/dither"
+	"github.com/ddmoney420/moji/internal/fetch"
+	"github.com/ddmoney420/moji/internal/halfblock"
+	"github.com/ddmoney420/moji/internal/terminal"
 	_ "golang.org/x/image/bmp"
 	_ "golang.org/x/image/webp"
 )
 
 // Options for ASCII conversion
 type Options struct {
-	Width      int    // Target width in characters (0 = auto)
-	Height     int    // Target height in characters (0 = auto based on width)
-	Charset    string // Characters to use (dark to light)
-	Invert     bool   // Invert brightness
-	EdgeDetect bool   // Use edge detection
-	Color      bool   // Preserve colors (ANSI)
-	Dither     bool   // Apply dithering
+	Width            int                 // Target width in characters (0 = auto)
+	Height           int                 // Target height in characters (0 = auto based on width)
+	Charset          string              // Characters to use (dark to light)
+	Invert           bool                // Invert brightness
+	EdgeDetect       bool                // Draw the edges as lines instead of shading
+	EdgeThreshold    float64             // Edge strength, 0 to 1, below which cells stay blank (0 = DefaultEdgeThreshold, NoEdgeThreshold = none)
+	EdgeStyle        EdgeStyle           // Line glyphs for edges ("" = ascii)
+	Color            bool                // Preserve colors (ANSI)
+	Dither           dither.Algorithm    // Spread quantization error across the charset ramp ("" or none = off)
+	Levels           int                 // Number of charset steps to use (0 = every character)
+	Mode             Mode                // How cells are drawn ("" = charset)
+	Threshold        float64             // Sub-cell brightness threshold (0 = 0.5)
+	BrightnessWeight float64             // Shape mode: weight of brightness against shape, 0 to 1
+	ColorLevel       terminal.ColorLevel // Palette for Color: Color256 or Basic (TrueColor or 0 = 24-bit)
+	ColorDither      dither.Algorithm    // Dithering of colors reduced to a palette ("" or none = nearest)
+	BgColor          bool                // Color the cell background and draw the glyph in a darker shade
+
+	// Preprocessing, applied to the source image before it is sampled
+	Background    color.Color     // Composite transparent pixels over this color (nil = black)
+	Crop          image.Rectangle // Region of the image to keep (empty = all), relative to its top-left
+	Rotate        int             // Clockwise rotation in degrees: 0, 90, 180 or 270
+	FlipH         bool            // Mirror left to right
+	FlipV         bool            // Mirror top to bottom
+	AutoLevels    bool            // Stretch each channel to the full range
+	Brightness    float64         // Added to every channel, -1 to 1
+	Contrast      float64         // -1 (flat gray) to 1 (hard), 0 = unchanged
+	Gamma         float64         // Midtone gamma, above 1 brightens (0 or 1 = unchanged)
+	Sharpen       float64         // Unsharp mask amount (0 = off)
+	SharpenRadius float64         // Unsharp mask blur radius in pixels (0 = 1)
 }
 
 // DefaultOptions returns sensible defaults
@@ -120,19 +147,23 @@ func LoadImageFile(path string) (image.Image, error) {
 	return img, nil
 }
 
+// fetcher downloads the images LoadImageURL and FromURL convert
+var fetcher = fetch.New(fetch.Options{CacheDir: fetch.DefaultCacheDir()})
+
+// SetFetcher replaces the fetcher used for URLs, for instance to turn off
+// the cache or to block private addresses
+func SetFetcher(f *fetch.Fetcher) {
+	fetcher = f
+}
+
 // LoadImageURL loads an image from a URL
 func LoadImageURL(url string) (image.Image, error) {
-	resp, err := http.Get(url)
+	res, err := fetcher.Fetch(context.Background(), url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch image: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch image: HTTP %d", resp.StatusCode)
-	}
-
-	img, _, err := image.Decode(resp.Body)
+	img, _, err := image.Decode(bytes.NewReader(res.Data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode image: %w", err)
 	}
@@ -151,17 +182,12 @@ func FromFile(path string, opts Options) (string, error) {
 
 // FromURL converts an image from URL to ASCII art
 func FromURL(url string, opts Options) (string, error) {
-	resp, err := http.Get(url)
+	res, err := fetcher.Fetch(context.Background(), url)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch image: %w", err)
+		return "", err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch image: HTTP %d", resp.StatusCode)
-	}
-
-	return FromReader(resp.Body, opts)
+	return FromReader(bytes.NewReader(res.Data), opts)
 }
 
 // FromReader converts an image from io.Reader to ASCII art
@@ -177,15 +203,21 @@ func FromReader(r io.Reader, opts Options) (string, error) {
 // FromImage converts an image.Image to ASCII art
 // For large images (above threshold), automatically uses parallel processing
 func FromImage(img image.Image, opts Options) (string, error) {
+	img, err := Preprocess(img, opts)
+	if err != nil {
+		return "", err
+	}
+
 	bounds := img.Bounds()
 	imgWidth := bounds.Max.X - bounds.Min.X
 	imgHeight := bounds.Max.Y - bounds.Min.Y
 
-	// Check if image is large enough to benefit from parallelization
+	// Check if image is large enough to benefit from parallelization.
+	// Matching shapes is slow enough to always use the workers.
 	pixelCount := imgWidth * imgHeight
-	if pixelCount >= parallelConfig.threshold {
+	if pixelCount >= parallelConfig.threshold || opts.Mode == ModeShape {
 		// Use parallel processing for large images
-		return FromImageParallel(img, opts)
+		return fromImageParallel(img, opts)
 	}
 
 	// Sequential processing for small images
@@ -195,43 +227,51 @@ func FromImage(img image.Image, opts Options) (string, error) {
 // fromImageSequential performs the actual sequential pixel-by-pixel conversion
 // This is the core algorithm used by both FromImage and FromImageParallel
 func fromImageSequential(img image.Image, opts Options) (string, error) {
-	if opts.Charset == "" {
-		opts.Charset = CharSets["standard"]
+	opts = opts.withDefaults()
+	if opts.Mode.subCell() {
+		return fromImageCells(img, opts), nil
+	}
+	var shapes *glyphSet
+	if opts.Mode == ModeShape {
+		var err error
+		if shapes, err = loadGlyphSet(opts.Charset); err != nil {
+			return "", err
+		}
+	} else if opts.dithered() && !opts.EdgeDetect {
+		return fromImageDithered(img, opts)
 	}
 
 	bounds := img.Bounds()
 	imgWidth := bounds.Max.X - bounds.Min.X
 	imgHeight := bounds.Max.Y - bounds.Min.Y
-
-	// Calculate target dimensions
-	targetWidth := opts.Width
-	if targetWidth <= 0 {
-		targetWidth = 80
-	}
-
-	// Characters are roughly 2x taller than wide, so adjust
-	aspectRatio := float64(imgWidth) / float64(imgHeight)
-	targetHeight := opts.Height
-	if targetHeight <= 0 {
-		targetHeight = int(float64(targetWidth) / aspectRatio / 2.0)
-	}
+	targetWidth, targetHeight := targetSize(img, opts)
 
 	// Calculate sampling step
 	stepX := float64(imgWidth) / float64(targetWidth)
 	stepY := float64(imgHeight) / float64(targetHeight)
 
-	// Precompute grayscale if edge detection
-	var edges [][]float64
+	// Edge detection draws lines instead of the charset
+	var edges [][]rune
 	if opts.EdgeDetect {
-		edges = detectEdges(img, targetWidth, targetHeight, stepX, stepY)
+		edges = detectEdges(img, targetWidth, targetHeight, stepX, stepY).glyphs(opts.EdgeThreshold, opts.EdgeStyle)
 	}
 
-	var result strings.Builder
+	var palette [][][3]uint8
+	if opts.paletted() {
+		palette = quantizeColors(sampleColors(img, targetWidth, targetHeight, stepX, stepY), opts)
+	}
+
+	result := ansi.NewWriter(opts.ColorLevel)
 	chars := []rune(opts.Charset)
 	numChars := len(chars)
 
 	for y := 0; y < targetHeight; y++ {
 		for x := 0; x < targetWidth; x++ {
+			if edges != nil {
+				result.WriteRune(edges[y][x])
+				continue
+			}
+
 			// Sample the image region
 			sampleX := int(float64(x)*stepX) + bounds.Min.X
 			sampleY := int(float64(y)*stepY) + bounds.Min.Y
@@ -244,15 +284,8 @@ func fromImageSequential(img image.Image, opts Options) (string, error) {
 				sampleY = bounds.Max.Y - 1
 			}
 
-			var brightness float64
-			var r8, g8, b8 uint8
-
-			if opts.EdgeDetect && edges != nil {
-				brightness = edges[y][x]
-			} else {
-				// Get average color of the region
-				r8, g8, b8, brightness = sampleRegion(img, sampleX, sampleY, int(stepX), int(stepY))
-			}
+			// Get average color of the region
+			r8, g8, b8, brightness := sampleRegion(img, sampleX, sampleY, int(stepX), int(stepY))
 
 			if opts.Invert {
 				brightness = 1.0 - brightness
@@ -268,20 +301,84 @@ func fromImageSequential(img image.Image, opts Options) (string, error) {
 			}
 
 			char := chars[charIdx]
+			if shapes != nil {
+				char = shapes.match(sampleCell(img, float64(x)*stepX+float64(bounds.Min.X), float64(y)*stepY+float64(bounds.Min.Y), stepX, stepY, opts.Invert), opts.BrightnessWeight)
+			}
 
-			if opts.Color && !opts.EdgeDetect {
-				// ANSI 24-bit color
-				result.WriteString(fmt.Sprintf("\x1b[38;2;%d;%d;%dm%c\x1b[0m", r8, g8, b8, char))
-			} else {
-				result.WriteRune(char)
+			if opts.Color {
+				if palette != nil {
+					c := palette[y][x]
+					r8, g8, b8 = c[0], c[1], c[2]
+				}
+				setCellColor(result, opts, r8, g8, b8)
 			}
+			result.WriteRune(char)
 		}
-		result.WriteString("\n")
+		result.WriteRune('\n')
 	}
 
 	return result.String(), nil
 }
 
+// targetSize returns the output size in characters. Characters are roughly
+// twice as tall as they are wide, so the automatic height is halved.
+func targetSize(img image.Image, opts Options) (int, int) {
+	bounds := img.Bounds()
+	imgWidth := bounds.Max.X - bounds.Min.X
+	imgHeight := bounds.Max.Y - bounds.Min.Y
+
+	targetWidth := opts.Width
+	if targetWidth <= 0 {
+		targetWidth = 80
+	}
+
+	aspectRatio := float64(imgWidth) / float64(imgHeight)
+	targetHeight := opts.Height
+	if targetHeight <= 0 {
+		targetHeight = int(float64(targetWidth) / aspectRatio / 2.0)
+	}
+	return targetWidth, targetHeight
+}
+
+// withDefaults fills in the charset, mode and edge threshold. Shape mode
+// defaults to all of printable ASCII, since a short ramp gives it few shapes
+// to choose from.
+func (o Options) withDefaults() Options {
+	if o.Mode == "" {
+		o.Mode = ModeCharset
+	}
+	if o.Charset == "" {
+		o.Charset = CharSets["standard"]
+		if o.Mode == ModeShape {
+			o.Charset = ShapeCharset
+		}
+	}
+	if o.EdgeThreshold == 0 {
+		o.EdgeThreshold = DefaultEdgeThreshold
+	} else if o.EdgeThreshold < 0 {
+		o.EdgeThreshold = 0
+	}
+	return o
+}
+
+// fromImageCells draws the image with sub-cell glyphs at the output size.
+// Edge detection and the charset don't apply to sub-cell modes.
+func fromImageCells(img image.Image, opts Options) string {
+	width, height := targetSize(img, opts)
+	return halfblock.RenderCells(img, halfblock.CellOptions{
+		Mode:        halfblock.Mode(opts.Mode),
+		Width:       width,
+		Height:      height,
+		Color:       opts.Color,
+		Invert:      opts.Invert,
+		Threshold:   opts.Threshold,
+		Dither:      opts.Dither,
+		ColorLevel:  opts.ColorLevel,
+		ColorDither: opts.ColorDither,
+		BgColor:     opts.BgColor,
+	})
+}
+
 // sampleRegion samples a region of the image and returns RGB and brightness
 func sampleRegion(img image.Image, x, y, width, height int) (uint8, uint8, uint8, float64) {
 	bounds := img.Bounds()
@@ -329,58 +426,6 @@ func sampleRegion(img image.Image, x, y, width, height int) (uint8, uint8, uint8
 	return uint8(avgR), uint8(avgG), uint8(avgB), brightness
 }
 
-// detectEdges applies Sobel edge detection
-func detectEdges(img image.Image, width, height int, stepX, stepY float64) [][]float64 {
-	bounds := img.Bounds()
-
-	// First, create grayscale version at target resolution
-	gray := make([][]float64, height)
-	for y := 0; y < height; y++ {
-		gray[y] = make([]float64, width)
-		for x := 0; x < width; x++ {
-			sampleX := int(float64(x)*stepX) + bounds.Min.X
-			sampleY := int(float64(y)*stepY) + bounds.Min.Y
-
-			if sampleX >= bounds.Max.X {
-				sampleX = bounds.Max.X - 1
-			}
-			if sampleY >= bounds.Max.Y {
-				sampleY = bounds.Max.Y - 1
-			}
-
-			_, _, _, brightness := sampleRegion(img, sampleX, sampleY, int(stepX), int(stepY))
-			gray[y][x] = brightness
-		}
-	}
-
-	// Apply Sobel operator
-	edges := make([][]float64, height)
-	for y := 0; y < height; y++ {
-		edges[y] = make([]float64, width)
-		for x := 0; x < width; x++ {
-			if x == 0 || x == width-1 || y == 0 || y == height-1 {
-				edges[y][x] = 0
-				continue
-			}
-
-			// Sobel kernels
-			gx := -gray[y-1][x-1] + gray[y-1][x+1] +
-				-2*gray[y][x-1] + 2*gray[y][x+1] +
-				-gray[y+1][x-1] + gray[y+1][x+1]
-
-			gy := -gray[y-1][x-1] - 2*gray[y-1][x] - gray[y-1][x+1] +
-				gray[y+1][x-1] + 2*gray[y+1][x] + gray[y+1][x+1]
-
-			magnitude := math.Sqrt(gx*gx + gy*gy)
-
-			// Normalize and invert (edges should be dark characters)
-			edges[y][x] = 1.0 - math.Min(magnitude*2, 1.0)
-		}
-	}
-
-	return edges
-}
-
 // GetCharset returns a charset by name
 func GetCharset(name string) string {
 	if cs, ok := CharSets[name]; ok {
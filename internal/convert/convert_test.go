@@ -1,10 +1,18 @@
 package convert
 
 import (
+	"bytes"
 	"image"
 	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/ddmoney420/moji/internal/dither"
+	"github.com/ddmoney420/moji/internal/fetch"
+	"github.com/ddmoney420/moji/internal/terminal"
 )
 
 // createTestImage creates a simple test image
@@ -198,6 +206,26 @@ func TestFromImageColor(t *testing.T) {
 	}
 }
 
+func TestFromImageColorRunLength(t *testing.T) {
+	img := createTestImage(100, 50, color.RGBA{255, 0, 0, 255})
+	result, err := FromImage(img, Options{Width: 20, Height: 4, Color: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One escape per line for a flat color, not one per character
+	if n := strings.Count(result, "\x1b[38;2;"); n != 4 {
+		t.Errorf("got %d color escapes, want 4: %q", n, result)
+	}
+
+	bg, err := FromImage(img, Options{Width: 20, Height: 1, Color: true, BgColor: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(bg, "\x1b[38;2;127;0;0;48;2;255;0;0m") {
+		t.Errorf("BgColor cell = %q, want a darker red glyph on red", bg)
+	}
+}
+
 func TestFromImageEdgeDetection(t *testing.T) {
 	// Create an image with a clear edge (left half black, right half white)
 	img := image.NewRGBA(image.Rect(0, 0, 100, 50))
@@ -622,6 +650,57 @@ func TestFromImageAutoParallel(t *testing.T) {
 	}
 }
 
+// TestFromImageMode verifies sub-cell modes render the same sequentially and in parallel
+func TestFromImageMode(t *testing.T) {
+	img := createTestImage(100, 100, color.White)
+	opts := Options{Width: 10, Height: 5, Mode: ModeBraille}
+
+	sequential, err := FromImage(img, opts)
+	if err != nil {
+		t.Fatalf("FromImage error: %v", err)
+	}
+	if strings.Trim(sequential, "⣿\n") != "" || strings.Count(sequential, "\n") != 5 {
+		t.Errorf("white image in braille mode = %q, want 5 rows of ⣿", sequential)
+	}
+	parallel, err := FromImageParallel(img, opts)
+	if err != nil || parallel != sequential {
+		t.Errorf("FromImageParallel = %q, %v; want the sequential result", parallel, err)
+	}
+}
+
+// TestFromImagePalette verifies 256 and 16-color output, with and without dithering
+func TestFromImagePalette(t *testing.T) {
+	img := createGradientImage(200, 40)
+	for _, level := range []terminal.ColorLevel{terminal.Color256, terminal.Basic} {
+		opts := Options{Width: 40, Height: 8, Color: true, ColorLevel: level, ColorDither: dither.FloydSteinberg}
+		seq, err := fromImageSequential(img, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		par, err := FromImageParallel(img, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seq != par {
+			t.Errorf("%v: parallel output differs from sequential", level)
+		}
+		if strings.Contains(seq, "38;2;") {
+			t.Errorf("%v: output should have no 24-bit escapes", level)
+		}
+		if level == terminal.Color256 && !strings.Contains(seq, "\x1b[38;5;") {
+			t.Errorf("256-color output has no 256-color escapes")
+		}
+	}
+
+	// Dithering a 16-color gradient mixes black and white cells in the middle
+	opts := Options{Width: 40, Height: 8, Color: true, ColorLevel: terminal.Basic, ColorDither: dither.FloydSteinberg}
+	out, _ := FromImage(img, opts)
+	row := strings.Split(out, "\n")[4]
+	if !strings.Contains(row, "\x1b[30m") || !strings.Contains(row, "\x1b[97m") {
+		t.Errorf("dithered 16-color gradient should mix black and white: %q", row)
+	}
+}
+
 // BenchmarkFromImageSequential benchmarks sequential processing
 func BenchmarkFromImageSequential(b *testing.B) {
 	// Force sequential processing by setting very high threshold
@@ -673,3 +752,33 @@ func BenchmarkFromImageLarge(b *testing.B) {
 		_, _ = FromImage(img, opts)
 	}
 }
+
+func TestFromURL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, createTestImage(20, 10, color.White)); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/page" {
+			w.Write([]byte("<html>not an image</html>"))
+			return
+		}
+		w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	saved := fetcher
+	SetFetcher(fetch.New(fetch.Options{}))
+	defer SetFetcher(saved)
+
+	art, err := FromURL(srv.URL+"/white.png", Options{Width: 10, Height: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if art != "@@@@@@@@@@\n@@@@@@@@@@\n" {
+		t.Errorf("FromURL = %q, want a white block", art)
+	}
+	if _, err := LoadImageURL(srv.URL + "/page"); err == nil {
+		t.Error("LoadImageURL should reject a page that isn't an image")
+	}
+}
@@ -0,0 +1,82 @@
+package convert
+
+import (
+	"image"
+	"math"
+
+	"github.com/ddmoney420/moji/internal/ansi"
+	"github.com/ddmoney420/moji/internal/dither"
+)
+
+// dithered reports whether the charset ramp is quantized with the dither package
+func (o Options) dithered() bool {
+	return (o.Dither != "" && o.Dither != dither.None) || o.Levels > 0
+}
+
+// fromImageDithered samples every cell first and then quantizes the whole
+// grid, so error diffusion can carry between neighbouring cells
+func fromImageDithered(img image.Image, opts Options) (string, error) {
+	bounds := img.Bounds()
+	imgWidth := bounds.Max.X - bounds.Min.X
+	imgHeight := bounds.Max.Y - bounds.Min.Y
+	targetWidth, targetHeight := targetSize(img, opts)
+
+	stepX := float64(imgWidth) / float64(targetWidth)
+	stepY := float64(imgHeight) / float64(targetHeight)
+
+	brightness := make([][]float64, targetHeight)
+	colors := make([][][3]uint8, targetHeight)
+	for y := 0; y < targetHeight; y++ {
+		brightness[y] = make([]float64, targetWidth)
+		colors[y] = make([][3]uint8, targetWidth)
+		for x := 0; x < targetWidth; x++ {
+			sampleX := int(float64(x)*stepX) + bounds.Min.X
+			sampleY := int(float64(y)*stepY) + bounds.Min.Y
+			if sampleX >= bounds.Max.X {
+				sampleX = bounds.Max.X - 1
+			}
+			if sampleY >= bounds.Max.Y {
+				sampleY = bounds.Max.Y - 1
+			}
+
+			r8, g8, b8, b := sampleRegion(img, sampleX, sampleY, int(stepX), int(stepY))
+			colors[y][x] = [3]uint8{r8, g8, b8}
+			if opts.Invert {
+				b = 1.0 - b
+			}
+			brightness[y][x] = b
+		}
+	}
+
+	chars := []rune(opts.Charset)
+	numChars := len(chars)
+	levels := opts.Levels
+	if levels <= 0 || levels > numChars {
+		levels = numChars
+	}
+	if levels < 2 {
+		levels = 2
+	}
+	grid := dither.Quantize(brightness, opts.Dither, levels)
+	if opts.paletted() {
+		colors = quantizeColors(colors, opts)
+	}
+
+	result := ansi.NewWriter(opts.ColorLevel)
+	for y, row := range grid {
+		for x, level := range row {
+			// Spread the levels evenly over the ramp when fewer are used
+			charIdx := int(math.Round(float64(level) * float64(numChars-1) / float64(levels-1)))
+			char := chars[charIdx]
+
+			if opts.Color {
+				c := colors[y][x]
+				setCellColor(result, opts, c[0], c[1], c[2])
+			}
+			result.WriteRune(char)
+		}
+		result.WriteRune('\n')
+	}
+
+	return result.String(), nil
+}
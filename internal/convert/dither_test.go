@@ -0,0 +1,88 @@
+package convert
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/ddmoney420/moji/internal/dither"
+)
+
+func TestFromImageDitherMixesNeighbouringChars(t *testing.T) {
+	// 40% gray falls between the "-" and "=" steps of the standard ramp
+	img := createTestImage(200, 100, color.Gray{Y: 102})
+	opts := Options{Width: 40, Height: 20, Charset: " .:-=+*#%@"}
+
+	plain, err := FromImage(img, opts)
+	if err != nil {
+		t.Fatalf("FromImage error: %v", err)
+	}
+	if strings.Trim(plain, "-\n") != "" {
+		t.Fatalf("undithered gray should map to one character, got %q", plain[:20])
+	}
+
+	opts.Dither = dither.FloydSteinberg
+	dithered, err := FromImage(img, opts)
+	if err != nil {
+		t.Fatalf("FromImage error: %v", err)
+	}
+	if !strings.Contains(dithered, "-") || !strings.Contains(dithered, "=") {
+		t.Errorf("dithered gray should mix - and =, got %q", dithered[:41])
+	}
+	if strings.Trim(dithered, "-=\n") != "" {
+		t.Errorf("dithered gray should only use neighbouring steps")
+	}
+}
+
+func TestFromImageLevels(t *testing.T) {
+	img := createGradientImage(200, 100)
+	opts := Options{Width: 40, Height: 10, Charset: " .:-=+*#%@", Levels: 2, Dither: dither.Bayer4x4}
+
+	result, err := FromImage(img, opts)
+	if err != nil {
+		t.Fatalf("FromImage error: %v", err)
+	}
+	if strings.Trim(result, " @\n") != "" {
+		t.Errorf("2 levels should use only the ends of the ramp, got %q", result)
+	}
+	if !strings.Contains(result, " ") || !strings.Contains(result, "@") {
+		t.Error("gradient should use both ends of the ramp")
+	}
+}
+
+func TestFromImageDitherParallelMatchesSequential(t *testing.T) {
+	img := createGradientImage(1000, 1000)
+	opts := Options{Width: 80, Height: 40, Dither: dither.Atkinson, Color: true}
+
+	parallelResult, err := FromImageParallel(img, opts)
+	if err != nil {
+		t.Fatalf("FromImageParallel error: %v", err)
+	}
+	sequentialResult, err := fromImageSequential(img, opts)
+	if err != nil {
+		t.Fatalf("fromImageSequential error: %v", err)
+	}
+	if parallelResult != sequentialResult {
+		t.Error("dithered parallel result does not match sequential result")
+	}
+	if lines := strings.Count(parallelResult, "\n"); lines != 40 {
+		t.Errorf("got %d lines, want 40", lines)
+	}
+}
+
+func TestFromImageDitherEdgeDetect(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 50; x < 100; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	result, err := FromImage(img, Options{Width: 20, Height: 10, EdgeDetect: true, Dither: dither.FloydSteinberg})
+	if err != nil {
+		t.Fatalf("FromImage error: %v", err)
+	}
+	if strings.Count(result, "\n") != 10 {
+		t.Errorf("edge-detected dithered output has wrong height: %q", result)
+	}
+}
@@ -1,8 +1,17 @@
 // Package convert provides image-to-ASCII conversion with multiple charsets and advanced features.
 //
 // It converts images to ASCII art with support for various character sets, dithering algorithms,
-// edge detection, color preservation, and parallel processing. The package handles files, URLs,
-// and in-memory image data.
+// edge detection (Sobel edges thinned to one cell and drawn as lines by direction), color
+// preservation (24-bit, or the 256 and 16 color palettes with optional color dithering, in the
+// foreground or the cell background), and parallel processing. Colored output goes through
+// ansi.Writer, so escapes are only written when the color changes. Besides the brightness ramp,
+// cells can be drawn by matching glyph shapes (ModeShape) or with sub-cell block and braille
+// patterns.
+//
+// Source images can be preprocessed first (Preprocess): cropped, rotated and flipped, composited
+// over a background color, and adjusted with auto-levels, brightness, contrast, gamma and an
+// unsharp mask. The package handles files, URLs, and in-memory image data, and decodes every frame
+// of animated GIFs (DecodeGIF) so each can be converted with the same options.
 //
 // Example usage:
 //
@@ -0,0 +1,260 @@
+package convert
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+	"sync"
+)
+
+// EdgeStyle selects the glyphs edge detection draws lines with
+type EdgeStyle string
+
+const (
+	EdgeASCII EdgeStyle = "ascii" // | / - \ and _
+	EdgeBox   EdgeStyle = "box"   // │ ╱ ─ ╲ and _
+)
+
+// DefaultEdgeThreshold is the gradient strength, 0 to 1, below which a cell
+// has no edge
+const DefaultEdgeThreshold = 0.2
+
+// NoEdgeThreshold is an EdgeThreshold that draws every edge however faint,
+// since a threshold of 0 means DefaultEdgeThreshold
+const NoEdgeThreshold = -1
+
+// Line directions, named by the line drawn rather than the gradient, which
+// runs across it
+const (
+	lineVertical = iota
+	lineRising   // bottom-left to top-right
+	lineHorizontal
+	lineFalling // top-left to bottom-right
+	lineLow     // horizontal, in the lower part of the cell
+)
+
+// edgeGlyphs holds each style's glyph for every line direction
+var edgeGlyphs = map[EdgeStyle][5]rune{
+	EdgeASCII: {'|', '/', '-', '\\', '_'},
+	EdgeBox:   {'│', '╱', '─', '╲', '_'},
+}
+
+// gradientSteps are the cell offsets along the gradient of each direction
+var gradientSteps = [4]image.Point{{1, 0}, {1, 1}, {0, 1}, {-1, 1}}
+
+// ListEdgeStyles returns the edge style names
+func ListEdgeStyles() []string {
+	return []string{string(EdgeASCII), string(EdgeBox)}
+}
+
+// ParseEdgeStyle parses an edge style name
+func ParseEdgeStyle(name string) (EdgeStyle, error) {
+	switch strings.ToLower(name) {
+	case "", string(EdgeASCII):
+		return EdgeASCII, nil
+	case string(EdgeBox), "unicode":
+		return EdgeBox, nil
+	}
+	return "", fmt.Errorf("unknown edge style %q (use %s)", name, strings.Join(ListEdgeStyles(), ", "))
+}
+
+// edgeMap holds the Sobel gradient of every output cell
+type edgeMap struct {
+	width, height int
+	aspect        float64     // cell height over width, in image pixels
+	magnitude     [][]float64 // 0 to 1
+	direction     [][]uint8   // line drawn across the gradient, as seen in the image
+	normal        [][]uint8   // gradient direction on the cell grid
+}
+
+func newEdgeMap(width, height int, stepX, stepY float64) *edgeMap {
+	m := &edgeMap{
+		width:     width,
+		height:    height,
+		aspect:    stepY / stepX,
+		magnitude: make([][]float64, height),
+		direction: make([][]uint8, height),
+		normal:    make([][]uint8, height),
+	}
+	for y := 0; y < height; y++ {
+		m.magnitude[y] = make([]float64, width)
+		m.direction[y] = make([]uint8, width)
+		m.normal[y] = make([]uint8, width)
+	}
+	return m
+}
+
+// detectEdges applies Sobel edge detection at the output resolution
+func detectEdges(img image.Image, width, height int, stepX, stepY float64) *edgeMap {
+	gray := edgeGray(img, width, height, stepX, stepY)
+	m := newEdgeMap(width, height, stepX, stepY)
+	for y := 0; y < height; y++ {
+		m.sobelRow(gray, y)
+	}
+	return m
+}
+
+// detectEdgesParallel applies Sobel edge detection with parallel processing
+func detectEdgesParallel(img image.Image, width, height int, stepX, stepY float64) *edgeMap {
+	gray := edgeGray(img, width, height, stepX, stepY)
+	m := newEdgeMap(width, height, stepX, stepY)
+
+	// Determine optimal worker count for edge detection
+	workers := parallelConfig.workerCount
+	if workers > height {
+		workers = height
+	}
+
+	rowQueue := make(chan int, workers)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := range rowQueue {
+				m.sobelRow(gray, y)
+			}
+		}()
+	}
+
+	go func() {
+		for y := 0; y < height; y++ {
+			rowQueue <- y
+		}
+		close(rowQueue)
+	}()
+
+	wg.Wait()
+
+	return m
+}
+
+// edgeGray samples the brightness of every output cell
+func edgeGray(img image.Image, width, height int, stepX, stepY float64) [][]float64 {
+	bounds := img.Bounds()
+	gray := make([][]float64, height)
+	for y := 0; y < height; y++ {
+		gray[y] = make([]float64, width)
+		for x := 0; x < width; x++ {
+			sampleX := int(float64(x)*stepX) + bounds.Min.X
+			sampleY := int(float64(y)*stepY) + bounds.Min.Y
+
+			if sampleX >= bounds.Max.X {
+				sampleX = bounds.Max.X - 1
+			}
+			if sampleY >= bounds.Max.Y {
+				sampleY = bounds.Max.Y - 1
+			}
+
+			_, _, _, brightness := sampleRegion(img, sampleX, sampleY, int(stepX), int(stepY))
+			gray[y][x] = brightness
+		}
+	}
+	return gray
+}
+
+// sobelRow computes the gradient of one row. Pixels past the border repeat
+// the edge, so flat areas touching it have no gradient.
+func (m *edgeMap) sobelRow(gray [][]float64, y int) {
+	at := func(x, y int) float64 {
+		return gray[min(max(y, 0), m.height-1)][min(max(x, 0), m.width-1)]
+	}
+
+	for x := 0; x < m.width; x++ {
+		// Sobel kernels
+		gx := -at(x-1, y-1) + at(x+1, y-1) +
+			-2*at(x-1, y) + 2*at(x+1, y) +
+			-at(x-1, y+1) + at(x+1, y+1)
+
+		gy := -at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1) +
+			at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1)
+
+		// A full black to white step gives 4
+		m.magnitude[y][x] = math.Min(math.Sqrt(gx*gx+gy*gy)/4, 1)
+
+		// Cells are taller than they are wide, so the line's angle in the
+		// image picks the glyph, while the angle on the cell grid picks the
+		// neighbours it is thinned against. Diagonal glyphs are steep, so
+		// they cover lines 30 to 75 degrees from horizontal.
+		m.direction[y][x] = binAngle(gradientAngle(gx*m.aspect, gy), 15)
+		m.normal[y][x] = binAngle(gradientAngle(gx, gy), 22.5)
+	}
+}
+
+// gradientAngle returns the direction of a gradient folded onto 0-180
+// degrees
+func gradientAngle(gx, gy float64) float64 {
+	angle := math.Atan2(gy, gx) * 180 / math.Pi
+	if angle < 0 {
+		angle += 180
+	}
+	return angle
+}
+
+// binAngle maps a gradient angle to the line it crosses. Vertical lines
+// take the angles within tolerance of 0 degrees, horizontal ones those
+// within 45 - tolerance of 90. Y grows downwards, so a gradient towards the
+// bottom right crosses a rising line.
+func binAngle(angle, tolerance float64) uint8 {
+	switch {
+	case angle < tolerance || angle >= 180-tolerance:
+		return lineVertical
+	case angle < 45+tolerance:
+		return lineRising
+	case angle < 135-tolerance:
+		return lineHorizontal
+	default:
+		return lineFalling
+	}
+}
+
+// at returns the gradient magnitude of a cell, 0 outside the map
+func (m *edgeMap) at(x, y int) float64 {
+	if x < 0 || y < 0 || x >= m.width || y >= m.height {
+		return 0
+	}
+	return m.magnitude[y][x]
+}
+
+// glyphs draws the edges as lines. Cells below the threshold, or that
+// aren't the strongest along their gradient (non-maximum suppression),
+// are blank, which thins edges to one cell.
+func (m *edgeMap) glyphs(threshold float64, style EdgeStyle) [][]rune {
+	set, ok := edgeGlyphs[style]
+	if !ok {
+		set = edgeGlyphs[EdgeASCII]
+	}
+
+	out := make([][]rune, m.height)
+	for y := 0; y < m.height; y++ {
+		out[y] = make([]rune, m.width)
+		for x := 0; x < m.width; x++ {
+			out[y][x] = ' '
+			mag := m.magnitude[y][x]
+			if mag < threshold || mag == 0 {
+				continue
+			}
+
+			dir := m.direction[y][x]
+			step := gradientSteps[m.normal[y][x]]
+			before := m.at(x-step.X, y-step.Y)
+			after := m.at(x+step.X, y+step.Y)
+			// Of two equal neighbours, the first keeps the edge
+			if mag <= before || mag < after {
+				continue
+			}
+
+			// A horizontal edge whose peak lies towards the row below sits
+			// low in the cell
+			if dir == lineHorizontal {
+				if curve := before - 2*mag + after; curve < 0 && 0.5*(before-after)/curve > 0.25 {
+					dir = lineLow
+				}
+			}
+			out[y][x] = set[dir]
+		}
+	}
+	return out
+}
@@ -0,0 +1,139 @@
+package convert
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// edgeImage draws a white region, where inside reports true, on black
+func edgeImage(w, h int, inside func(x, y int) bool) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if inside(x, y) {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return img
+}
+
+func TestEdgeLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		inside func(x, y int) bool
+		want   string // the only glyphs that may appear
+	}{
+		{"vertical", func(x, y int) bool { return x >= 40 }, "|"},
+		{"horizontal", func(x, y int) bool { return y >= 40 }, "-_"},
+		{"rising", func(x, y int) bool { return x+y >= 80 }, "/"},
+		{"falling", func(x, y int) bool { return x >= y }, "\\"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			art, err := FromImage(edgeImage(80, 80, tt.inside), Options{Width: 20, Height: 10, EdgeDetect: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Trim(art, " \n"+tt.want) != "" {
+				t.Errorf("edge drawn with other glyphs than %q:\n%s", tt.want, art)
+			}
+			if !strings.ContainsAny(art, tt.want) {
+				t.Errorf("no %q edge found:\n%s", tt.want, art)
+			}
+		})
+	}
+}
+
+func TestEdgeThinning(t *testing.T) {
+	art, err := FromImage(edgeImage(80, 80, func(x, y int) bool { return x >= 40 }), Options{Width: 20, Height: 10, EdgeDetect: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Non-maximum suppression leaves a line one cell wide on every row
+	for i, line := range strings.Split(strings.TrimSuffix(art, "\n"), "\n") {
+		if n := strings.Count(line, "|"); n != 1 {
+			t.Errorf("row %d has %d edge cells, want 1: %q", i, n, line)
+		}
+	}
+
+	// A horizontal step between two rows sits low in the upper one
+	art, _ = FromImage(edgeImage(80, 80, func(x, y int) bool { return y >= 40 }), Options{Width: 20, Height: 10, EdgeDetect: true})
+	if lines := strings.Split(art, "\n"); strings.Trim(lines[4], "_") != "" {
+		t.Errorf("row above the step = %q, want underscores", lines[4])
+	}
+}
+
+func TestEdgeThreshold(t *testing.T) {
+	// A faint step, 20 levels apart
+	img := image.NewGray(image.Rect(0, 0, 80, 80))
+	for y := 0; y < 80; y++ {
+		for x := 0; x < 80; x++ {
+			v := uint8(100)
+			if x >= 40 {
+				v = 120
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+
+	art, _ := FromImage(img, Options{Width: 20, Height: 10, EdgeDetect: true})
+	if strings.TrimSpace(art) != "" {
+		t.Errorf("faint edge drawn at the default threshold:\n%s", art)
+	}
+	art, _ = FromImage(img, Options{Width: 20, Height: 10, EdgeDetect: true, EdgeThreshold: 0.05})
+	if !strings.Contains(art, "|") {
+		t.Errorf("faint edge missing at threshold 0.05:\n%s", art)
+	}
+	art, _ = FromImage(img, Options{Width: 20, Height: 10, EdgeDetect: true, EdgeThreshold: NoEdgeThreshold})
+	if !strings.Contains(art, "|") {
+		t.Errorf("faint edge missing with NoEdgeThreshold:\n%s", art)
+	}
+}
+
+func TestEdgeStyleBox(t *testing.T) {
+	art, err := FromImage(edgeImage(80, 80, func(x, y int) bool { return x >= 40 }), Options{Width: 20, Height: 10, EdgeDetect: true, EdgeStyle: EdgeBox})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(art, "│") || strings.Contains(art, "|") {
+		t.Errorf("box style should draw with │:\n%s", art)
+	}
+
+	if _, err := ParseEdgeStyle("wavy"); err == nil {
+		t.Error("ParseEdgeStyle should reject unknown styles")
+	}
+	if s, err := ParseEdgeStyle("Unicode"); err != nil || s != EdgeBox {
+		t.Errorf("ParseEdgeStyle(Unicode) = %q, %v; want box", s, err)
+	}
+}
+
+func TestEdgeParallelMatchesSequential(t *testing.T) {
+	saved := parallelConfig.workerCount
+	SetWorkerCount(2)
+	defer SetWorkerCount(saved)
+
+	img := edgeImage(200, 200, func(x, y int) bool {
+		dx, dy := x-100, y-100
+		return dx*dx+dy*dy < 60*60
+	})
+	opts := Options{Width: 60, Height: 40, EdgeDetect: true}
+	seq, err := fromImageSequential(img, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	par, err := FromImageParallel(img, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq != par {
+		t.Errorf("parallel edges differ from sequential:\n%s\n%s", seq, par)
+	}
+	for _, glyph := range "|/-\\" {
+		if !strings.ContainsRune(seq, glyph) {
+			t.Errorf("circle outline has no %q:\n%s", glyph, seq)
+		}
+	}
+}
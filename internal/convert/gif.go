@@ -0,0 +1,110 @@
+package convert
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"os"
+	"time"
+)
+
+// defaultFrameDelay is used for GIF frames with no delay, as browsers do
+const defaultFrameDelay = 100 * time.Millisecond
+
+// Frame is one fully composited frame of an animated image
+type Frame struct {
+	Image image.Image
+	Delay time.Duration
+}
+
+// Animation is a decoded animated image
+type Animation struct {
+	Frames    []Frame
+	LoopCount int // 0 = loop forever, -1 = play once, n = repeat n more times
+}
+
+// LoadAnimationFile loads every frame of an animated GIF. Other formats
+// load as a single frame.
+func LoadAnimationFile(path string) (*Animation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+	defer file.Close()
+
+	anim, err := DecodeGIF(file)
+	if err == nil {
+		return anim, nil
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return &Animation{Frames: []Frame{{Image: img}}, LoopCount: -1}, nil
+}
+
+// DecodeGIF decodes every frame of a GIF, drawing each one over the
+// canvas left by the previous frame's disposal method
+func DecodeGIF(r io.Reader) (*Animation, error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode GIF: %w", err)
+	}
+
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() && len(g.Image) > 0 {
+		bounds = g.Image[0].Bounds()
+	}
+	canvas := image.NewRGBA(bounds)
+
+	anim := &Animation{LoopCount: g.LoopCount}
+	for i, frame := range g.Image {
+		var previous *image.RGBA
+		disposal := byte(gif.DisposalNone)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(bounds)
+			draw.Draw(previous, bounds, canvas, bounds.Min, draw.Src)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		snapshot := image.NewRGBA(bounds)
+		draw.Draw(snapshot, bounds, canvas, bounds.Min, draw.Src)
+
+		delay := defaultFrameDelay
+		if i < len(g.Delay) && g.Delay[i] > 1 {
+			delay = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		}
+		anim.Frames = append(anim.Frames, Frame{Image: snapshot, Delay: delay})
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return anim, nil
+}
+
+// FromAnimation converts each frame of an animation with the same options
+func FromAnimation(anim *Animation, opts Options) ([]string, error) {
+	frames := make([]string, len(anim.Frames))
+	for i, frame := range anim.Frames {
+		art, err := FromImage(frame.Image, opts)
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i+1, err)
+		}
+		frames[i] = art
+	}
+	return frames, nil
+}
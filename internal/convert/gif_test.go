@@ -0,0 +1,129 @@
+package convert
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testGIF builds a 4x4 animation: a white background, then a black pixel at
+// (0,0) with the given disposal, then a black pixel at (3,3)
+func testGIF(t *testing.T, disposal byte) []byte {
+	t.Helper()
+	palette := color.Palette{color.White, color.Black, color.Transparent}
+
+	background := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+	first := image.NewPaletted(image.Rect(0, 0, 1, 1), palette)
+	first.SetColorIndex(0, 0, 1)
+	second := image.NewPaletted(image.Rect(3, 3, 4, 4), palette)
+	second.SetColorIndex(3, 3, 1)
+
+	g := &gif.GIF{
+		Image:    []*image.Paletted{background, first, second},
+		Delay:    []int{5, 0, 20},
+		Disposal: []byte{gif.DisposalNone, disposal, gif.DisposalNone},
+		Config:   image.Config{ColorModel: palette, Width: 4, Height: 4},
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func isBlack(img image.Image, x, y int) bool {
+	r, g, b, a := img.At(x, y).RGBA()
+	return r == 0 && g == 0 && b == 0 && a == 0xffff
+}
+
+func TestDecodeGIFDisposal(t *testing.T) {
+	tests := []struct {
+		name     string
+		disposal byte
+		kept     bool // whether frame 2's pixel is still drawn in frame 3
+		cleared  bool // whether frame 2's area is transparent in frame 3
+	}{
+		{"none", gif.DisposalNone, true, false},
+		{"background", gif.DisposalBackground, false, true},
+		{"previous", gif.DisposalPrevious, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anim, err := DecodeGIF(bytes.NewReader(testGIF(t, tt.disposal)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(anim.Frames) != 3 {
+				t.Fatalf("got %d frames, want 3", len(anim.Frames))
+			}
+
+			second, third := anim.Frames[1].Image, anim.Frames[2].Image
+			if !isBlack(second, 0, 0) {
+				t.Error("frame 2 should draw its pixel")
+			}
+			if !isBlack(third, 3, 3) {
+				t.Error("frame 3 should draw its pixel")
+			}
+			if got := isBlack(third, 0, 0); got != tt.kept {
+				t.Errorf("frame 2's pixel in frame 3 = %v, want %v", got, tt.kept)
+			}
+			if _, _, _, a := third.At(0, 0).RGBA(); (a == 0) != tt.cleared {
+				t.Errorf("frame 2's area cleared = %v, want %v", a == 0, tt.cleared)
+			}
+			if _, _, _, a := third.At(2, 2).RGBA(); a != 0xffff {
+				t.Error("the background should stay in place")
+			}
+		})
+	}
+}
+
+func TestDecodeGIFDelays(t *testing.T) {
+	anim, err := DecodeGIF(bytes.NewReader(testGIF(t, gif.DisposalNone)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Duration{50 * time.Millisecond, defaultFrameDelay, 200 * time.Millisecond}
+	for i, frame := range anim.Frames {
+		if frame.Delay != want[i] {
+			t.Errorf("frame %d delay = %v, want %v", i+1, frame.Delay, want[i])
+		}
+	}
+}
+
+func TestLoadAnimationFile(t *testing.T) {
+	dir := t.TempDir()
+	gifPath := filepath.Join(dir, "anim.gif")
+	if err := os.WriteFile(gifPath, testGIF(t, gif.DisposalBackground), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	anim, err := LoadAnimationFile(gifPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames, err := FromAnimation(anim, Options{Width: 4, Height: 2, Charset: " #"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("got %d frames, want 3", len(frames))
+	}
+	if frames[0] == frames[1] {
+		t.Error("frames 1 and 2 should convert differently")
+	}
+	for i, frame := range frames {
+		if lines := strings.Split(strings.TrimRight(frame, "\n"), "\n"); len(lines) != 2 {
+			t.Errorf("frame %d has %d lines, want 2", i+1, len(lines))
+		}
+	}
+
+	if _, err := LoadAnimationFile(filepath.Join(dir, "missing.gif")); err == nil {
+		t.Error("LoadAnimationFile should fail for a missing file")
+	}
+}
@@ -0,0 +1,75 @@
+package convert
+
+import (
+	"image"
+
+	"github.com/ddmoney420/moji/internal/ansi"
+	"github.com/ddmoney420/moji/internal/dither"
+	"github.com/ddmoney420/moji/internal/terminal"
+)
+
+// paletted reports whether colors are reduced to the 256 or 16 color palette
+func (o Options) paletted() bool {
+	return o.Color && !o.EdgeDetect && o.ColorLevel.PaletteStep() > 0
+}
+
+// sampleColors samples the average color of every cell
+func sampleColors(img image.Image, width, height int, stepX, stepY float64) [][][3]uint8 {
+	bounds := img.Bounds()
+	colors := make([][][3]uint8, height)
+	for y := 0; y < height; y++ {
+		colors[y] = make([][3]uint8, width)
+		for x := 0; x < width; x++ {
+			sampleX := int(float64(x)*stepX) + bounds.Min.X
+			sampleY := int(float64(y)*stepY) + bounds.Min.Y
+			if sampleX >= bounds.Max.X {
+				sampleX = bounds.Max.X - 1
+			}
+			if sampleY >= bounds.Max.Y {
+				sampleY = bounds.Max.Y - 1
+			}
+			r8, g8, b8, _ := sampleRegion(img, sampleX, sampleY, int(stepX), int(stepY))
+			colors[y][x] = [3]uint8{r8, g8, b8}
+		}
+	}
+	return colors
+}
+
+// quantizeColors reduces cell colors to the palette of opts.ColorLevel,
+// dithering them with opts.ColorDither so gradients don't band
+func quantizeColors(colors [][][3]uint8, opts Options) [][][3]uint8 {
+	pixels := make([][][3]float64, len(colors))
+	for y, row := range colors {
+		pixels[y] = make([][3]float64, len(row))
+		for x, c := range row {
+			pixels[y][x] = [3]float64{float64(c[0]), float64(c[1]), float64(c[2])}
+		}
+	}
+
+	nearest := func(c [3]float64) [3]float64 {
+		r, g, b := terminal.NearestColor(opts.ColorLevel, uint8(c[0]+0.5), uint8(c[1]+0.5), uint8(c[2]+0.5))
+		return [3]float64{float64(r), float64(g), float64(b)}
+	}
+	quantized := dither.QuantizeColors(pixels, opts.ColorDither, opts.ColorLevel.PaletteStep(), nearest)
+
+	result := make([][][3]uint8, len(quantized))
+	for y, row := range quantized {
+		result[y] = make([][3]uint8, len(row))
+		for x, c := range row {
+			result[y][x] = [3]uint8{uint8(c[0]), uint8(c[1]), uint8(c[2])}
+		}
+	}
+	return result
+}
+
+// setCellColor sets the colors of the next cell: the foreground, or with
+// BgColor the background, with the glyph at half the brightness so it
+// still shows the cell's detail
+func setCellColor(w *ansi.Writer, opts Options, r, g, b uint8) {
+	if opts.BgColor {
+		w.SetBg(r, g, b)
+		w.SetFg(r/2, g/2, b/2)
+		return
+	}
+	w.SetFg(r, g, b)
+}
@@ -1,12 +1,12 @@
 package convert
 
 import (
-	"fmt"
 	"image"
-	"math"
 	"runtime"
 	"strings"
 	"sync"
+
+	"github.com/ddmoney420/moji/internal/ansi"
 )
 
 // ParallelConfig holds configuration for parallel processing
@@ -47,25 +47,34 @@ type rowResult struct {
 // FromImageParallel converts an image to ASCII art using parallel processing
 // It splits the image into horizontal bands and processes each band in a separate worker
 func FromImageParallel(img image.Image, opts Options) (string, error) {
-	if opts.Charset == "" {
-		opts.Charset = CharSets["standard"]
+	img, err := Preprocess(img, opts)
+	if err != nil {
+		return "", err
+	}
+	return fromImageParallel(img, opts)
+}
+
+// fromImageParallel converts an image that has already been preprocessed
+func fromImageParallel(img image.Image, opts Options) (string, error) {
+	opts = opts.withDefaults()
+	if opts.Mode.subCell() {
+		return fromImageCells(img, opts), nil
+	}
+	var shapes *glyphSet
+	if opts.Mode == ModeShape {
+		var err error
+		if shapes, err = loadGlyphSet(opts.Charset); err != nil {
+			return "", err
+		}
+	} else if opts.dithered() && !opts.EdgeDetect {
+		// Error diffusion carries from cell to cell, so it can't be split into bands
+		return fromImageDithered(img, opts)
 	}
 
 	bounds := img.Bounds()
 	imgWidth := bounds.Max.X - bounds.Min.X
 	imgHeight := bounds.Max.Y - bounds.Min.Y
-
-	// Calculate target dimensions
-	targetWidth := opts.Width
-	if targetWidth <= 0 {
-		targetWidth = 80
-	}
-
-	aspectRatio := float64(imgWidth) / float64(imgHeight)
-	targetHeight := opts.Height
-	if targetHeight <= 0 {
-		targetHeight = int(float64(targetWidth) / aspectRatio / 2.0)
-	}
+	targetWidth, targetHeight := targetSize(img, opts)
 
 	// Determine optimal worker count
 	workers := parallelConfig.workerCount
@@ -86,10 +95,17 @@ func FromImageParallel(img image.Image, opts Options) (string, error) {
 	stepX := float64(imgWidth) / float64(targetWidth)
 	stepY := float64(imgHeight) / float64(targetHeight)
 
-	// Precompute edges if needed
-	var edges [][]float64
+	// Edges are thinned against their neighbours, so the whole grid is
+	// drawn before the rows are split up
+	var edges [][]rune
 	if opts.EdgeDetect {
-		edges = detectEdges(img, targetWidth, targetHeight, stepX, stepY)
+		edges = detectEdgesParallel(img, targetWidth, targetHeight, stepX, stepY).glyphs(opts.EdgeThreshold, opts.EdgeStyle)
+	}
+
+	var palette [][][3]uint8
+	if opts.paletted() {
+		// Colors are dithered across the whole grid before the rows are split up
+		palette = quantizeColors(sampleColors(img, targetWidth, targetHeight, stepX, stepY), opts)
 	}
 
 	// Create result channel with buffer to avoid blocking workers
@@ -103,7 +119,7 @@ func FromImageParallel(img image.Image, opts Options) (string, error) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			processRowWorker(img, opts, bounds, targetWidth, targetHeight, stepX, stepY, edges, rowQueue, resultsChan)
+			processRowWorker(img, opts, bounds, targetWidth, targetHeight, stepX, stepY, edges, shapes, palette, rowQueue, resultsChan)
 		}()
 	}
 
@@ -149,14 +165,19 @@ func FromImageParallel(img image.Image, opts Options) (string, error) {
 }
 
 // processRowWorker processes rows from the queue
-func processRowWorker(img image.Image, opts Options, bounds image.Rectangle, targetWidth, targetHeight int, stepX, stepY float64, edges [][]float64, rowQueue chan int, results chan rowResult) {
+func processRowWorker(img image.Image, opts Options, bounds image.Rectangle, targetWidth, targetHeight int, stepX, stepY float64, edges [][]rune, shapes *glyphSet, palette [][][3]uint8, rowQueue chan int, results chan rowResult) {
 	chars := []rune(opts.Charset)
 	numChars := len(chars)
 
 	for y := range rowQueue {
-		var rowContent strings.Builder
+		rowContent := ansi.NewWriter(opts.ColorLevel)
 
 		for x := 0; x < targetWidth; x++ {
+			if edges != nil {
+				rowContent.WriteRune(edges[y][x])
+				continue
+			}
+
 			// Sample the image region
 			sampleX := int(float64(x)*stepX) + bounds.Min.X
 			sampleY := int(float64(y)*stepY) + bounds.Min.Y
@@ -169,14 +190,7 @@ func processRowWorker(img image.Image, opts Options, bounds image.Rectangle, tar
 				sampleY = bounds.Max.Y - 1
 			}
 
-			var brightness float64
-			var r8, g8, b8 uint8
-
-			if opts.EdgeDetect && edges != nil {
-				brightness = edges[y][x]
-			} else {
-				r8, g8, b8, brightness = sampleRegion(img, sampleX, sampleY, int(stepX), int(stepY))
-			}
+			r8, g8, b8, brightness := sampleRegion(img, sampleX, sampleY, int(stepX), int(stepY))
 
 			if opts.Invert {
 				brightness = 1.0 - brightness
@@ -192,13 +206,18 @@ func processRowWorker(img image.Image, opts Options, bounds image.Rectangle, tar
 			}
 
 			char := chars[charIdx]
+			if shapes != nil {
+				char = shapes.match(sampleCell(img, float64(x)*stepX+float64(bounds.Min.X), float64(y)*stepY+float64(bounds.Min.Y), stepX, stepY, opts.Invert), opts.BrightnessWeight)
+			}
 
-			if opts.Color && !opts.EdgeDetect {
-				// ANSI 24-bit color
-				rowContent.WriteString(fmt.Sprintf("\x1b[38;2;%d;%d;%dm%c\x1b[0m", r8, g8, b8, char))
-			} else {
-				rowContent.WriteRune(char)
+			if opts.Color {
+				if palette != nil {
+					c := palette[y][x]
+					r8, g8, b8 = c[0], c[1], c[2]
+				}
+				setCellColor(rowContent, opts, r8, g8, b8)
 			}
+			rowContent.WriteRune(char)
 		}
 
 		results <- rowResult{
@@ -208,79 +227,3 @@ func processRowWorker(img image.Image, opts Options, bounds image.Rectangle, tar
 		}
 	}
 }
-
-// detectEdgesParallel applies Sobel edge detection with parallel processing
-func detectEdgesParallel(img image.Image, width, height int, stepX, stepY float64) [][]float64 {
-	bounds := img.Bounds()
-
-	// First, create grayscale version at target resolution (sequential)
-	gray := make([][]float64, height)
-	for y := 0; y < height; y++ {
-		gray[y] = make([]float64, width)
-		for x := 0; x < width; x++ {
-			sampleX := int(float64(x)*stepX) + bounds.Min.X
-			sampleY := int(float64(y)*stepY) + bounds.Min.Y
-
-			if sampleX >= bounds.Max.X {
-				sampleX = bounds.Max.X - 1
-			}
-			if sampleY >= bounds.Max.Y {
-				sampleY = bounds.Max.Y - 1
-			}
-
-			_, _, _, brightness := sampleRegion(img, sampleX, sampleY, int(stepX), int(stepY))
-			gray[y][x] = brightness
-		}
-	}
-
-	// Apply Sobel operator in parallel
-	edges := make([][]float64, height)
-	for y := 0; y < height; y++ {
-		edges[y] = make([]float64, width)
-	}
-
-	// Determine optimal worker count for edge detection
-	workers := parallelConfig.workerCount
-	if workers > height {
-		workers = height
-	}
-
-	rowQueue := make(chan int, workers)
-	var wg sync.WaitGroup
-
-	for i := 0; i < workers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for y := range rowQueue {
-				for x := 0; x < width; x++ {
-					if x == 0 || x == width-1 || y == 0 || y == height-1 {
-						edges[y][x] = 0
-						continue
-					}
-
-					gx := -gray[y-1][x-1] + gray[y-1][x+1] +
-						-2*gray[y][x-1] + 2*gray[y][x+1] +
-						-gray[y+1][x-1] + gray[y+1][x+1]
-
-					gy := -gray[y-1][x-1] - 2*gray[y-1][x] - gray[y-1][x+1] +
-						gray[y+1][x-1] + 2*gray[y+1][x] + gray[y+1][x+1]
-
-					magnitude := math.Sqrt(gx*gx + gy*gy)
-					edges[y][x] = 1.0 - math.Min(magnitude*2, 1.0)
-				}
-			}
-		}()
-	}
-
-	go func() {
-		for y := 0; y < height; y++ {
-			rowQueue <- y
-		}
-		close(rowQueue)
-	}()
-
-	wg.Wait()
-
-	return edges
-}
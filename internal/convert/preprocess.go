@@ -0,0 +1,230 @@
+package convert
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ddmoney420/moji/internal/dither"
+)
+
+// preprocessing reports whether any preprocessing stage is set
+func (o Options) preprocessing() bool {
+	return o.Background != nil || !o.Crop.Empty() || o.Rotate%360 != 0 || o.FlipH || o.FlipV ||
+		o.AutoLevels || o.Brightness != 0 || o.Contrast != 0 || (o.Gamma > 0 && o.Gamma != 1) || o.Sharpen > 0
+}
+
+// Preprocess applies the preprocessing stages of opts to an image, in
+// order: crop, alpha compositing, rotate and flip, auto-levels, brightness,
+// contrast and gamma, then the unsharp mask. The image is returned as is
+// when no stage is set.
+func Preprocess(img image.Image, opts Options) (image.Image, error) {
+	if !opts.preprocessing() {
+		return img, nil
+	}
+
+	bounds := img.Bounds()
+	region := bounds
+	if !opts.Crop.Empty() {
+		region = opts.Crop.Add(bounds.Min).Intersect(bounds)
+		if region.Empty() {
+			return nil, fmt.Errorf("crop %d,%d,%d,%d is outside the %dx%d image",
+				opts.Crop.Min.X, opts.Crop.Min.Y, opts.Crop.Dx(), opts.Crop.Dy(), bounds.Dx(), bounds.Dy())
+		}
+	}
+
+	rotate := (opts.Rotate%360 + 360) % 360
+	if rotate%90 != 0 {
+		return nil, fmt.Errorf("rotation must be a multiple of 90 degrees, got %d", opts.Rotate)
+	}
+
+	// Transparent pixels have always come out black, so that stays the default
+	background := opts.Background
+	if background == nil {
+		background = color.Black
+	}
+	out := image.NewRGBA(image.Rect(0, 0, region.Dx(), region.Dy()))
+	draw.Draw(out, out.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(out, out.Bounds(), img, region.Min, draw.Over)
+
+	out = transform(out, rotate, opts.FlipH, opts.FlipV)
+	if opts.AutoLevels {
+		autoLevels(out)
+	}
+	adjustTone(out, opts.Brightness, opts.Contrast, opts.Gamma)
+	if opts.Sharpen > 0 {
+		out = unsharpMask(out, opts.SharpenRadius, opts.Sharpen)
+	}
+	return out, nil
+}
+
+// ParseCrop parses a crop rectangle given as x,y,width,height in pixels
+func ParseCrop(s string) (image.Rectangle, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return image.Rectangle{}, fmt.Errorf("invalid crop %q (use x,y,width,height)", s)
+	}
+	var v [4]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 0 {
+			return image.Rectangle{}, fmt.Errorf("invalid crop %q (use x,y,width,height)", s)
+		}
+		v[i] = n
+	}
+	if v[2] == 0 || v[3] == 0 {
+		return image.Rectangle{}, fmt.Errorf("invalid crop %q: width and height must be positive", s)
+	}
+	return image.Rect(v[0], v[1], v[0]+v[2], v[1]+v[3]), nil
+}
+
+// transform rotates an image clockwise by 0, 90, 180 or 270 degrees, then
+// flips it
+func transform(img *image.RGBA, rotate int, flipH, flipV bool) *image.RGBA {
+	if rotate == 0 && !flipH && !flipV {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	outW, outH := w, h
+	if rotate == 90 || rotate == 270 {
+		outW, outH = h, w
+	}
+	out := image.NewRGBA(image.Rect(0, 0, outW, outH))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			nx, ny := x, y
+			switch rotate {
+			case 90:
+				nx, ny = h-1-y, x
+			case 180:
+				nx, ny = w-1-x, h-1-y
+			case 270:
+				nx, ny = y, w-1-x
+			}
+			if flipH {
+				nx = outW - 1 - nx
+			}
+			if flipV {
+				ny = outH - 1 - ny
+			}
+			out.SetRGBA(nx, ny, img.RGBAAt(x, y))
+		}
+	}
+	return out
+}
+
+// autoLevels stretches each channel to the full range with
+// dither.ContrastStretch
+func autoLevels(img *image.RGBA) {
+	bounds := img.Bounds()
+	channel := image.NewGray(bounds)
+	for c := 0; c < 3; c++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				channel.Pix[channel.PixOffset(x, y)] = img.Pix[img.PixOffset(x, y)+c]
+			}
+		}
+		stretched := dither.ContrastStretch(channel, 0, 255)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				img.Pix[img.PixOffset(x, y)+c] = stretched.GrayAt(x, y).Y
+			}
+		}
+	}
+}
+
+// adjustTone applies brightness, contrast and gamma through a lookup table
+func adjustTone(img *image.RGBA, brightness, contrast, gamma float64) {
+	if gamma <= 0 {
+		gamma = 1
+	}
+	if brightness == 0 && contrast == 0 && gamma == 1 {
+		return
+	}
+
+	// Contrast maps -1..1 onto a slope of 0..infinity around mid gray
+	contrast = math.Max(-1, math.Min(0.99, contrast))
+	slope := math.Tan((contrast + 1) * math.Pi / 4)
+
+	var table [256]uint8
+	for i := range table {
+		v := float64(i)/255 + brightness
+		v = (v-0.5)*slope + 0.5
+		v = math.Pow(math.Max(0, math.Min(1, v)), 1/gamma)
+		table[i] = uint8(math.Round(v * 255))
+	}
+
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i] = table[img.Pix[i]]
+		img.Pix[i+1] = table[img.Pix[i+1]]
+		img.Pix[i+2] = table[img.Pix[i+2]]
+	}
+}
+
+// unsharpMask sharpens an image by adding amount times the difference
+// between it and a Gaussian blur of the given radius
+func unsharpMask(img *image.RGBA, radius, amount float64) *image.RGBA {
+	if radius <= 0 {
+		radius = 1
+	}
+	blurred := gaussianBlur(img, radius)
+
+	out := image.NewRGBA(img.Bounds())
+	for i := 0; i < len(img.Pix); i += 4 {
+		for c := 0; c < 3; c++ {
+			v := float64(img.Pix[i+c])
+			v += amount * (v - float64(blurred.Pix[i+c]))
+			out.Pix[i+c] = uint8(math.Max(0, math.Min(255, math.Round(v))))
+		}
+		out.Pix[i+3] = img.Pix[i+3]
+	}
+	return out
+}
+
+// gaussianBlur blurs an image with a separable Gaussian kernel, where
+// radius is the standard deviation
+func gaussianBlur(img *image.RGBA, radius float64) *image.RGBA {
+	size := int(math.Ceil(radius * 3))
+	kernel := make([]float64, 2*size+1)
+	total := 0.0
+	for i := range kernel {
+		d := float64(i - size)
+		kernel[i] = math.Exp(-d * d / (2 * radius * radius))
+		total += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= total
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	pass := func(src *image.RGBA, dx, dy int) *image.RGBA {
+		dst := image.NewRGBA(bounds)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				var sum [3]float64
+				for k, weight := range kernel {
+					// Clamp to the edge
+					sx := min(max(x+(k-size)*dx, 0), w-1)
+					sy := min(max(y+(k-size)*dy, 0), h-1)
+					i := src.PixOffset(bounds.Min.X+sx, bounds.Min.Y+sy)
+					for c := range sum {
+						sum[c] += weight * float64(src.Pix[i+c])
+					}
+				}
+				i := dst.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)
+				for c := range sum {
+					dst.Pix[i+c] = uint8(math.Round(sum[c]))
+				}
+				dst.Pix[i+3] = 255
+			}
+		}
+		return dst
+	}
+	return pass(pass(img, 1, 0), 0, 1)
+}
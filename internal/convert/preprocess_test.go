@@ -0,0 +1,173 @@
+package convert
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func grayAt(img image.Image, x, y int) uint8 {
+	r, _, _, _ := img.At(x, y).RGBA()
+	return uint8(r >> 8)
+}
+
+func TestPreprocessUnchanged(t *testing.T) {
+	img := createGradientImage(10, 10)
+	out, err := Preprocess(img, Options{Gamma: 1, Width: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != image.Image(img) {
+		t.Error("Preprocess should return the image as is when no stage is set")
+	}
+}
+
+func TestPreprocessCropRotateFlip(t *testing.T) {
+	// A 4x2 image whose pixel values encode their position
+	img := image.NewGray(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8(10*y + x)})
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want [][]uint8
+	}{
+		{"crop", Options{Crop: image.Rect(1, 0, 3, 2)}, [][]uint8{{1, 2}, {11, 12}}},
+		{"rotate 90", Options{Rotate: 90}, [][]uint8{{10, 0}, {11, 1}, {12, 2}, {13, 3}}},
+		{"rotate 180", Options{Rotate: 180}, [][]uint8{{13, 12, 11, 10}, {3, 2, 1, 0}}},
+		{"rotate -90", Options{Rotate: -90}, [][]uint8{{3, 13}, {2, 12}, {1, 11}, {0, 10}}},
+		{"flip h", Options{FlipH: true}, [][]uint8{{3, 2, 1, 0}, {13, 12, 11, 10}}},
+		{"flip v", Options{FlipV: true}, [][]uint8{{10, 11, 12, 13}, {0, 1, 2, 3}}},
+		{"crop then rotate", Options{Crop: image.Rect(2, 0, 4, 2), Rotate: 270}, [][]uint8{{3, 13}, {2, 12}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Preprocess(img, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h, w := out.Bounds().Dy(), out.Bounds().Dx(); h != len(tt.want) || w != len(tt.want[0]) {
+				t.Fatalf("size = %dx%d, want %dx%d", w, h, len(tt.want[0]), len(tt.want))
+			}
+			for y, row := range tt.want {
+				for x, want := range row {
+					if got := grayAt(out, x, y); got != want {
+						t.Errorf("pixel (%d,%d) = %d, want %d", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+
+	if _, err := Preprocess(img, Options{Rotate: 45}); err == nil {
+		t.Error("Preprocess should reject rotations that aren't multiples of 90")
+	}
+	if _, err := Preprocess(img, Options{Crop: image.Rect(10, 10, 20, 20)}); err == nil {
+		t.Error("Preprocess should reject a crop outside the image")
+	}
+}
+
+func TestPreprocessBackground(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(1, 0, color.NRGBA{0, 0, 0, 255})
+
+	out, err := Preprocess(img, Options{Background: color.White})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := grayAt(out, 0, 0); got != 255 {
+		t.Errorf("transparent pixel = %d, want the white background", got)
+	}
+	if got := grayAt(out, 1, 0); got != 0 {
+		t.Errorf("opaque pixel = %d, want it left black", got)
+	}
+
+	// Transparent areas become spaces on a white background with --invert
+	art, err := FromImage(image.NewNRGBA(image.Rect(0, 0, 8, 8)), Options{Width: 4, Height: 2, Background: color.White, Invert: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(art) != "" {
+		t.Errorf("transparent image over white = %q, want blank", art)
+	}
+}
+
+func TestPreprocessTone(t *testing.T) {
+	img := createTestImage(2, 2, color.Gray{Y: 100})
+
+	tests := []struct {
+		name string
+		opts Options
+		want func(v uint8) bool
+	}{
+		{"brightness", Options{Brightness: 0.2}, func(v uint8) bool { return v > 140 && v < 160 }},
+		{"darken", Options{Brightness: -0.5}, func(v uint8) bool { return v == 0 }},
+		{"contrast", Options{Contrast: 0.5}, func(v uint8) bool { return v < 100 }},
+		{"flat", Options{Contrast: -1}, func(v uint8) bool { return v == 128 }},
+		{"gamma", Options{Gamma: 2}, func(v uint8) bool { return v > 150 }},
+	}
+	for _, tt := range tests {
+		out, err := Preprocess(img, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := grayAt(out, 0, 0); !tt.want(v) {
+			t.Errorf("%s: pixel = %d", tt.name, v)
+		}
+	}
+}
+
+func TestPreprocessAutoLevels(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 3, 1))
+	img.SetGray(0, 0, color.Gray{Y: 100})
+	img.SetGray(1, 0, color.Gray{Y: 125})
+	img.SetGray(2, 0, color.Gray{Y: 150})
+
+	out, err := Preprocess(img, Options{AutoLevels: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lo, mid, hi := grayAt(out, 0, 0), grayAt(out, 1, 0), grayAt(out, 2, 0); lo != 0 || hi != 255 || mid < 120 || mid > 135 {
+		t.Errorf("auto-levels = %d, %d, %d; want 0, ~128, 255", lo, mid, hi)
+	}
+}
+
+func TestPreprocessSharpen(t *testing.T) {
+	// A step edge gains overshoot on both sides when sharpened
+	img := image.NewGray(image.Rect(0, 0, 8, 1))
+	for x := 0; x < 8; x++ {
+		v := uint8(64)
+		if x >= 4 {
+			v = 192
+		}
+		img.SetGray(x, 0, color.Gray{Y: v})
+	}
+
+	out, err := Preprocess(img, Options{Sharpen: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dark, light := grayAt(out, 3, 0), grayAt(out, 4, 0); dark >= 64 || light <= 192 {
+		t.Errorf("edge pixels = %d, %d; want below 64 and above 192", dark, light)
+	}
+	if far := grayAt(out, 0, 0); far < 60 || far > 68 {
+		t.Errorf("flat area = %d, want about 64", far)
+	}
+}
+
+func TestParseCrop(t *testing.T) {
+	rect, err := ParseCrop("10, 20,30,40")
+	if err != nil || rect != image.Rect(10, 20, 40, 60) {
+		t.Errorf("ParseCrop = %v, %v; want (10,20)-(40,60)", rect, err)
+	}
+	for _, bad := range []string{"", "1,2,3", "a,b,c,d", "0,0,0,10", "-1,0,5,5"} {
+		if _, err := ParseCrop(bad); err == nil {
+			t.Errorf("ParseCrop(%q) should fail", bad)
+		}
+	}
+}
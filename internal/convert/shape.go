@@ -0,0 +1,237 @@
+package convert
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/ddmoney420/moji/internal/halfblock"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Mode selects how each character cell is drawn
+type Mode string
+
+const (
+	ModeCharset   Mode = "charset"                     // Average brightness mapped onto the charset ramp
+	ModeShape     Mode = "shape"                       // Charset glyph whose shape best matches the cell
+	ModeHalfBlock      = Mode(halfblock.ModeHalfBlock) // 1x2 pixels per cell
+	ModeQuadrant       = Mode(halfblock.ModeQuadrant)  // 2x2 pixels per cell
+	ModeSextant        = Mode(halfblock.ModeSextant)   // 2x3 pixels per cell
+	ModeBraille        = Mode(halfblock.ModeBraille)   // 2x4 dots per cell
+)
+
+// ListModes returns the conversion mode names
+func ListModes() []string {
+	return append([]string{string(ModeCharset), string(ModeShape)}, halfblock.Modes()...)
+}
+
+// ParseMode parses a conversion mode name
+func ParseMode(name string) (Mode, error) {
+	switch m := Mode(strings.ToLower(name)); m {
+	case "", ModeCharset, "ramp":
+		return ModeCharset, nil
+	case ModeShape, "structure":
+		return ModeShape, nil
+	}
+	sub, err := halfblock.ParseMode(name)
+	if err != nil {
+		return "", fmt.Errorf("unknown mode %q (use %s)", name, strings.Join(ListModes(), ", "))
+	}
+	return Mode(sub), nil
+}
+
+// subCell reports whether the mode is drawn by halfblock.RenderCells
+func (m Mode) subCell() bool {
+	switch m {
+	case ModeHalfBlock, ModeQuadrant, ModeSextant, ModeBraille:
+		return true
+	}
+	return false
+}
+
+// ShapeCharset is the default charset for shape mode: printable ASCII
+const ShapeCharset = " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+
+// Shape cells are compared at this many samples per character cell, which
+// matches the roughly 1:2 aspect ratio of a terminal cell
+const (
+	shapeCols = 6
+	shapeRows = 12
+)
+
+// SSIM stabilising constants for values in the range 0 to 1
+const (
+	ssimC1 = 0.01 * 0.01
+	ssimC2 = 0.03 * 0.03
+)
+
+// glyphSet holds the rasterised candidate glyphs for shape mode
+type glyphSet struct {
+	chars   []rune
+	bitmaps [][]float64 // Coverage per sample, scaled so the densest glyph averages 1
+	means   []float64
+	vars    []float64
+}
+
+var (
+	glyphSetsMu sync.Mutex
+	glyphSets   = make(map[string]*glyphSet)
+)
+
+// loadGlyphSet rasterises the characters of a charset with the embedded Go
+// Mono font, once per charset. Characters the font lacks are skipped.
+func loadGlyphSet(charset string) (*glyphSet, error) {
+	glyphSetsMu.Lock()
+	defer glyphSetsMu.Unlock()
+	if set, ok := glyphSets[charset]; ok {
+		return set, nil
+	}
+
+	src, err := opentype.Parse(gomono.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shape font: %w", err)
+	}
+
+	// Rasterise at four times the sample grid, then average down
+	const scale = 4
+	face, err := opentype.NewFace(src, &opentype.FaceOptions{Size: shapeRows * scale * 0.8, DPI: 72, Hinting: font.HintingNone})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shape font: %w", err)
+	}
+	defer face.Close()
+
+	metrics := face.Metrics()
+	lineHeight := (metrics.Ascent + metrics.Descent).Ceil()
+	advance, _ := face.GlyphAdvance('M')
+	width := advance.Ceil()
+
+	set := &glyphSet{}
+	seen := make(map[rune]bool)
+	var buf = image.NewAlpha(image.Rect(0, 0, width, lineHeight))
+	for _, r := range charset {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+		if r != ' ' {
+			if _, ok := face.GlyphAdvance(r); !ok {
+				continue
+			}
+		}
+
+		draw.Draw(buf, buf.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		d := font.Drawer{Dst: buf, Src: image.Opaque, Face: face, Dot: fixed.P(0, metrics.Ascent.Ceil())}
+		d.DrawString(string(r))
+
+		set.chars = append(set.chars, r)
+		set.bitmaps = append(set.bitmaps, downsample(buf, shapeCols, shapeRows))
+	}
+	if len(set.chars) == 0 {
+		return nil, fmt.Errorf("shape mode: the font has none of the charset's characters")
+	}
+
+	// Scale coverage so the densest glyph matches a fully bright cell
+	maxMean := 0.0
+	for _, bitmap := range set.bitmaps {
+		maxMean = math.Max(maxMean, mean(bitmap))
+	}
+	for _, bitmap := range set.bitmaps {
+		if maxMean > 0 {
+			for i := range bitmap {
+				bitmap[i] /= maxMean
+			}
+		}
+		m := mean(bitmap)
+		set.means = append(set.means, m)
+		set.vars = append(set.vars, variance(bitmap, m))
+	}
+
+	glyphSets[charset] = set
+	return set, nil
+}
+
+// downsample averages an alpha image down to cols x rows samples
+func downsample(img *image.Alpha, cols, rows int) []float64 {
+	bounds := img.Bounds()
+	sums := make([]float64, cols*rows)
+	counts := make([]float64, cols*rows)
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			i := (y*rows/bounds.Dy())*cols + x*cols/bounds.Dx()
+			sums[i] += float64(img.AlphaAt(x, y).A) / 255.0
+			counts[i]++
+		}
+	}
+	for i := range sums {
+		if counts[i] > 0 {
+			sums[i] /= counts[i]
+		}
+	}
+	return sums
+}
+
+// sampleCell samples the brightness of one character cell on the shape grid
+func sampleCell(img image.Image, x, y, stepX, stepY float64, invert bool) []float64 {
+	patch := make([]float64, shapeCols*shapeRows)
+	subW, subH := stepX/shapeCols, stepY/shapeRows
+	for row := 0; row < shapeRows; row++ {
+		for col := 0; col < shapeCols; col++ {
+			px := int(x + float64(col)*subW)
+			py := int(y + float64(row)*subH)
+			_, _, _, brightness := sampleRegion(img, px, py, int(math.Ceil(subW)), int(math.Ceil(subH)))
+			if invert {
+				brightness = 1.0 - brightness
+			}
+			patch[row*shapeCols+col] = brightness
+		}
+	}
+	return patch
+}
+
+// match returns the glyph most similar to a cell: SSIM, blended with how
+// closely the glyph's density matches the cell's brightness
+func (g *glyphSet) match(patch []float64, brightnessWeight float64) rune {
+	pm := mean(patch)
+	pv := variance(patch, pm)
+
+	best, bestScore := 0, math.Inf(-1)
+	for i, bitmap := range g.bitmaps {
+		gm := g.means[i]
+		covariance := 0.0
+		for j, v := range bitmap {
+			covariance += (patch[j] - pm) * (v - gm)
+		}
+		covariance /= float64(len(bitmap))
+
+		ssim := ((2*pm*gm + ssimC1) * (2*covariance + ssimC2)) /
+			((pm*pm + gm*gm + ssimC1) * (pv + g.vars[i] + ssimC2))
+		score := (1-brightnessWeight)*ssim + brightnessWeight*(1-math.Abs(pm-gm))
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return g.chars[best]
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func variance(values []float64, m float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return sum / float64(len(values))
+}
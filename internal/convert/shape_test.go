@@ -0,0 +1,106 @@
+package convert
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	tests := map[string]Mode{
+		"":          ModeCharset,
+		"charset":   ModeCharset,
+		"shape":     ModeShape,
+		"Quadrant":  ModeQuadrant,
+		"braille":   ModeBraille,
+		"halfblock": ModeHalfBlock,
+	}
+	for name, want := range tests {
+		if got, err := ParseMode(name); err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseMode("mosaic"); err == nil {
+		t.Error("ParseMode should reject unknown modes")
+	}
+}
+
+func TestLoadGlyphSet(t *testing.T) {
+	set, err := loadGlyphSet(" |-😀")
+	if err != nil {
+		t.Fatalf("loadGlyphSet error: %v", err)
+	}
+	if string(set.chars) != " |-" {
+		t.Errorf("glyph set chars = %q, want characters the font has", string(set.chars))
+	}
+	again, _ := loadGlyphSet(" |-😀")
+	if again != set {
+		t.Error("glyph sets should be cached per charset")
+	}
+	if _, err := loadGlyphSet("😀"); err == nil {
+		t.Error("a charset the font can't draw should fail")
+	}
+}
+
+func TestGlyphSetMatch(t *testing.T) {
+	set, err := loadGlyphSet(ShapeCharset)
+	if err != nil {
+		t.Fatalf("loadGlyphSet error: %v", err)
+	}
+
+	patch := func(lit func(col, row int) bool) []float64 {
+		p := make([]float64, shapeCols*shapeRows)
+		for row := 0; row < shapeRows; row++ {
+			for col := 0; col < shapeCols; col++ {
+				if lit(col, row) {
+					p[row*shapeCols+col] = 1
+				}
+			}
+		}
+		return p
+	}
+
+	tests := []struct {
+		name  string
+		lit   func(col, row int) bool
+		among string
+	}{
+		{"blank", func(col, row int) bool { return false }, " "},
+		{"vertical", func(col, row int) bool { return col == 2 || col == 3 }, "|!l1I[]"},
+		{"horizontal", func(col, row int) bool { return row == 6 }, "-~="},
+		{"underline", func(col, row int) bool { return row == 10 || row == 11 }, "_"},
+	}
+	for _, tt := range tests {
+		got := set.match(patch(tt.lit), 0.3)
+		if !strings.ContainsRune(tt.among, got) {
+			t.Errorf("%s cell matched %q, want one of %q", tt.name, got, tt.among)
+		}
+	}
+}
+
+func TestFromImageShapeFollowsDiagonals(t *testing.T) {
+	// A bright diagonal from top-left to bottom-right
+	img := image.NewGray(image.Rect(0, 0, 200, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			if d := x - y; d > -8 && d < 8 {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	opts := Options{Width: 20, Height: 10, Mode: ModeShape}
+
+	result, err := FromImage(img, opts)
+	if err != nil {
+		t.Fatalf("FromImage error: %v", err)
+	}
+	if !strings.ContainsAny(result, "\\`\"x") {
+		t.Errorf("shape mode should draw the diagonal with slanted characters:\n%s", result)
+	}
+
+	sequential, err := fromImageSequential(img, opts)
+	if err != nil || sequential != result {
+		t.Errorf("sequential shape result differs from the worker pool result")
+	}
+}
@@ -47,6 +47,44 @@ var bayer8x8 = [][]float64{
 	{63.0 / 64.0, 31.0 / 64.0, 55.0 / 64.0, 23.0 / 64.0, 61.0 / 64.0, 29.0 / 64.0, 53.0 / 64.0, 21.0 / 64.0},
 }
 
+// diffusion is one weighted neighbour of an error diffusion kernel
+type diffusion struct {
+	dx, dy int
+	weight float64
+}
+
+// kernels holds the error diffusion kernels, shared by Apply and Quantize
+var kernels = map[Algorithm][]diffusion{
+	FloydSteinberg: {
+		{1, 0, 7.0 / 16}, {-1, 1, 3.0 / 16}, {0, 1, 5.0 / 16}, {1, 1, 1.0 / 16},
+	},
+	Atkinson: {
+		{1, 0, 1.0 / 8}, {2, 0, 1.0 / 8}, {-1, 1, 1.0 / 8}, {0, 1, 1.0 / 8}, {1, 1, 1.0 / 8}, {0, 2, 1.0 / 8},
+	},
+	Sierra: {
+		{1, 0, 5.0 / 32}, {2, 0, 3.0 / 32},
+		{-2, 1, 2.0 / 32}, {-1, 1, 4.0 / 32}, {0, 1, 5.0 / 32}, {1, 1, 4.0 / 32}, {2, 1, 2.0 / 32},
+		{-1, 2, 2.0 / 32}, {0, 2, 3.0 / 32}, {1, 2, 2.0 / 32},
+	},
+	SierraLite: {
+		{1, 0, 2.0 / 4}, {-1, 1, 1.0 / 4}, {0, 1, 1.0 / 4},
+	},
+	Stucki: {
+		{1, 0, 8.0 / 42}, {2, 0, 4.0 / 42},
+		{-2, 1, 2.0 / 42}, {-1, 1, 4.0 / 42}, {0, 1, 8.0 / 42}, {1, 1, 4.0 / 42}, {2, 1, 2.0 / 42},
+		{-2, 2, 1.0 / 42}, {-1, 2, 2.0 / 42}, {0, 2, 4.0 / 42}, {1, 2, 2.0 / 42}, {2, 2, 1.0 / 42},
+	},
+	Burkes: {
+		{1, 0, 8.0 / 32}, {2, 0, 4.0 / 32},
+		{-2, 1, 2.0 / 32}, {-1, 1, 4.0 / 32}, {0, 1, 8.0 / 32}, {1, 1, 4.0 / 32}, {2, 1, 2.0 / 32},
+	},
+	JarvisJudice: {
+		{1, 0, 7.0 / 48}, {2, 0, 5.0 / 48},
+		{-2, 1, 3.0 / 48}, {-1, 1, 5.0 / 48}, {0, 1, 7.0 / 48}, {1, 1, 5.0 / 48}, {2, 1, 3.0 / 48},
+		{-2, 2, 1.0 / 48}, {-1, 2, 3.0 / 48}, {0, 2, 5.0 / 48}, {1, 2, 3.0 / 48}, {2, 2, 1.0 / 48},
+	},
+}
+
 // Apply applies dithering algorithm to an image
 func Apply(img image.Image, algo Algorithm) *image.Gray {
 	bounds := img.Bounds()
@@ -61,34 +99,24 @@ func Apply(img image.Image, algo Algorithm) *image.Gray {
 		}
 	}
 
+	if kernel, ok := kernels[algo]; ok {
+		return errorDiffusion(gray, kernel)
+	}
 	switch algo {
-	case FloydSteinberg:
-		return floydSteinberg(gray)
 	case Bayer2x2:
 		return orderedDither(gray, bayer2x2)
 	case Bayer4x4:
 		return orderedDither(gray, bayer4x4)
 	case Bayer8x8:
 		return orderedDither(gray, bayer8x8)
-	case Atkinson:
-		return atkinson(gray)
-	case Sierra:
-		return sierra(gray)
-	case SierraLite:
-		return sierraLite(gray)
-	case Stucki:
-		return stucki(gray)
-	case Burkes:
-		return burkes(gray)
-	case JarvisJudice:
-		return jarvisJudice(gray)
 	default:
 		return gray
 	}
 }
 
-// floydSteinberg implements Floyd-Steinberg dithering
-func floydSteinberg(img *image.Gray) *image.Gray {
+// errorDiffusion thresholds an image to black and white, spreading each
+// pixel's error to its neighbours by the kernel's weights
+func errorDiffusion(img *image.Gray, kernel []diffusion) *image.Gray {
 	bounds := img.Bounds()
 	width, height := bounds.Max.X, bounds.Max.Y
 	result := image.NewGray(bounds)
@@ -111,19 +139,12 @@ func floydSteinberg(img *image.Gray) *image.Gray {
 			}
 			result.SetGray(x, y, color.Gray{Y: uint8(newPixel)})
 
-			err := oldPixel - newPixel
-
 			// Distribute error
-			if x+1 < width {
-				errors[y][x+1] += err * 7.0 / 16.0
-			}
-			if y+1 < height {
-				if x > 0 {
-					errors[y+1][x-1] += err * 3.0 / 16.0
-				}
-				errors[y+1][x] += err * 5.0 / 16.0
-				if x+1 < width {
-					errors[y+1][x+1] += err * 1.0 / 16.0
+			err := oldPixel - newPixel
+			for _, d := range kernel {
+				ny, nx := y+d.dy, x+d.dx
+				if ny < height && nx >= 0 && nx < width {
+					errors[ny][nx] += err * d.weight
 				}
 			}
 		}
@@ -156,347 +177,6 @@ func orderedDither(img *image.Gray, matrix [][]float64) *image.Gray {
 	return result
 }
 
-// atkinson implements Atkinson dithering (used by early Macs)
-func atkinson(img *image.Gray) *image.Gray {
-	bounds := img.Bounds()
-	width, height := bounds.Max.X, bounds.Max.Y
-	result := image.NewGray(bounds)
-
-	errors := make([][]float64, height)
-	for y := 0; y < height; y++ {
-		errors[y] = make([]float64, width)
-		for x := 0; x < width; x++ {
-			errors[y][x] = float64(img.GrayAt(x, y).Y)
-		}
-	}
-
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			oldPixel := errors[y][x]
-			newPixel := 0.0
-			if oldPixel > 127 {
-				newPixel = 255
-			}
-			result.SetGray(x, y, color.Gray{Y: uint8(newPixel)})
-
-			// Atkinson uses 1/8 and only distributes 6/8 of the error
-			err := (oldPixel - newPixel) / 8.0
-
-			if x+1 < width {
-				errors[y][x+1] += err
-			}
-			if x+2 < width {
-				errors[y][x+2] += err
-			}
-			if y+1 < height {
-				if x > 0 {
-					errors[y+1][x-1] += err
-				}
-				errors[y+1][x] += err
-				if x+1 < width {
-					errors[y+1][x+1] += err
-				}
-			}
-			if y+2 < height {
-				errors[y+2][x] += err
-			}
-		}
-	}
-
-	return result
-}
-
-// sierra implements Sierra dithering
-func sierra(img *image.Gray) *image.Gray {
-	bounds := img.Bounds()
-	width, height := bounds.Max.X, bounds.Max.Y
-	result := image.NewGray(bounds)
-
-	errors := make([][]float64, height)
-	for y := 0; y < height; y++ {
-		errors[y] = make([]float64, width)
-		for x := 0; x < width; x++ {
-			errors[y][x] = float64(img.GrayAt(x, y).Y)
-		}
-	}
-
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			oldPixel := errors[y][x]
-			newPixel := 0.0
-			if oldPixel > 127 {
-				newPixel = 255
-			}
-			result.SetGray(x, y, color.Gray{Y: uint8(newPixel)})
-
-			err := oldPixel - newPixel
-
-			// Sierra coefficients (divided by 32)
-			if x+1 < width {
-				errors[y][x+1] += err * 5.0 / 32.0
-			}
-			if x+2 < width {
-				errors[y][x+2] += err * 3.0 / 32.0
-			}
-			if y+1 < height {
-				if x > 1 {
-					errors[y+1][x-2] += err * 2.0 / 32.0
-				}
-				if x > 0 {
-					errors[y+1][x-1] += err * 4.0 / 32.0
-				}
-				errors[y+1][x] += err * 5.0 / 32.0
-				if x+1 < width {
-					errors[y+1][x+1] += err * 4.0 / 32.0
-				}
-				if x+2 < width {
-					errors[y+1][x+2] += err * 2.0 / 32.0
-				}
-			}
-			if y+2 < height {
-				if x > 0 {
-					errors[y+2][x-1] += err * 2.0 / 32.0
-				}
-				errors[y+2][x] += err * 3.0 / 32.0
-				if x+1 < width {
-					errors[y+2][x+1] += err * 2.0 / 32.0
-				}
-			}
-		}
-	}
-
-	return result
-}
-
-// sierraLite implements Sierra Lite (Two-Row Sierra) dithering
-func sierraLite(img *image.Gray) *image.Gray {
-	bounds := img.Bounds()
-	width, height := bounds.Max.X, bounds.Max.Y
-	result := image.NewGray(bounds)
-
-	errors := make([][]float64, height)
-	for y := 0; y < height; y++ {
-		errors[y] = make([]float64, width)
-		for x := 0; x < width; x++ {
-			errors[y][x] = float64(img.GrayAt(x, y).Y)
-		}
-	}
-
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			oldPixel := errors[y][x]
-			newPixel := 0.0
-			if oldPixel > 127 {
-				newPixel = 255
-			}
-			result.SetGray(x, y, color.Gray{Y: uint8(newPixel)})
-
-			err := oldPixel - newPixel
-
-			if x+1 < width {
-				errors[y][x+1] += err * 2.0 / 4.0
-			}
-			if y+1 < height {
-				if x > 0 {
-					errors[y+1][x-1] += err * 1.0 / 4.0
-				}
-				errors[y+1][x] += err * 1.0 / 4.0
-			}
-		}
-	}
-
-	return result
-}
-
-// stucki implements Stucki dithering
-func stucki(img *image.Gray) *image.Gray {
-	bounds := img.Bounds()
-	width, height := bounds.Max.X, bounds.Max.Y
-	result := image.NewGray(bounds)
-
-	errors := make([][]float64, height)
-	for y := 0; y < height; y++ {
-		errors[y] = make([]float64, width)
-		for x := 0; x < width; x++ {
-			errors[y][x] = float64(img.GrayAt(x, y).Y)
-		}
-	}
-
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			oldPixel := errors[y][x]
-			newPixel := 0.0
-			if oldPixel > 127 {
-				newPixel = 255
-			}
-			result.SetGray(x, y, color.Gray{Y: uint8(newPixel)})
-
-			err := oldPixel - newPixel
-
-			// Stucki coefficients (divided by 42)
-			if x+1 < width {
-				errors[y][x+1] += err * 8.0 / 42.0
-			}
-			if x+2 < width {
-				errors[y][x+2] += err * 4.0 / 42.0
-			}
-			if y+1 < height {
-				if x > 1 {
-					errors[y+1][x-2] += err * 2.0 / 42.0
-				}
-				if x > 0 {
-					errors[y+1][x-1] += err * 4.0 / 42.0
-				}
-				errors[y+1][x] += err * 8.0 / 42.0
-				if x+1 < width {
-					errors[y+1][x+1] += err * 4.0 / 42.0
-				}
-				if x+2 < width {
-					errors[y+1][x+2] += err * 2.0 / 42.0
-				}
-			}
-			if y+2 < height {
-				if x > 1 {
-					errors[y+2][x-2] += err * 1.0 / 42.0
-				}
-				if x > 0 {
-					errors[y+2][x-1] += err * 2.0 / 42.0
-				}
-				errors[y+2][x] += err * 4.0 / 42.0
-				if x+1 < width {
-					errors[y+2][x+1] += err * 2.0 / 42.0
-				}
-				if x+2 < width {
-					errors[y+2][x+2] += err * 1.0 / 42.0
-				}
-			}
-		}
-	}
-
-	return result
-}
-
-// burkes implements Burkes dithering
-func burkes(img *image.Gray) *image.Gray {
-	bounds := img.Bounds()
-	width, height := bounds.Max.X, bounds.Max.Y
-	result := image.NewGray(bounds)
-
-	errors := make([][]float64, height)
-	for y := 0; y < height; y++ {
-		errors[y] = make([]float64, width)
-		for x := 0; x < width; x++ {
-			errors[y][x] = float64(img.GrayAt(x, y).Y)
-		}
-	}
-
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			oldPixel := errors[y][x]
-			newPixel := 0.0
-			if oldPixel > 127 {
-				newPixel = 255
-			}
-			result.SetGray(x, y, color.Gray{Y: uint8(newPixel)})
-
-			err := oldPixel - newPixel
-
-			// Burkes coefficients (divided by 32)
-			if x+1 < width {
-				errors[y][x+1] += err * 8.0 / 32.0
-			}
-			if x+2 < width {
-				errors[y][x+2] += err * 4.0 / 32.0
-			}
-			if y+1 < height {
-				if x > 1 {
-					errors[y+1][x-2] += err * 2.0 / 32.0
-				}
-				if x > 0 {
-					errors[y+1][x-1] += err * 4.0 / 32.0
-				}
-				errors[y+1][x] += err * 8.0 / 32.0
-				if x+1 < width {
-					errors[y+1][x+1] += err * 4.0 / 32.0
-				}
-				if x+2 < width {
-					errors[y+1][x+2] += err * 2.0 / 32.0
-				}
-			}
-		}
-	}
-
-	return result
-}
-
-// jarvisJudice implements Jarvis-Judice-Ninke dithering
-func jarvisJudice(img *image.Gray) *image.Gray {
-	bounds := img.Bounds()
-	width, height := bounds.Max.X, bounds.Max.Y
-	result := image.NewGray(bounds)
-
-	errors := make([][]float64, height)
-	for y := 0; y < height; y++ {
-		errors[y] = make([]float64, width)
-		for x := 0; x < width; x++ {
-			errors[y][x] = float64(img.GrayAt(x, y).Y)
-		}
-	}
-
-	for y := 0; y < height; y++ {
-		for x := 0; x < width; x++ {
-			oldPixel := errors[y][x]
-			newPixel := 0.0
-			if oldPixel > 127 {
-				newPixel = 255
-			}
-			result.SetGray(x, y, color.Gray{Y: uint8(newPixel)})
-
-			err := oldPixel - newPixel
-
-			// Jarvis-Judice-Ninke coefficients (divided by 48)
-			if x+1 < width {
-				errors[y][x+1] += err * 7.0 / 48.0
-			}
-			if x+2 < width {
-				errors[y][x+2] += err * 5.0 / 48.0
-			}
-			if y+1 < height {
-				if x > 1 {
-					errors[y+1][x-2] += err * 3.0 / 48.0
-				}
-				if x > 0 {
-					errors[y+1][x-1] += err * 5.0 / 48.0
-				}
-				errors[y+1][x] += err * 7.0 / 48.0
-				if x+1 < width {
-					errors[y+1][x+1] += err * 5.0 / 48.0
-				}
-				if x+2 < width {
-					errors[y+1][x+2] += err * 3.0 / 48.0
-				}
-			}
-			if y+2 < height {
-				if x > 1 {
-					errors[y+2][x-2] += err * 1.0 / 48.0
-				}
-				if x > 0 {
-					errors[y+2][x-1] += err * 3.0 / 48.0
-				}
-				errors[y+2][x] += err * 5.0 / 48.0
-				if x+1 < width {
-					errors[y+2][x+1] += err * 3.0 / 48.0
-				}
-				if x+2 < width {
-					errors[y+2][x+2] += err * 1.0 / 48.0
-				}
-			}
-		}
-	}
-
-	return result
-}
-
 // ToGrayscale converts brightness values to grayscale levels
 func ToGrayscale(img *image.Gray, levels int) *image.Gray {
 	if levels < 2 {
@@ -546,7 +226,7 @@ func ListAlgorithms() []string {
 // GetAlgorithm returns algorithm from string
 func GetAlgorithm(name string) Algorithm {
 	switch name {
-	case "floyd-steinberg", "fs":
+	case "floyd-steinberg", "floyd", "fs":
 		return FloydSteinberg
 	case "bayer2x2", "bayer2":
 		return Bayer2x2
@@ -9,4 +9,5 @@
 //	grayscale := dither.FloydSteinberg(img)
 //	grayscale := dither.Bayer(img)
 //	grayscale := dither.Atkinson(img)
+//	levels := dither.Quantize(brightness, dither.FloydSteinberg, 10)
 package dither
@@ -0,0 +1,143 @@
+package dither
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Quantize reduces a grid of brightness values (0 to 1) to levels evenly
+// spaced steps and returns the step index, 0 to levels-1, of each cell.
+// Unlike Apply, which produces black and white pixels, the quantization
+// error is spread between neighbouring steps, so it can drive a whole
+// character ramp. With None each value is rounded to the nearest step.
+func Quantize(values [][]float64, algo Algorithm, levels int) [][]int {
+	if levels < 2 {
+		levels = 2
+	}
+	scale := float64(levels - 1)
+	height := len(values)
+
+	result := make([][]int, height)
+	for y := range values {
+		result[y] = make([]int, len(values[y]))
+	}
+
+	if matrix := bayerMatrix(algo); matrix != nil {
+		size := len(matrix)
+		for y, row := range values {
+			for x, v := range row {
+				scaled := clamp01(v) * scale
+				level := math.Floor(scaled)
+				if scaled-level > matrix[y%size][x%size] {
+					level++
+				}
+				result[y][x] = int(math.Min(level, scale))
+			}
+		}
+		return result
+	}
+
+	kernel := kernels[algo]
+	work := make([][]float64, height)
+	for y, row := range values {
+		work[y] = append([]float64(nil), row...)
+	}
+	for y, row := range work {
+		for x, v := range row {
+			level := math.Round(clamp01(v) * scale)
+			result[y][x] = int(level)
+
+			err := v - level/scale
+			for _, d := range kernel {
+				ny, nx := y+d.dy, x+d.dx
+				if ny < height && nx >= 0 && nx < len(work[ny]) {
+					work[ny][nx] += err * d.weight
+				}
+			}
+		}
+	}
+	return result
+}
+
+// QuantizeColors reduces a grid of RGB colors (0 to 255 per channel) to
+// the colors a palette can show, where nearest maps a color to its closest
+// palette entry. Error diffusion spreads each channel's error to the
+// neighbours; ordered algorithms offset colors by up to spread/2 per
+// channel, which should be about the distance between palette entries.
+func QuantizeColors(pixels [][][3]float64, algo Algorithm, spread float64, nearest func(c [3]float64) [3]float64) [][][3]float64 {
+	height := len(pixels)
+	result := make([][][3]float64, height)
+	for y := range pixels {
+		result[y] = make([][3]float64, len(pixels[y]))
+	}
+
+	if matrix := bayerMatrix(algo); matrix != nil {
+		size := len(matrix)
+		for y, row := range pixels {
+			for x, c := range row {
+				offset := (matrix[y%size][x%size] - 0.5) * spread
+				for i := range c {
+					c[i] = math.Max(0, math.Min(255, c[i]-offset))
+				}
+				result[y][x] = nearest(c)
+			}
+		}
+		return result
+	}
+
+	kernel := kernels[algo]
+	work := make([][][3]float64, height)
+	for y, row := range pixels {
+		work[y] = append([][3]float64(nil), row...)
+	}
+	for y, row := range work {
+		for x, c := range row {
+			for i := range c {
+				c[i] = math.Max(0, math.Min(255, c[i]))
+			}
+			q := nearest(c)
+			result[y][x] = q
+
+			for _, d := range kernel {
+				ny, nx := y+d.dy, x+d.dx
+				if ny < height && nx >= 0 && nx < len(work[ny]) {
+					for i := range c {
+						work[ny][nx][i] += (c[i] - q[i]) * d.weight
+					}
+				}
+			}
+		}
+	}
+	return result
+}
+
+// ParseAlgorithm parses an algorithm name or alias, rejecting unknown names
+// where GetAlgorithm falls back to None
+func ParseAlgorithm(name string) (Algorithm, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == string(None) {
+		return None, nil
+	}
+	if algo := GetAlgorithm(name); algo != None {
+		return algo, nil
+	}
+	return None, fmt.Errorf("unknown dithering algorithm %q (use %s)", name, strings.Join(ListAlgorithms(), ", "))
+}
+
+// bayerMatrix returns the threshold matrix of an ordered algorithm
+func bayerMatrix(algo Algorithm) [][]float64 {
+	switch algo {
+	case Bayer2x2:
+		return bayer2x2
+	case Bayer4x4:
+		return bayer4x4
+	case Bayer8x8:
+		return bayer8x8
+	}
+	return nil
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,138 @@
+package dither
+
+import (
+	"math"
+	"testing"
+)
+
+func uniformGrid(width, height int, value float64) [][]float64 {
+	grid := make([][]float64, height)
+	for y := range grid {
+		grid[y] = make([]float64, width)
+		for x := range grid[y] {
+			grid[y][x] = value
+		}
+	}
+	return grid
+}
+
+// meanLevel returns the average step of a quantized grid scaled to 0..1
+func meanLevel(grid [][]int, levels int) float64 {
+	total, count := 0.0, 0.0
+	for _, row := range grid {
+		for _, level := range row {
+			total += float64(level) / float64(levels-1)
+			count++
+		}
+	}
+	return total / count
+}
+
+func TestQuantizeNone(t *testing.T) {
+	grid := Quantize([][]float64{{0, 0.2, 0.5, 0.9, 1.5, -1}}, None, 5)
+	want := []int{0, 1, 2, 4, 4, 0}
+	for i, level := range grid[0] {
+		if level != want[i] {
+			t.Errorf("level[%d] = %d, want %d", i, level, want[i])
+		}
+	}
+}
+
+func TestQuantizePreservesTone(t *testing.T) {
+	// A tone between two steps can't be represented by rounding, but the
+	// dithered grid should average out to it
+	for _, algo := range []Algorithm{FloydSteinberg, Atkinson, Sierra, SierraLite, Stucki, Burkes, JarvisJudice, Bayer2x2, Bayer4x4, Bayer8x8} {
+		grid := Quantize(uniformGrid(32, 32, 0.3), algo, 3)
+
+		used := make(map[int]bool)
+		for _, row := range grid {
+			for _, level := range row {
+				if level < 0 || level > 2 {
+					t.Fatalf("%s: level %d out of range", algo, level)
+				}
+				used[level] = true
+			}
+		}
+		if !used[0] || !used[1] || used[2] {
+			t.Errorf("%s: 0.3 between steps 0 and 0.5 should mix levels 0 and 1, got %v", algo, used)
+		}
+		// A 2x2 matrix only has four thresholds, so it is coarser
+		tolerance := 0.05
+		if algo == Bayer2x2 {
+			tolerance = 0.1
+		}
+		if mean := meanLevel(grid, 3); math.Abs(mean-0.3) > tolerance {
+			t.Errorf("%s: mean tone = %.3f, want about 0.3", algo, mean)
+		}
+	}
+}
+
+func TestQuantizeLevelsClamped(t *testing.T) {
+	grid := Quantize(uniformGrid(4, 4, 1), FloydSteinberg, 0)
+	if grid[0][0] != 1 {
+		t.Errorf("levels below 2 should act as 2, got level %d for white", grid[0][0])
+	}
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	tests := map[string]Algorithm{
+		"":         None,
+		"none":     None,
+		"floyd":    FloydSteinberg,
+		"FS":       FloydSteinberg,
+		"bayer":    Bayer4x4,
+		"atkinson": Atkinson,
+	}
+	for name, want := range tests {
+		if got, err := ParseAlgorithm(name); err != nil || got != want {
+			t.Errorf("ParseAlgorithm(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseAlgorithm("spiral"); err == nil {
+		t.Error("ParseAlgorithm should reject unknown algorithms")
+	}
+}
+
+func TestQuantizeColorsPreservesTone(t *testing.T) {
+	// A black and white palette, so mid grey can only be shown by dithering
+	nearest := func(c [3]float64) [3]float64 {
+		if c[0]+c[1]+c[2] > 3*127.5 {
+			return [3]float64{255, 255, 255}
+		}
+		return [3]float64{}
+	}
+
+	pixels := make([][][3]float64, 16)
+	for y := range pixels {
+		pixels[y] = make([][3]float64, 16)
+		for x := range pixels[y] {
+			pixels[y][x] = [3]float64{64, 64, 64}
+		}
+	}
+
+	for _, algo := range []Algorithm{FloydSteinberg, Sierra, Bayer4x4} {
+		total := 0.0
+		for _, row := range QuantizeColors(pixels, algo, 255, nearest) {
+			for _, c := range row {
+				if c[0] != 0 && c[0] != 255 {
+					t.Fatalf("%s: %v is not a palette color", algo, c)
+				}
+				total += c[0]
+			}
+		}
+		if mean := total / 256; math.Abs(mean-64) > 16 {
+			t.Errorf("%s: mean = %.1f, want about 64", algo, mean)
+		}
+	}
+
+	for _, row := range QuantizeColors(pixels, None, 255, nearest) {
+		for _, c := range row {
+			if c[0] != 0 {
+				t.Fatalf("None should round every pixel to black, got %v", c)
+			}
+		}
+	}
+	if pixels[0][0][0] != 64 {
+		t.Error("QuantizeColors should not modify its input")
+	}
+}
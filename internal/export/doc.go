@@ -1,11 +1,24 @@
-// Package export provides ASCII art export to multiple image formats.
+// Package export provides ASCII art export to multiple image and document formats.
 //
-// It supports exporting ASCII art to PNG, SVG, and HTML formats with customizable colors,
-// fonts, backgrounds, and styling options.
+// It supports exporting ASCII art to PNG, SVG, HTML, plain or raw ANSI text, ANSI art, Markdown,
+// JSON, RTF and GIF with customizable colors, fonts, backgrounds, and styling options. Each format is an
+// Exporter registered by name and file extensions (Register); Save picks one by name or by the
+// output file's extension, and Exporters lists them for menus and help text. SGR escapes in the
+// text (ParseANSI) are kept as per-character foreground and background colors, bold, italic and
+// underline.
+//
+// PNGs are rendered (Renderer) with the embedded Go Mono fonts at a configurable size, DPI and
+// padding, measuring lines in display columns so East Asian wide characters take two. Box
+// drawing, block element, braille and sextant characters are drawn as shapes that fill their
+// cells; other characters Go Mono lacks fall back per glyph to installed fonts
+// (FallbackFontPaths), then to an empty box. ToGIF renders frames the same way into an
+// animated GIF with per-frame delays, a loop count and one palette shared by all frames.
 //
 // Example usage:
 //
+//	export.Save(asciiArt, "output.rtf", "", export.Options{Title: "Hello"})
 //	export.ToPNG(asciiArt, "output.png", opts)
 //	export.ToSVG(asciiArt, "output.svg", opts)
 //	export.ToHTML(asciiArt, "output.html", opts)
+//	export.ToGIF(frames, delaysMs, 0, "output.gif", bg, fg, export.DefaultPNGOptions())
 package export
@@ -2,67 +2,26 @@ package export
 
 import (
 	"fmt"
-	"image"
 	"image/color"
 	"image/png"
 	"os"
 	"strings"
-
-	"golang.org/x/image/font"
-	"golang.org/x/image/font/basicfont"
-	"golang.org/x/image/math/fixed"
 )
 
-// ToPNG exports ASCII art to a PNG image
+// ToPNG exports ASCII art to a PNG image with the default font size and
+// padding. SGR colors and bold, italic and underline in the text are
+// drawn; fgHex and bgHex are the default colors.
 func ToPNG(text, filename, bgHex, fgHex string) error {
-	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
-
-	// Calculate dimensions
-	charWidth := 7   // basicfont.Face7x13 character width
-	charHeight := 13 // basicfont.Face7x13 character height
-	padding := 20
-
-	maxWidth := 0
-	for _, line := range lines {
-		if len(line) > maxWidth {
-			maxWidth = len(line)
-		}
-	}
-
-	imgWidth := maxWidth*charWidth + padding*2
-	imgHeight := len(lines)*charHeight + padding*2
-
-	// Create image
-	img := image.NewRGBA(image.Rect(0, 0, imgWidth, imgHeight))
-
-	// Parse colors
-	bgColor := parseHexColor(bgHex)
-	fgColor := parseHexColor(fgHex)
-
-	// Fill background
-	for y := 0; y < imgHeight; y++ {
-		for x := 0; x < imgWidth; x++ {
-			img.Set(x, y, bgColor)
-		}
-	}
-
-	// Draw text
-	face := basicfont.Face7x13
-	drawer := &font.Drawer{
-		Dst:  img,
-		Src:  image.NewUniform(fgColor),
-		Face: face,
-	}
+	return ToPNGWithOptions(text, filename, bgHex, fgHex, DefaultPNGOptions())
+}
 
-	for i, line := range lines {
-		x := padding
-		y := padding + (i+1)*charHeight - 3 // baseline adjustment
-		drawer.Dot = fixed.Point26_6{
-			X: fixed.I(x),
-			Y: fixed.I(y),
-		}
-		drawer.DrawString(line)
+// ToPNGWithOptions exports ASCII art to a PNG image rendered with opts
+func ToPNGWithOptions(text, filename, bgHex, fgHex string, opts PNGOptions) error {
+	r, err := NewRenderer(opts)
+	if err != nil {
+		return err
 	}
+	img := r.Render(ParseANSI(text), parseHexColor(bgHex), parseHexColor(fgHex))
 
 	// Save to file
 	f, err := os.Create(filename)
@@ -91,22 +50,19 @@ func parseHexColor(hex string) color.RGBA {
 	return color.RGBA{r, g, b, 255}
 }
 
-// ToSVG exports ASCII art to SVG (for better scalability)
+// ToSVG exports ASCII art to SVG (for better scalability). SGR colors and
+// bold, italic and underline in the text are kept; fgHex and bgHex are the
+// default colors.
 func ToSVG(text, filename, bgHex, fgHex string) error {
-	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	lines := ParseANSI(text)
 
-	charWidth := 8.4   // approximate width for monospace
+	// Monospace fonts are 0.6em wide, so at 14px each run placed by its
+	// column lines up with the cell backgrounds
+	charWidth := 8.4
 	charHeight := 14.0 // line height
 	padding := 20.0
 
-	maxWidth := 0
-	for _, line := range lines {
-		if len(line) > maxWidth {
-			maxWidth = len(line)
-		}
-	}
-
-	width := float64(maxWidth)*charWidth + padding*2
+	width := float64(maxLineWidth(lines))*charWidth + padding*2
 	height := float64(len(lines))*charHeight + padding*2
 
 	f, err := os.Create(filename)
@@ -119,16 +75,34 @@ func ToSVG(text, filename, bgHex, fgHex string) error {
 	fmt.Fprintf(f, `<?xml version="1.0" encoding="UTF-8"?>
 <svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f">
   <rect width="100%%" height="100%%" fill="%s"/>
-  <text font-family="monospace" font-size="12" fill="%s">
-`, width, height, bgHex, fgHex)
+`, width, height, bgHex)
+
+	// Write cell backgrounds
+	for i, line := range lines {
+		y := padding + float64(i)*charHeight + 3
+		for _, r := range runs(line) {
+			if !r.style.HasBg {
+				continue
+			}
+			x := padding + float64(r.col)*charWidth
+			fmt.Fprintf(f, `  <rect x="%.1f" y="%.0f" width="%.1f" height="%.0f" fill="%s"/>
+`, x, y, float64(len([]rune(r.text)))*charWidth, charHeight, hexColor(r.style.Bg))
+		}
+	}
 
-	// Write text lines
+	fmt.Fprintf(f, `  <text font-family="monospace" font-size="14" fill="%s" xml:space="preserve">
+`, fgHex)
+
+	// Write text lines, a tspan for each run of one style
 	for i, line := range lines {
 		y := padding + float64(i+1)*charHeight
-		// Escape XML special characters
-		escaped := escapeXML(line)
-		fmt.Fprintf(f, `    <tspan x="%.0f" y="%.0f">%s</tspan>
-`, padding, y, escaped)
+		for _, r := range runs(line) {
+			x := padding + float64(r.col)*charWidth
+			// Escape XML special characters
+			escaped := escapeXML(r.text)
+			fmt.Fprintf(f, `    <tspan x="%.1f" y="%.0f"%s>%s</tspan>
+`, x, y, svgAttrs(r.style), escaped)
+		}
 	}
 
 	// Close SVG
@@ -139,6 +113,24 @@ func ToSVG(text, filename, bgHex, fgHex string) error {
 	return nil
 }
 
+// svgAttrs returns the tspan attributes for a style
+func svgAttrs(style Style) string {
+	var sb strings.Builder
+	if style.HasFg {
+		fmt.Fprintf(&sb, ` fill="%s"`, hexColor(style.Fg))
+	}
+	if style.Bold {
+		sb.WriteString(` font-weight="bold"`)
+	}
+	if style.Italic {
+		sb.WriteString(` font-style="italic"`)
+	}
+	if style.Underline {
+		sb.WriteString(` text-decoration="underline"`)
+	}
+	return sb.String()
+}
+
 func escapeXML(s string) string {
 	s = strings.ReplaceAll(s, "&", "&amp;")
 	s = strings.ReplaceAll(s, "<", "&lt;")
@@ -148,7 +140,9 @@ func escapeXML(s string) string {
 	return s
 }
 
-// ToHTML exports ASCII art to HTML with styling
+// ToHTML exports ASCII art to HTML with styling. SGR colors and bold,
+// italic and underline in the text become styled spans; fgHex and bgHex
+// are the page's colors.
 func ToHTML(text, filename, bgHex, fgHex, title string) error {
 	f, err := os.Create(filename)
 	if err != nil {
@@ -156,7 +150,20 @@ func ToHTML(text, filename, bgHex, fgHex, title string) error {
 	}
 	defer f.Close()
 
-	escaped := escapeXML(text)
+	var body strings.Builder
+	for i, line := range ParseANSI(text) {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		for _, r := range runs(line) {
+			escaped := escapeXML(r.text)
+			if css := htmlStyle(r.style); css != "" {
+				fmt.Fprintf(&body, `<span style="%s">%s</span>`, css, escaped)
+			} else {
+				body.WriteString(escaped)
+			}
+		}
+	}
 
 	fmt.Fprintf(f, `<!DOCTYPE html>
 <html>
@@ -187,7 +194,28 @@ func ToHTML(text, filename, bgHex, fgHex, title string) error {
   <pre>%s</pre>
 </body>
 </html>
-`, title, bgHex, fgHex, escaped)
+`, escapeXML(title), bgHex, fgHex, body.String())
 
 	return nil
 }
+
+// htmlStyle returns the inline CSS for a style, "" for the default
+func htmlStyle(style Style) string {
+	var props []string
+	if style.HasFg {
+		props = append(props, "color: "+hexColor(style.Fg))
+	}
+	if style.HasBg {
+		props = append(props, "background-color: "+hexColor(style.Bg))
+	}
+	if style.Bold {
+		props = append(props, "font-weight: bold")
+	}
+	if style.Italic {
+		props = append(props, "font-style: italic")
+	}
+	if style.Underline {
+		props = append(props, "text-decoration: underline")
+	}
+	return strings.Join(props, "; ")
+}
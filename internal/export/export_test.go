@@ -1,7 +1,9 @@
 package export
 
 import (
+	"image"
 	"image/color"
+	"image/png"
 	"os"
 	"path/filepath"
 	"strings"
@@ -177,6 +179,17 @@ func TestToHTML(t *testing.T) {
 	}
 }
 
+func TestToHTMLEscapesTitle(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "title.html")
+	if err := ToHTML("Hi", out, "#000000", "#ffffff", "</title><script>x</script>"); err != nil {
+		t.Fatalf("ToHTML() error: %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	if !strings.Contains(string(data), "<title>&lt;/title&gt;&lt;script&gt;x&lt;/script&gt;</title>") {
+		t.Errorf("HTML title not escaped:\n%s", data)
+	}
+}
+
 func TestToHTMLInvalidPath(t *testing.T) {
 	err := ToHTML("Hello", "/nonexistent/dir/test.html", "#000", "#fff", "Test")
 	if err == nil {
@@ -205,3 +218,176 @@ func TestToPNGMultiline(t *testing.T) {
 		t.Error("Output PNG file is empty")
 	}
 }
+
+func TestToPNGColors(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "color.png")
+
+	// A red full block, a space on a blue background and a plain M
+	err := ToPNG("\x1b[38;2;255;0;0m█\x1b[48;2;0;0;255m \x1b[0mM", out, "#000000", "#ffffff")
+	if err != nil {
+		t.Fatalf("ToPNG() error: %v", err)
+	}
+	img := decodePNG(t, out)
+
+	r, err := NewRenderer(DefaultPNGOptions())
+	if err != nil {
+		t.Fatalf("NewRenderer() error: %v", err)
+	}
+	cw, ch := r.CellSize()
+
+	// Escape codes take up no space
+	if w := img.Bounds().Dx(); w != 3*cw+2*DefaultPadding {
+		t.Errorf("width = %d, want %d", w, 3*cw+2*DefaultPadding)
+	}
+
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+	white, black := color.RGBA{255, 255, 255, 255}, color.RGBA{0, 0, 0, 255}
+
+	block := countColors(img, image.Rect(0, 0, cw, ch).Add(image.Pt(DefaultPadding, DefaultPadding)))
+	if block[red] != cw*ch {
+		t.Errorf("block cell has %v, want all red", block)
+	}
+	space := countColors(img, image.Rect(cw, 0, 2*cw, ch).Add(image.Pt(DefaultPadding, DefaultPadding)))
+	if space[blue] != cw*ch {
+		t.Errorf("background cell has %v, want all blue", space)
+	}
+	plain := countColors(img, image.Rect(2*cw, 0, 3*cw, ch).Add(image.Pt(DefaultPadding, DefaultPadding)))
+	if plain[white] == 0 || plain[black] == 0 || plain[red] > 0 {
+		t.Errorf("plain cell has %v, want white on black", plain)
+	}
+}
+
+func TestToPNGUnicode(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "unicode.png")
+
+	opts := PNGOptions{FontSize: 20, Padding: 4}
+	// Box drawing, braille and a wide character
+	err := ToPNGWithOptions("┌──┐\n│⣿⠀│\n漢字", out, "#000000", "#ffffff", opts)
+	if err != nil {
+		t.Fatalf("ToPNGWithOptions() error: %v", err)
+	}
+	img := decodePNG(t, out)
+
+	r, err := NewRenderer(opts)
+	if err != nil {
+		t.Fatalf("NewRenderer() error: %v", err)
+	}
+	cw, ch := r.CellSize()
+
+	// Lines are measured in columns, so the two wide characters are as
+	// wide as the four-column box
+	if w, h := img.Bounds().Dx(), img.Bounds().Dy(); w != 4*cw+8 || h != 3*ch+8 {
+		t.Errorf("size = %dx%d, want %dx%d", w, h, 4*cw+8, 3*ch+8)
+	}
+
+	// The horizontal lines join up across cells
+	y := 4 + ch/2
+	for x := 4 + cw/2; x < 4+3*cw+cw/2; x++ {
+		if c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA); c.R < 128 {
+			t.Fatalf("box top has a gap at x=%d", x)
+		}
+	}
+
+	// ⣿ has all eight dots, ⠀ none
+	cell := func(col, row int) image.Rectangle {
+		return image.Rect(col*cw, row*ch, (col+1)*cw, (row+1)*ch).Add(image.Pt(4, 4))
+	}
+	white := color.RGBA{255, 255, 255, 255}
+	if n := countColors(img, cell(1, 1))[white]; n < 8 {
+		t.Errorf("full braille cell has %d white pixels, want at least 8", n)
+	}
+	if n := len(countColors(img, cell(2, 1))); n != 1 {
+		t.Errorf("blank braille cell has %d colors, want 1", n)
+	}
+}
+
+func TestToPNGMissingGlyph(t *testing.T) {
+	saved := FallbackFontPaths
+	FallbackFontPaths = nil
+	defer func() { FallbackFontPaths = saved }()
+
+	r, err := NewRenderer(PNGOptions{})
+	if err != nil {
+		t.Fatalf("NewRenderer() error: %v", err)
+	}
+	r.opened = true // skip any fallback fonts already loaded
+
+	// A private use character no font has is drawn as a box
+	img := r.Render(ParseANSI("\ue000"), color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255})
+	if n := countColors(img, img.Bounds())[color.RGBA{255, 255, 255, 255}]; n == 0 {
+		t.Error("missing character drew nothing")
+	}
+}
+
+func decodePNG(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	return img
+}
+
+func countColors(img image.Image, r image.Rectangle) map[color.RGBA]int {
+	colors := make(map[color.RGBA]int)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			colors[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)]++
+		}
+	}
+	return colors
+}
+
+func TestToSVGColors(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "color.svg")
+
+	err := ToSVG("\x1b[1;31mHot\x1b[0m \x1b[44mCold\x1b[0m", out, "#000000", "#ffffff")
+	if err != nil {
+		t.Fatalf("ToSVG() error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+
+	content := string(data)
+	if strings.Contains(content, "\x1b") || strings.Contains(content, "[31m") {
+		t.Error("SVG output contains escape codes")
+	}
+	if !strings.Contains(content, `fill="#cd0000" font-weight="bold">Hot</tspan>`) {
+		t.Error("SVG output missing bold red run")
+	}
+	if !strings.Contains(content, `fill="#0000ee"/>`) {
+		t.Error("SVG output missing background rect")
+	}
+}
+
+func TestToHTMLColors(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "color.html")
+
+	err := ToHTML("\x1b[38;5;46;3;4mGo\x1b[0m <b>", out, "#000000", "#ffffff", "Test")
+	if err != nil {
+		t.Fatalf("ToHTML() error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+
+	content := string(data)
+	want := `<span style="color: #00ff00; font-style: italic; text-decoration: underline">Go</span> &lt;b&gt;`
+	if !strings.Contains(content, want) {
+		t.Errorf("HTML output missing styled span %q", want)
+	}
+}
@@ -0,0 +1,334 @@
+package export
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/gomonobolditalic"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Defaults for PNG rendering
+const (
+	DefaultFontSize = 14.0 // points
+	DefaultDPI      = 72.0
+	DefaultPadding  = 20 // pixels
+)
+
+// PNGOptions configures how text is rendered to images
+type PNGOptions struct {
+	FontSize float64 // Font size in points (0 = DefaultFontSize)
+	DPI      float64 // Resolution the font size is scaled by (0 = DefaultDPI)
+	Padding  int     // Margin around the text in pixels
+}
+
+// DefaultPNGOptions returns the options ToPNG uses
+func DefaultPNGOptions() PNGOptions {
+	return PNGOptions{FontSize: DefaultFontSize, DPI: DefaultDPI, Padding: DefaultPadding}
+}
+
+// FallbackFontPaths are fonts tried, in order, for characters the embedded
+// Go Mono fonts don't have, such as CJK. Missing files are skipped.
+var FallbackFontPaths = []string{
+	// Linux
+	"/usr/share/fonts/truetype/dejavu/DejaVuSansMono.ttf",
+	"/usr/share/fonts/TTF/DejaVuSansMono.ttf",
+	"/usr/share/fonts/dejavu-sans-mono-fonts/DejaVuSansMono.ttf",
+	"/usr/share/fonts/truetype/noto/NotoSansMono-Regular.ttf",
+	"/usr/share/fonts/opentype/noto/NotoSansCJK-Regular.ttc",
+	"/usr/share/fonts/noto-cjk/NotoSansCJK-Regular.ttc",
+	"/usr/share/fonts/google-noto-cjk/NotoSansCJK-Regular.ttc",
+	"/usr/share/fonts/truetype/droid/DroidSansFallbackFull.ttf",
+	"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",
+	// macOS
+	"/System/Library/Fonts/Menlo.ttc",
+	"/System/Library/Fonts/Hiragino Sans GB.ttc",
+	"/System/Library/Fonts/AppleSDGothicNeo.ttc",
+	"/Library/Fonts/Arial Unicode.ttf",
+	"/System/Library/Fonts/Supplemental/Arial Unicode.ttf",
+	// Windows
+	`C:\Windows\Fonts\consola.ttf`,
+	`C:\Windows\Fonts\msgothic.ttc`,
+	`C:\Windows\Fonts\malgun.ttf`,
+	`C:\Windows\Fonts\seguisym.ttf`,
+}
+
+// Go Mono in regular, bold, italic and bold italic, indexed by fontStyle
+var (
+	monoOnce  sync.Once
+	monoFonts [4]*sfnt.Font
+	monoErr   error
+)
+
+// Fallback fonts are only read the first time a character is missing
+var (
+	fallbackOnce  sync.Once
+	fallbackFonts []*sfnt.Font
+)
+
+// loadMonoFonts parses the embedded Go Mono fonts
+func loadMonoFonts() ([4]*sfnt.Font, error) {
+	monoOnce.Do(func() {
+		for i, ttf := range [][]byte{gomono.TTF, gomonobold.TTF, gomonoitalic.TTF, gomonobolditalic.TTF} {
+			if monoFonts[i], monoErr = opentype.Parse(ttf); monoErr != nil {
+				monoErr = fmt.Errorf("failed to parse embedded font: %w", monoErr)
+				return
+			}
+		}
+	})
+	return monoFonts, monoErr
+}
+
+// loadFallbackFonts parses the fallback fonts that exist, taking the first
+// font of collections
+func loadFallbackFonts() []*sfnt.Font {
+	fallbackOnce.Do(func() {
+		for _, path := range FallbackFontPaths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			f, err := opentype.Parse(data)
+			if err != nil {
+				c, cerr := opentype.ParseCollection(data)
+				if cerr != nil {
+					continue
+				}
+				if f, err = c.Font(0); err != nil {
+					continue
+				}
+			}
+			fallbackFonts = append(fallbackFonts, f)
+		}
+	})
+	return fallbackFonts
+}
+
+// fontStyle indexes the Go Mono styles
+func fontStyle(style Style) int {
+	i := 0
+	if style.Bold {
+		i |= 1
+	}
+	if style.Italic {
+		i |= 2
+	}
+	return i
+}
+
+// fontFace is a face and the font it came from, which says which
+// characters it has
+type fontFace struct {
+	font *sfnt.Font
+	face font.Face
+}
+
+// Renderer draws styled text onto images in a grid of character cells.
+// Characters come from the embedded Go Mono fonts; box drawing, block,
+// braille and sextant characters are drawn as shapes that join up across
+// cells, and anything else Go Mono lacks comes from the first fallback
+// font that has it. A Renderer is not safe for concurrent use.
+type Renderer struct {
+	opts      PNGOptions
+	faces     [4]fontFace
+	fallbacks []fontFace // opened on first use
+	opened    bool
+	buf       sfnt.Buffer
+
+	cellWidth, cellHeight int
+	ascent, descent       int
+}
+
+// NewRenderer returns a renderer with the given options
+func NewRenderer(opts PNGOptions) (*Renderer, error) {
+	if opts.FontSize <= 0 {
+		opts.FontSize = DefaultFontSize
+	}
+	if opts.DPI <= 0 {
+		opts.DPI = DefaultDPI
+	}
+	opts.Padding = max(opts.Padding, 0)
+
+	fonts, err := loadMonoFonts()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Renderer{opts: opts}
+	for i, f := range fonts {
+		face, err := r.newFace(f)
+		if err != nil {
+			return nil, err
+		}
+		r.faces[i] = fontFace{font: f, face: face}
+	}
+
+	regular := r.faces[0].face
+	metrics := regular.Metrics()
+	advance, _ := regular.GlyphAdvance('M')
+	r.cellWidth = max(advance.Round(), 1)
+	r.ascent, r.descent = metrics.Ascent.Ceil(), metrics.Descent.Ceil()
+	r.cellHeight = max(metrics.Height.Ceil(), r.ascent+r.descent, 1)
+	return r, nil
+}
+
+func (r *Renderer) newFace(f *sfnt.Font) (font.Face, error) {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    r.opts.FontSize,
+		DPI:     r.opts.DPI,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create font face: %w", err)
+	}
+	return face, nil
+}
+
+// CellSize returns the size of a character cell in pixels
+func (r *Renderer) CellSize() (int, int) {
+	return r.cellWidth, r.cellHeight
+}
+
+// Render draws lines of styled cells on a new image, with the default
+// colors where the cells have none
+func (r *Renderer) Render(lines [][]Cell, bg, fg color.RGBA) *image.RGBA {
+	return r.RenderGrid(lines, maxLineWidth(lines), len(lines), bg, fg)
+}
+
+// RenderGrid is like Render on an image sized for a grid of cols by rows
+// cells, so that frames of different sizes line up
+func (r *Renderer) RenderGrid(lines [][]Cell, cols, rows int, bg, fg color.RGBA) *image.RGBA {
+	pad := r.opts.Padding
+	img := image.NewRGBA(image.Rect(0, 0, cols*r.cellWidth+pad*2, rows*r.cellHeight+pad*2))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	// Cell backgrounds go first, so they don't cover glyphs that overhang
+	// their cells
+	for row, line := range lines {
+		for col, c := range line {
+			if c.Style.HasBg {
+				draw.Draw(img, r.cellRect(row, col, 1), image.NewUniform(c.Style.Bg), image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	for row, line := range lines {
+		for col, c := range line {
+			if c.Char == 0 {
+				continue // the second column of a wide character
+			}
+			span := 1
+			if col+1 < len(line) && line[col+1].Char == 0 {
+				span = 2
+			}
+
+			ink := fg
+			if c.Style.HasFg {
+				ink = c.Style.Fg
+			}
+			cell := r.cellRect(row, col, span)
+			r.drawChar(img, cell, c.Char, ink, c.Style)
+
+			if c.Style.Underline {
+				y := cell.Min.Y + r.baseline() + max(r.descent/2, 1)
+				thickness := max(r.cellWidth/8, 1)
+				draw.Draw(img, image.Rect(cell.Min.X, y, cell.Max.X, y+thickness), image.NewUniform(ink), image.Point{}, draw.Src)
+			}
+		}
+	}
+	return img
+}
+
+// cellRect returns the pixels of span cells starting at a row and column
+func (r *Renderer) cellRect(row, col, span int) image.Rectangle {
+	x := r.opts.Padding + col*r.cellWidth
+	y := r.opts.Padding + row*r.cellHeight
+	return image.Rect(x, y, x+span*r.cellWidth, y+r.cellHeight)
+}
+
+// baseline returns the baseline's offset from the top of a cell, centring
+// the font's ascent and descent in the line height
+func (r *Renderer) baseline() int {
+	return (r.cellHeight-r.ascent-r.descent)/2 + r.ascent
+}
+
+// drawChar draws one character, trying in turn the shapes, Go Mono in the
+// character's style, the fallback fonts and finally an empty box
+func (r *Renderer) drawChar(img *image.RGBA, cell image.Rectangle, ch rune, fg color.RGBA, style Style) {
+	if ch == ' ' {
+		return
+	}
+	if drawShape(img, cell, ch, fg) {
+		return
+	}
+	if f := r.faces[fontStyle(style)]; r.has(f.font, ch) {
+		r.drawGlyph(img, cell, f.face, ch, fg)
+		return
+	}
+	for _, f := range r.fallbackFaces() {
+		if r.has(f.font, ch) {
+			r.drawGlyph(img, cell, f.face, ch, fg)
+			return
+		}
+	}
+	drawMissing(img, cell, fg)
+}
+
+// has reports whether a font has a glyph for a character
+func (r *Renderer) has(f *sfnt.Font, ch rune) bool {
+	index, err := f.GlyphIndex(&r.buf, ch)
+	return err == nil && index != 0
+}
+
+// fallbackFaces returns faces for the fallback fonts at the renderer's size
+func (r *Renderer) fallbackFaces() []fontFace {
+	if !r.opened {
+		r.opened = true
+		for _, f := range loadFallbackFonts() {
+			if face, err := r.newFace(f); err == nil {
+				r.fallbacks = append(r.fallbacks, fontFace{font: f, face: face})
+			}
+		}
+	}
+	return r.fallbacks
+}
+
+// drawGlyph draws a character from a font face centred in its cells
+func (r *Renderer) drawGlyph(img *image.RGBA, cell image.Rectangle, face font.Face, ch rune, fg color.RGBA) {
+	x := cell.Min.X
+	if advance, ok := face.GlyphAdvance(ch); ok {
+		x += (cell.Dx() - advance.Round()) / 2
+	}
+	drawer := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(fg),
+		Face: face,
+		Dot:  fixed.P(x, cell.Min.Y+r.baseline()),
+	}
+	drawer.DrawString(string(ch))
+}
+
+// drawMissing draws the outline box that stands in for a character no font
+// has
+func drawMissing(img *image.RGBA, cell image.Rectangle, fg color.RGBA) {
+	box := cell.Inset(max(cell.Dx()/6, 1))
+	src := image.NewUniform(fg)
+	for _, edge := range []image.Rectangle{
+		image.Rect(box.Min.X, box.Min.Y, box.Max.X, box.Min.Y+1),
+		image.Rect(box.Min.X, box.Max.Y-1, box.Max.X, box.Max.Y),
+		image.Rect(box.Min.X, box.Min.Y, box.Min.X+1, box.Max.Y),
+		image.Rect(box.Max.X-1, box.Min.Y, box.Max.X, box.Max.Y),
+	} {
+		draw.Draw(img, edge, src, image.Point{}, draw.Over)
+	}
+}
@@ -0,0 +1,59 @@
+package export
+
+import "github.com/ddmoney420/moji/internal/ansiart"
+
+// format is an Exporter made of a function
+type format struct {
+	name, description string
+	extensions        []string
+	export            func(text, filename string, opts Options) error
+}
+
+func (f *format) Name() string         { return f.name }
+func (f *format) Description() string  { return f.description }
+func (f *format) Extensions() []string { return f.extensions }
+
+func (f *format) Export(text, filename string, opts Options) error {
+	return f.export(text, filename, opts)
+}
+
+// The built-in formats, in the order they are offered
+func init() {
+	for _, f := range []*format{
+		{"png", "PNG image", []string{".png"}, func(text, filename string, opts Options) error {
+			bg, fg := opts.colors()
+			return ToPNGWithOptions(text, filename, bg, fg, opts.PNG)
+		}},
+		{"svg", "SVG image", []string{".svg"}, func(text, filename string, opts Options) error {
+			bg, fg := opts.colors()
+			return ToSVG(text, filename, bg, fg)
+		}},
+		{"html", "HTML page", []string{".html", ".htm"}, func(text, filename string, opts Options) error {
+			bg, fg := opts.colors()
+			return ToHTML(text, filename, bg, fg, opts.Title)
+		}},
+		{"txt", "Plain text", []string{".txt"}, func(text, filename string, opts Options) error {
+			return ToText(text, filename)
+		}},
+		{"ansi", "Text with its ANSI color escapes", []string{".ansi"}, func(text, filename string, opts Options) error {
+			return ToANSI(text, filename)
+		}},
+		{"ans", "ANSI art with SAUCE", []string{".ans"}, func(text, filename string, opts Options) error {
+			return ansiart.WriteFile(filename, text, ansiart.Sauce{Title: opts.Title, Author: opts.Author, Group: opts.Group})
+		}},
+		{"md", "Markdown code block", []string{".md", ".markdown"}, func(text, filename string, opts Options) error {
+			return ToMarkdown(text, filename)
+		}},
+		{"json", "JSON with text and metadata", []string{".json"}, ToJSON},
+		{"rtf", "Rich Text Format", []string{".rtf"}, func(text, filename string, opts Options) error {
+			bg, fg := opts.colors()
+			return ToRTF(text, filename, bg, fg)
+		}},
+		{"gif", "GIF image", []string{".gif"}, func(text, filename string, opts Options) error {
+			bg, fg := opts.colors()
+			return ToGIF([]string{text}, nil, 0, filename, bg, fg, opts.PNG)
+		}},
+	} {
+		Register(f)
+	}
+}
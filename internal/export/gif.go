@@ -0,0 +1,269 @@
+package export
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"sort"
+)
+
+// defaultDelayMs is the frame delay when none is given
+const defaultDelayMs = 100
+
+// ToGIF exports frames of ASCII art as an animated GIF, each rendered like
+// ToPNGWithOptions on a canvas that fits the largest. delays gives each
+// frame's delay in milliseconds, missing entries repeating the last, and
+// loops is how many times the animation plays (0 = forever).
+//
+// The colors of all frames share one palette, cut down from the most
+// common colors when there are more than 256, and after the first frame
+// only the area that changed is stored.
+func ToGIF(frames []string, delays []int, loops int, filename, bgHex, fgHex string, opts PNGOptions) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("no frames to export")
+	}
+
+	r, err := NewRenderer(opts)
+	if err != nil {
+		return err
+	}
+
+	parsed := make([][][]Cell, len(frames))
+	cols, rows := 0, 0
+	for i, frame := range frames {
+		parsed[i] = ParseANSI(frame)
+		cols = max(cols, maxLineWidth(parsed[i]))
+		rows = max(rows, len(parsed[i]))
+	}
+
+	bg, fg := parseHexColor(bgHex), parseHexColor(fgHex)
+	images := make([]*image.RGBA, len(frames))
+	for i, lines := range parsed {
+		images[i] = r.RenderGrid(lines, cols, rows, bg, fg)
+	}
+
+	palette := buildPalette(images, 256)
+	indexes := make(map[color.RGBA]uint8)
+	anim := &gif.GIF{
+		LoopCount: gifLoopCount(loops),
+		Config: image.Config{
+			ColorModel: palette,
+			Width:      images[0].Bounds().Dx(),
+			Height:     images[0].Bounds().Dy(),
+		},
+	}
+
+	var prev *image.RGBA
+	for i, img := range images {
+		delay := gifDelay(delays, i)
+		area := img.Bounds()
+		if prev != nil {
+			if area = changedArea(prev, img); area.Empty() {
+				// An unchanged frame just holds the previous one longer
+				anim.Delay[len(anim.Delay)-1] += delay
+				continue
+			}
+		}
+		anim.Image = append(anim.Image, toPaletted(img, area, palette, indexes))
+		anim.Delay = append(anim.Delay, delay)
+		anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+		prev = img
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	if err := gif.EncodeAll(f, anim); err != nil {
+		return fmt.Errorf("failed to encode GIF: %w", err)
+	}
+	return nil
+}
+
+// gifLoopCount converts a play count (0 = forever) to the GIF loop count,
+// which counts repeats and uses -1 to play once
+func gifLoopCount(loops int) int {
+	switch {
+	case loops <= 0:
+		return 0
+	case loops == 1:
+		return -1
+	}
+	return loops - 1
+}
+
+// gifDelay returns frame i's delay in hundredths of a second. Browsers
+// treat delays under 2 as 10, so 2 is the shortest.
+func gifDelay(delays []int, i int) int {
+	ms := defaultDelayMs
+	if len(delays) > 0 {
+		ms = delays[min(i, len(delays)-1)]
+	}
+	return max((ms+5)/10, 2)
+}
+
+// changedArea returns the bounds of the pixels that differ between two
+// images of the same size
+func changedArea(a, b *image.RGBA) image.Rectangle {
+	var area image.Rectangle
+	bounds := b.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		rowA := a.Pix[a.PixOffset(bounds.Min.X, y):a.PixOffset(bounds.Max.X, y)]
+		rowB := b.Pix[b.PixOffset(bounds.Min.X, y):b.PixOffset(bounds.Max.X, y)]
+		if string(rowA) == string(rowB) {
+			continue
+		}
+		x0, x1 := 0, len(rowA)/4
+		for x0 < x1 && string(rowA[x0*4:x0*4+4]) == string(rowB[x0*4:x0*4+4]) {
+			x0++
+		}
+		for x1 > x0 && string(rowA[x1*4-4:x1*4]) == string(rowB[x1*4-4:x1*4]) {
+			x1--
+		}
+		area = area.Union(image.Rect(bounds.Min.X+x0, y, bounds.Min.X+x1, y+1))
+	}
+	return area
+}
+
+// toPaletted converts an area of an image to palette colors, caching the
+// nearest palette entry of each color
+func toPaletted(img *image.RGBA, area image.Rectangle, palette color.Palette, indexes map[color.RGBA]uint8) *image.Paletted {
+	out := image.NewPaletted(area, palette)
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			index, ok := indexes[c]
+			if !ok {
+				index = uint8(palette.Index(c))
+				indexes[c] = index
+			}
+			out.SetColorIndex(x, y, index)
+		}
+	}
+	return out
+}
+
+// colorCount is a color and how many pixels have it
+type colorCount struct {
+	c     color.RGBA
+	count int
+}
+
+// buildPalette returns the colors of the images, reduced by median cut
+// when there are more than size of them
+func buildPalette(images []*image.RGBA, size int) color.Palette {
+	counts := make(map[color.RGBA]int)
+	for _, img := range images {
+		for i := 0; i+3 < len(img.Pix); i += 4 {
+			counts[color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], 255}]++
+		}
+	}
+
+	colors := make([]colorCount, 0, len(counts))
+	for c, n := range counts {
+		colors = append(colors, colorCount{c, n})
+	}
+	// Most common first, so the order doesn't depend on map iteration
+	sort.Slice(colors, func(i, j int) bool {
+		if colors[i].count != colors[j].count {
+			return colors[i].count > colors[j].count
+		}
+		return rgbKey(colors[i].c) < rgbKey(colors[j].c)
+	})
+
+	if len(colors) <= size {
+		palette := make(color.Palette, len(colors))
+		for i, cc := range colors {
+			palette[i] = cc.c
+		}
+		return palette
+	}
+
+	boxes := [][]colorCount{colors}
+	for len(boxes) < size {
+		// Split the box with the widest channel range
+		best, bestRange, channel := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			if ch, r := widestChannel(box); r > bestRange {
+				best, bestRange, channel = i, r, ch
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		box := boxes[best]
+		sort.Slice(box, func(i, j int) bool { return channelOf(box[i].c, channel) < channelOf(box[j].c, channel) })
+		total := 0
+		for _, cc := range box {
+			total += cc.count
+		}
+		// Split at the weighted median, keeping both halves non-empty
+		split, seen := 1, 0
+		for i, cc := range box[:len(box)-1] {
+			seen += cc.count
+			if seen*2 >= total {
+				split = i + 1
+				break
+			}
+		}
+		boxes[best] = box[:split]
+		boxes = append(boxes, box[split:])
+	}
+
+	palette := make(color.Palette, len(boxes))
+	for i, box := range boxes {
+		palette[i] = averageColor(box)
+	}
+	return palette
+}
+
+// widestChannel returns the RGB channel with the largest range in a box,
+// and the range
+func widestChannel(box []colorCount) (int, int) {
+	best, bestRange := 0, -1
+	for ch := 0; ch < 3; ch++ {
+		lo, hi := 255, 0
+		for _, cc := range box {
+			v := int(channelOf(cc.c, ch))
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		if hi-lo > bestRange {
+			best, bestRange = ch, hi-lo
+		}
+	}
+	return best, bestRange
+}
+
+func channelOf(c color.RGBA, ch int) uint8 {
+	switch ch {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	}
+	return c.B
+}
+
+func rgbKey(c color.RGBA) int {
+	return int(c.R)<<16 | int(c.G)<<8 | int(c.B)
+}
+
+// averageColor returns the pixel-weighted average of a box
+func averageColor(box []colorCount) color.RGBA {
+	var r, g, b, n int
+	for _, cc := range box {
+		r += int(cc.c.R) * cc.count
+		g += int(cc.c.G) * cc.count
+		b += int(cc.c.B) * cc.count
+		n += cc.count
+	}
+	return color.RGBA{uint8((r + n/2) / n), uint8((g + n/2) / n), uint8((b + n/2) / n), 255}
+}
@@ -0,0 +1,110 @@
+package export
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestToGIF(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "anim.gif")
+
+	frames := []string{"|", "|", "/", "\x1b[31m-\x1b[0m wide"}
+	if err := ToGIF(frames, []int{100, 100, 250}, 3, out, "#000000", "#ffffff", DefaultPNGOptions()); err != nil {
+		t.Fatalf("ToGIF() error: %v", err)
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatalf("Failed to decode GIF: %v", err)
+	}
+
+	// The repeated frame merges into the first
+	if len(anim.Image) != 3 {
+		t.Fatalf("got %d frames, want 3", len(anim.Image))
+	}
+	wantDelays := []int{20, 25, 25}
+	for i, want := range wantDelays {
+		if anim.Delay[i] != want {
+			t.Errorf("frame %d delay = %d, want %d", i, anim.Delay[i], want)
+		}
+	}
+	if anim.LoopCount != 2 {
+		t.Errorf("LoopCount = %d, want 2 (plays 3 times)", anim.LoopCount)
+	}
+
+	// Every frame shares a canvas sized for the widest
+	r, _ := NewRenderer(DefaultPNGOptions())
+	cw, ch := r.CellSize()
+	if anim.Config.Width != 6*cw+2*DefaultPadding || anim.Config.Height != ch+2*DefaultPadding {
+		t.Errorf("canvas = %dx%d, want %dx%d", anim.Config.Width, anim.Config.Height, 6*cw+2*DefaultPadding, ch+2*DefaultPadding)
+	}
+	full := image.Rect(0, 0, anim.Config.Width, anim.Config.Height)
+	if anim.Image[0].Bounds() != full {
+		t.Errorf("first frame bounds = %v, want %v", anim.Image[0].Bounds(), full)
+	}
+	// Later frames only store what changed
+	if b := anim.Image[1].Bounds(); !b.In(full) || b == full {
+		t.Errorf("second frame bounds = %v, want a part of %v", b, full)
+	}
+}
+
+func TestToGIFNoFrames(t *testing.T) {
+	if err := ToGIF(nil, nil, 0, filepath.Join(t.TempDir(), "x.gif"), "#000000", "#ffffff", PNGOptions{}); err == nil {
+		t.Error("ToGIF with no frames should return error")
+	}
+}
+
+func TestGIFLoopCount(t *testing.T) {
+	tests := []struct{ loops, want int }{{0, 0}, {1, -1}, {2, 1}, {5, 4}}
+	for _, tt := range tests {
+		if got := gifLoopCount(tt.loops); got != tt.want {
+			t.Errorf("gifLoopCount(%d) = %d, want %d", tt.loops, got, tt.want)
+		}
+	}
+}
+
+func TestBuildPalette(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(x * 8), uint8(y * 8), 128, 255})
+		}
+	}
+
+	// 1024 colors are cut down to 256
+	palette := buildPalette([]*image.RGBA{img}, 256)
+	if len(palette) != 256 {
+		t.Fatalf("got %d colors, want 256", len(palette))
+	}
+	for _, c := range []color.RGBA{{0, 0, 128, 255}, {248, 248, 128, 255}} {
+		p := palette[palette.Index(c)].(color.RGBA)
+		if diff := max(absDiff(p.R, c.R), absDiff(p.G, c.G), absDiff(p.B, c.B)); diff > 16 {
+			t.Errorf("nearest to %v is %v", c, p)
+		}
+	}
+
+	// Few colors are kept exactly
+	small := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	small.SetRGBA(0, 0, color.RGBA{1, 2, 3, 255})
+	small.SetRGBA(1, 0, color.RGBA{4, 5, 6, 255})
+	if palette := buildPalette([]*image.RGBA{small}, 256); len(palette) != 2 {
+		t.Errorf("got %d colors, want 2", len(palette))
+	}
+}
+
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
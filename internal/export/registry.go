@@ -0,0 +1,122 @@
+package export
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Default colors for exports, from the Dracula theme
+const (
+	DefaultBackground = "#282a36"
+	DefaultForeground = "#f8f8f2"
+)
+
+// Options are the settings exporters share; each format uses those that
+// apply to it
+type Options struct {
+	Background string            // Default background color (hex, "" = DefaultBackground)
+	Foreground string            // Default text color (hex, "" = DefaultForeground)
+	Title      string            // Document title, and the SAUCE title of .ans files
+	Author     string            // SAUCE author of .ans files
+	Group      string            // SAUCE group of .ans files
+	PNG        PNGOptions        // Text rendering for image formats
+	Metadata   map[string]string // Extra details, for formats that keep them such as .json
+}
+
+// colors returns the default colors, filling in any that aren't set
+func (o Options) colors() (bg, fg string) {
+	bg, fg = o.Background, o.Foreground
+	if bg == "" {
+		bg = DefaultBackground
+	}
+	if fg == "" {
+		fg = DefaultForeground
+	}
+	return bg, fg
+}
+
+// Exporter saves terminal text, SGR escapes and all, in one file format
+type Exporter interface {
+	Name() string         // Short name, such as "png"
+	Description() string  // One line for help text
+	Extensions() []string // File extensions with the dot, the usual one first
+	Export(text, filename string, opts Options) error
+}
+
+// The registered exporters in order, and by lower case name and extension
+// without the dot
+var (
+	exporters []Exporter
+	lookup    = make(map[string]Exporter)
+)
+
+// Register adds an exporter, which is then found by its name and its
+// extensions. It panics if another exporter already has one of them.
+func Register(e Exporter) {
+	keys := append([]string{e.Name()}, e.Extensions()...)
+	for _, key := range keys {
+		key = formatKey(key)
+		if existing, ok := lookup[key]; ok && existing != e {
+			panic(fmt.Sprintf("export: %q is already registered by %s", key, existing.Name()))
+		}
+	}
+	for _, key := range keys {
+		lookup[formatKey(key)] = e
+	}
+	exporters = append(exporters, e)
+}
+
+func formatKey(s string) string {
+	return strings.ToLower(strings.TrimPrefix(s, "."))
+}
+
+// Lookup returns the exporter with a name or extension, such as "png" or
+// ".PNG"
+func Lookup(format string) (Exporter, bool) {
+	e, ok := lookup[formatKey(format)]
+	return e, ok
+}
+
+// ForFile returns the exporter for a file's extension
+func ForFile(filename string) (Exporter, bool) {
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		return nil, false
+	}
+	return Lookup(ext)
+}
+
+// Exporters returns the registered exporters in the order they were
+// registered
+func Exporters() []Exporter {
+	return slices.Clone(exporters)
+}
+
+// Names returns the names of the registered exporters
+func Names() []string {
+	names := make([]string, len(exporters))
+	for i, e := range exporters {
+		names[i] = e.Name()
+	}
+	return names
+}
+
+// Save exports text to filename in format, a name or extension, or when
+// format is empty in the format its extension names. A file whose
+// extension no exporter has gets plain text, without escape sequences;
+// the ansi format keeps them.
+func Save(text, filename, format string, opts Options) error {
+	if format != "" {
+		e, ok := Lookup(format)
+		if !ok {
+			return fmt.Errorf("unknown format %q (available: %s)", format, strings.Join(Names(), ", "))
+		}
+		return e.Export(text, filename, opts)
+	}
+	if e, ok := ForFile(filename); ok {
+		return e.Export(text, filename, opts)
+	}
+	return ToText(text, filename)
+}
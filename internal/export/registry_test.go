@@ -0,0 +1,108 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		format, want string
+	}{
+		{"png", "png"},
+		{".PNG", "png"},
+		{"htm", "html"},
+		{".markdown", "md"},
+		{"RTF", "rtf"},
+	}
+	for _, tt := range tests {
+		e, ok := Lookup(tt.format)
+		if !ok || e.Name() != tt.want {
+			t.Errorf("Lookup(%q) = %v, %v; want %s", tt.format, e, ok, tt.want)
+		}
+	}
+	if _, ok := Lookup("doc"); ok {
+		t.Error("Lookup(doc) should find nothing")
+	}
+
+	if e, ok := ForFile("dir.v2/Art.Json"); !ok || e.Name() != "json" {
+		t.Errorf("ForFile(Art.Json) = %v, %v; want json", e, ok)
+	}
+	if _, ok := ForFile("README"); ok {
+		t.Error("ForFile should find nothing without an extension")
+	}
+}
+
+func TestExporters(t *testing.T) {
+	names := Names()
+	for _, want := range []string{"png", "svg", "html", "txt", "ansi", "ans", "md", "json", "rtf", "gif"} {
+		if !strings.Contains(","+strings.Join(names, ",")+",", ","+want+",") {
+			t.Errorf("Names() = %v, missing %s", names, want)
+		}
+	}
+	for _, e := range Exporters() {
+		if len(e.Extensions()) == 0 || !strings.HasPrefix(e.Extensions()[0], ".") || e.Description() == "" {
+			t.Errorf("exporter %s has extensions %v and description %q", e.Name(), e.Extensions(), e.Description())
+		}
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register should panic for an extension that is taken")
+		}
+	}()
+	Register(&format{name: "portable", extensions: []string{".png"}})
+}
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	art := "\033[31mhi\033[0m"
+
+	// Every format writes something
+	for _, e := range Exporters() {
+		path := filepath.Join(dir, "art"+e.Extensions()[0])
+		if err := Save(art, path, "", Options{Title: "hi"}); err != nil {
+			t.Errorf("%s: %v", e.Name(), err)
+			continue
+		}
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("%s: wrote nothing", e.Name())
+		}
+	}
+
+	// --format wins over the extension
+	path := filepath.Join(dir, "art.out")
+	if err := Save(art, path, "txt", Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "hi\n" {
+		t.Errorf("txt format wrote %q", data)
+	}
+
+	// The ansi format keeps the escapes, whatever the extension
+	if err := Save(art, path, "ansi", Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != art+"\n" {
+		t.Errorf("ansi format wrote %q, want %q", data, art+"\n")
+	}
+
+	// Unknown and missing extensions get plain text
+	for _, name := range []string{"art.log", "art"} {
+		path := filepath.Join(dir, name)
+		if err := Save(art, path, "", Options{}); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != "hi\n" {
+			t.Errorf("%s wrote %q, want plain text", name, data)
+		}
+	}
+
+	if err := Save(art, path, "doc", Options{}); err == nil || !strings.Contains(err.Error(), "png") {
+		t.Errorf("Save with an unknown format = %v, want an error listing the formats", err)
+	}
+}
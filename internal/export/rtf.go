@@ -0,0 +1,96 @@
+package export
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+// ToRTF exports ASCII art to Rich Text Format in a monospace font. SGR
+// colors and bold, italic and underline in the text are kept; fgHex and
+// bgHex are the default colors, and lines are padded so the background
+// fills a rectangle.
+func ToRTF(text, filename, bgHex, fgHex string) error {
+	lines := ParseANSI(text)
+	width := maxLineWidth(lines)
+
+	// Color table entries are numbered from 1; the defaults come first
+	colors := []color.RGBA{parseHexColor(fgHex), parseHexColor(bgHex)}
+	index := map[color.RGBA]int{colors[0]: 1, colors[1]: 2}
+	colorIndex := func(c color.RGBA) int {
+		if i, ok := index[c]; ok {
+			return i
+		}
+		colors = append(colors, c)
+		index[c] = len(colors)
+		return len(colors)
+	}
+
+	var body strings.Builder
+	for _, line := range lines {
+		for len(line) < width {
+			line = append(line, Cell{Char: ' '})
+		}
+		for _, r := range runs(line) {
+			fg, bg := 1, 2
+			if r.style.HasFg {
+				fg = colorIndex(r.style.Fg)
+			}
+			if r.style.HasBg {
+				bg = colorIndex(r.style.Bg)
+			}
+			// Background is \cb for most readers, \chcbpat for Word
+			fmt.Fprintf(&body, `{\cf%d\cb%d\highlight%d\chshdng0\chcbpat%d`, fg, bg, bg, bg)
+			if r.style.Bold {
+				body.WriteString(`\b`)
+			}
+			if r.style.Italic {
+				body.WriteString(`\i`)
+			}
+			if r.style.Underline {
+				body.WriteString(`\ul`)
+			}
+			body.WriteString(" " + escapeRTF(r.text) + "}")
+		}
+		body.WriteString("\\line\n")
+	}
+
+	var doc strings.Builder
+	doc.WriteString(`{\rtf1\ansi\deff0{\fonttbl{\f0\fmodern\fcharset0 Courier New;}}` + "\n")
+	doc.WriteString(`{\colortbl;`)
+	for _, c := range colors {
+		fmt.Fprintf(&doc, `\red%d\green%d\blue%d;`, c.R, c.G, c.B)
+	}
+	doc.WriteString("}\n")
+	doc.WriteString(`\pard\sl0\f0\fs20` + "\n")
+	doc.WriteString(body.String())
+	doc.WriteString("}\n")
+
+	if err := os.WriteFile(filename, []byte(doc.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// escapeRTF escapes RTF's special characters and writes anything outside
+// ASCII as \u escapes of its UTF-16 code units, with ? for readers that
+// can't show them
+func escapeRTF(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '{' || r == '}':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case r < 0x80:
+			sb.WriteRune(r)
+		default:
+			for _, unit := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&sb, `\u%d?`, int16(unit))
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,137 @@
+package export
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ddmoney420/moji/internal/ansi"
+	"github.com/mattn/go-runewidth"
+)
+
+// Style holds the SGR attributes of a character. Fg and Bg are only used
+// when HasFg and HasBg are set; otherwise the export's default colors apply.
+type Style struct {
+	Fg, Bg                  color.RGBA
+	HasFg, HasBg            bool
+	Bold, Italic, Underline bool
+}
+
+// Cell is a character and its style. A wide character takes up two
+// cells, the second with Char 0.
+type Cell struct {
+	Char  rune
+	Style Style
+}
+
+// widths measures characters in terminal columns. East Asian ambiguous
+// characters, which include box drawing, count as narrow whatever the
+// locale.
+var widths = &runewidth.Condition{StrictEmojiNeutral: true}
+
+// ParseANSI splits ANSI-colored text into lines of styled cells, one per
+// display column. It understands the 16-color, 256-color and 24-bit SGR colors and bold,
+// italic, underline and inverse; other escape sequences are dropped.
+func ParseANSI(text string) [][]Cell {
+	lines := [][]Cell{nil}
+	var state ansi.Style
+
+	data := []byte(text)
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		i += size
+		switch {
+		case r == 0x1B:
+			esc, n := ansi.ReadEscape(data[i:])
+			if esc.Final == 'm' && !esc.Private {
+				state.ApplySGR(esc.Params)
+			}
+			i += n
+		case r == '\n':
+			lines = append(lines, nil)
+		case r == '\t':
+			line := &lines[len(lines)-1]
+			for stop := (len(*line)/8 + 1) * 8; len(*line) < stop; {
+				*line = append(*line, Cell{Char: ' ', Style: cellStyle(state)})
+			}
+		case r < 0x20 || r == 0x7F:
+			// Other control characters take up no space
+		default:
+			line := &lines[len(lines)-1]
+			switch widths.RuneWidth(r) {
+			case 0:
+				// Zero-width characters, such as combining marks, are dropped
+			case 2:
+				*line = append(*line, Cell{Char: r, Style: cellStyle(state)}, Cell{Style: cellStyle(state)})
+			default:
+				*line = append(*line, Cell{Char: r, Style: cellStyle(state)})
+			}
+		}
+	}
+
+	// Trailing newlines, even with a reset after them, add no lines
+	for len(lines) > 1 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// cellStyle returns the style characters are drawn in, swapping the colors
+// for inverse
+func cellStyle(s ansi.Style) Style {
+	style := Style{Bold: s.Bold, Italic: s.Italic, Underline: s.Underline}
+	if r, g, b, ok := s.Fg.RGB(); ok {
+		style.Fg, style.HasFg = color.RGBA{r, g, b, 255}, true
+	}
+	if r, g, b, ok := s.Bg.RGB(); ok {
+		style.Bg, style.HasBg = color.RGBA{r, g, b, 255}, true
+	}
+	if s.Inverse {
+		style.Fg, style.Bg = style.Bg, style.Fg
+		style.HasFg, style.HasBg = style.HasBg, style.HasFg
+	}
+	return style
+}
+
+// run is a stretch of a line in one style
+type run struct {
+	col   int
+	text  string
+	style Style
+}
+
+// runs groups a line's cells into runs of the same style
+func runs(line []Cell) []run {
+	var out []run
+	var sb strings.Builder
+	start := 0
+	for i, c := range line {
+		if i > 0 && c.Style != line[i-1].Style {
+			out = append(out, run{col: start, text: sb.String(), style: line[i-1].Style})
+			sb.Reset()
+			start = i
+		}
+		if c.Char != 0 {
+			sb.WriteRune(c.Char)
+		}
+	}
+	if len(line) > 0 {
+		out = append(out, run{col: start, text: sb.String(), style: line[len(line)-1].Style})
+	}
+	return out
+}
+
+// hexColor formats a color as #rrggbb
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// maxLineWidth returns the width of the longest line in cells
+func maxLineWidth(lines [][]Cell) int {
+	width := 0
+	for _, line := range lines {
+		width = max(width, len(line))
+	}
+	return width
+}
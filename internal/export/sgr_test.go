@@ -0,0 +1,105 @@
+package export
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParseANSIColors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Style
+	}{
+		{"plain", "x", Style{}},
+		{"16 color", "\x1b[31mx", Style{Fg: color.RGBA{205, 0, 0, 255}, HasFg: true}},
+		{"bright", "\x1b[92mx", Style{Fg: color.RGBA{0, 255, 0, 255}, HasFg: true}},
+		{"background", "\x1b[44mx", Style{Bg: color.RGBA{0, 0, 238, 255}, HasBg: true}},
+		{"256 color", "\x1b[38;5;196mx", Style{Fg: color.RGBA{255, 0, 0, 255}, HasFg: true}},
+		{"truecolor", "\x1b[38;2;1;2;3;48;2;4;5;6mx", Style{
+			Fg: color.RGBA{1, 2, 3, 255}, HasFg: true,
+			Bg: color.RGBA{4, 5, 6, 255}, HasBg: true,
+		}},
+		{"attributes", "\x1b[1;3;4mx", Style{Bold: true, Italic: true, Underline: true}},
+		{"attributes off", "\x1b[1;3;4m\x1b[22;23;24mx", Style{}},
+		{"reset", "\x1b[1;31m\x1b[0mx", Style{}},
+		{"empty reset", "\x1b[1;31m\x1b[mx", Style{}},
+		{"default fg", "\x1b[31;44m\x1b[39mx", Style{Bg: color.RGBA{0, 0, 238, 255}, HasBg: true}},
+		{"inverse", "\x1b[31;7mx", Style{Bg: color.RGBA{205, 0, 0, 255}, HasBg: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := ParseANSI(tt.text)
+			if len(lines) != 1 || len(lines[0]) != 1 {
+				t.Fatalf("ParseANSI(%q) = %v, want one cell", tt.text, lines)
+			}
+			if got := lines[0][0]; got.Char != 'x' || got.Style != tt.want {
+				t.Errorf("ParseANSI(%q) = %+v, want x in %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseANSILines(t *testing.T) {
+	lines := ParseANSI("\x1b[31mab\x1b[0m\n\x1b]8;;https://example.com\x07c\x1b]8;;\x07\x1b[2K\td\n\x1b[0m\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+
+	text := func(line []Cell) string {
+		var s []rune
+		for _, c := range line {
+			s = append(s, c.Char)
+		}
+		return string(s)
+	}
+	if got := text(lines[0]); got != "ab" {
+		t.Errorf("line 0 = %q, want %q", got, "ab")
+	}
+	// Hyperlinks and other escapes are dropped, and tabs expand to spaces
+	if got := text(lines[1]); got != "c       d" {
+		t.Errorf("line 1 = %q, want %q", got, "c       d")
+	}
+	if !lines[0][1].Style.HasFg || lines[1][0].Style.HasFg {
+		t.Error("color should last until the reset")
+	}
+}
+
+func TestRuns(t *testing.T) {
+	got := runs(ParseANSI("ab\x1b[31mcd\x1b[0me")[0])
+	if len(got) != 3 {
+		t.Fatalf("got %d runs, want 3: %+v", len(got), got)
+	}
+	want := []struct {
+		col  int
+		text string
+	}{{0, "ab"}, {2, "cd"}, {4, "e"}}
+	for i, w := range want {
+		if got[i].col != w.col || got[i].text != w.text {
+			t.Errorf("run %d = %d %q, want %d %q", i, got[i].col, got[i].text, w.col, w.text)
+		}
+	}
+	if !got[1].style.HasFg {
+		t.Error("run 1 should be colored")
+	}
+}
+
+func TestParseANSIWidths(t *testing.T) {
+	lines := ParseANSI("a漢─é")
+	var got []rune
+	for _, c := range lines[0] {
+		got = append(got, c.Char)
+	}
+	// The wide character takes two cells and the combining accent none
+	want := []rune{'a', '漢', 0, '─', 'e'}
+	if string(got) != string(want) {
+		t.Errorf("cells = %q, want %q", got, want)
+	}
+	if w := maxLineWidth(lines); w != 5 {
+		t.Errorf("maxLineWidth() = %d, want 5", w)
+	}
+	if r := runs(lines[0]); len(r) != 1 || r[0].text != "a漢─e" {
+		t.Errorf("runs() = %+v, want one run of a漢─e", r)
+	}
+}
@@ -0,0 +1,399 @@
+package export
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Box drawing line weights
+const (
+	boxNone = iota
+	boxLight
+	boxHeavy
+	boxDouble
+)
+
+// Arm directions, clockwise from up
+var (
+	armX = [4]int{0, 1, 0, -1}
+	armY = [4]int{-1, 0, 1, 0}
+)
+
+// boxArms gives the weight of the up, right, down and left arms of each box
+// drawing character from U+2500. Dashed lines, arcs and diagonals are
+// drawn separately.
+var boxArms = [128]string{
+	"0101", "0202", "1010", "2020", "0101", "0202", "1010", "2020", // ─━│┃┄┅┆┇
+	"0101", "0202", "1010", "2020", "0110", "0210", "0120", "0220", // ┈┉┊┋┌┍┎┏
+	"0011", "0012", "0021", "0022", "1100", "1200", "2100", "2200", // ┐┑┒┓└┕┖┗
+	"1001", "1002", "2001", "2002", "1110", "1210", "2110", "1120", // ┘┙┚┛├┝┞┟
+	"2120", "2210", "1220", "2220", "1011", "1012", "2011", "1021", // ┠┡┢┣┤┥┦┧
+	"2021", "2012", "1022", "2022", "0111", "0112", "0211", "0212", // ┨┩┪┫┬┭┮┯
+	"0121", "0122", "0221", "0222", "1101", "1102", "1201", "1202", // ┰┱┲┳┴┵┶┷
+	"2101", "2102", "2201", "2202", "1111", "1112", "1211", "1212", // ┸┹┺┻┼┽┾┿
+	"2111", "1121", "2121", "2112", "2211", "1122", "1221", "2212", // ╀╁╂╃╄╅╆╇
+	"1222", "2122", "2221", "2222", "0101", "0202", "1010", "2020", // ╈╉╊╋╌╍╎╏
+	"0303", "3030", "0310", "0130", "0330", "0013", "0031", "0033", // ═║╒╓╔╕╖╗
+	"1300", "3100", "3300", "1003", "3001", "3003", "1310", "3130", // ╘╙╚╛╜╝╞╟
+	"3330", "1013", "3031", "3033", "0313", "0131", "0333", "1303", // ╠╡╢╣╤╥╦╧
+	"3101", "3303", "1313", "3131", "3333", "0110", "0011", "1001", // ╨╩╪╫╬╭╮╯
+	"1100", "", "", "", "0001", "1000", "0100", "0010", // ╰╱╲╳╴╵╶╷
+	"0002", "2000", "0200", "0020", "0201", "1020", "0102", "2010", // ╸╹╺╻╼╽╾╿
+}
+
+// boxDashes gives the number of dashes in the dashed lines
+var boxDashes = map[rune]int{
+	0x2504: 3, 0x2505: 3, 0x2506: 3, 0x2507: 3,
+	0x2508: 4, 0x2509: 4, 0x250A: 4, 0x250B: 4,
+	0x254C: 2, 0x254D: 2, 0x254E: 2, 0x254F: 2,
+}
+
+// quadrants holds the filled quarters of ▖ to ▟, with top-left=1,
+// top-right=2, bottom-left=4 and bottom-right=8
+var quadrants = [10]int{4, 8, 1, 13, 9, 7, 11, 2, 6, 14}
+
+// brailleDotCells gives the column and row of each braille dot bit
+var brailleDotCells = [8][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {0, 3}, {1, 3}}
+
+// drawShape draws box drawing, block element, braille and sextant
+// characters as shapes filling the cell, so they join up with their
+// neighbours whatever the font. It reports whether ch is one of them.
+func drawShape(img *image.RGBA, cell image.Rectangle, ch rune, fg color.RGBA) bool {
+	s := shape{img: img, cell: cell, fg: fg}
+	switch {
+	case ch >= 0x2500 && ch <= 0x257F:
+		s.box(ch)
+	case ch >= 0x2580 && ch <= 0x259F:
+		s.block(ch)
+	case ch >= 0x2800 && ch <= 0x28FF:
+		s.braille(int(ch - 0x2800))
+	case ch >= 0x1FB00 && ch <= 0x1FB3B:
+		// Sextants skip the patterns of ▌ and ▐
+		bits := int(ch-0x1FB00) + 1
+		if bits >= 21 {
+			bits++
+		}
+		if bits >= 42 {
+			bits++
+		}
+		s.sextant(bits)
+	default:
+		return false
+	}
+	return true
+}
+
+// shape draws into one cell
+type shape struct {
+	img  *image.RGBA
+	cell image.Rectangle
+	fg   color.RGBA
+}
+
+// fill fills part of the cell given as fractions of its width and height
+func (s shape) fill(x0, y0, x1, y1 float64) {
+	s.fillRect(image.Rect(s.x(x0), s.y(y0), s.x(x1), s.y(y1)))
+}
+
+func (s shape) fillRect(r image.Rectangle) {
+	draw.Draw(s.img, r.Intersect(s.cell), image.NewUniform(s.fg), image.Point{}, draw.Over)
+}
+
+// x and y convert fractions of the cell to pixels
+func (s shape) x(f float64) int {
+	return s.cell.Min.X + int(math.Round(f*float64(s.cell.Dx())))
+}
+
+func (s shape) y(f float64) int {
+	return s.cell.Min.Y + int(math.Round(f*float64(s.cell.Dy())))
+}
+
+// light is the thickness of a light line, which double lines space out by
+func (s shape) light() int {
+	return max(int(math.Round(float64(s.cell.Dx())/8)), 1)
+}
+
+// thickness returns the width of a line of a weight
+func (s shape) thickness(weight int) int {
+	switch weight {
+	case boxHeavy:
+		return max(2*s.light(), s.light()+2)
+	case boxLight, boxDouble:
+		return s.light()
+	}
+	return 0
+}
+
+// band returns the pixels a line of the given thickness centred on c covers
+func band(c, thickness int) (int, int) {
+	lo := c - thickness/2
+	return lo, lo + thickness
+}
+
+// block draws the block elements, U+2580 to U+259F
+func (s shape) block(ch rune) {
+	switch {
+	case ch == 0x2580: // ▀
+		s.fill(0, 0, 1, 0.5)
+	case ch <= 0x2588: // ▁ to █, in eighths from the bottom
+		s.fill(0, 1-float64(ch-0x2580)/8, 1, 1)
+	case ch <= 0x258F: // ▉ to ▏, in eighths from the left
+		s.fill(0, 0, float64(0x2590-ch)/8, 1)
+	case ch == 0x2590: // ▐
+		s.fill(0.5, 0, 1, 1)
+	case ch <= 0x2593: // ░ ▒ ▓ as a quarter, half and three quarters of the color
+		alpha := image.NewUniform(color.Alpha{uint8(64 * (ch - 0x2590))})
+		draw.DrawMask(s.img, s.cell, image.NewUniform(s.fg), image.Point{}, alpha, image.Point{}, draw.Over)
+	case ch == 0x2594: // ▔
+		s.fill(0, 0, 1, 1.0/8)
+	case ch == 0x2595: // ▕
+		s.fill(7.0/8, 0, 1, 1)
+	default: // quadrants
+		q := quadrants[ch-0x2596]
+		for i := 0; i < 4; i++ {
+			if q&(1<<i) != 0 {
+				x, y := float64(i%2)/2, float64(i/2)/2
+				s.fill(x, y, x+0.5, y+0.5)
+			}
+		}
+	}
+}
+
+// sextant draws a 2x3 pattern numbered left to right, then top to bottom
+func (s shape) sextant(bits int) {
+	for i := 0; i < 6; i++ {
+		if bits&(1<<i) != 0 {
+			x, y := float64(i%2)/2, float64(i/2)/3
+			s.fill(x, y, x+0.5, y+1.0/3)
+		}
+	}
+}
+
+// braille draws the dots of a braille pattern as circles
+func (s shape) braille(dots int) {
+	w, h := float64(s.cell.Dx()), float64(s.cell.Dy())
+	radius := math.Max(math.Min(w/4, h/8)*0.7, 0.75)
+	for bit, at := range brailleDotCells {
+		if dots&(1<<bit) == 0 {
+			continue
+		}
+		cx := float64(s.cell.Min.X) + w*float64(2*at[0]+1)/4
+		cy := float64(s.cell.Min.Y) + h*float64(2*at[1]+1)/8
+		s.paint(func(px, py float64) float64 {
+			return radius - math.Hypot(px-cx, py-cy)
+		})
+	}
+}
+
+// paint covers the cell with the foreground by a coverage function of the
+// pixel centre, antialiasing across its edge: 0.5 or more inside is full
+// coverage, -0.5 or less none
+func (s shape) paint(inside func(px, py float64) float64) {
+	for y := s.cell.Min.Y; y < s.cell.Max.Y; y++ {
+		for x := s.cell.Min.X; x < s.cell.Max.X; x++ {
+			coverage := math.Min(math.Max(inside(float64(x)+0.5, float64(y)+0.5)+0.5, 0), 1)
+			if coverage > 0 && image.Pt(x, y).In(s.img.Rect) {
+				blend(s.img, x, y, s.fg, coverage)
+			}
+		}
+	}
+}
+
+// blend mixes a color into a pixel by alpha a
+func blend(img *image.RGBA, x, y int, c color.RGBA, a float64) {
+	i := img.PixOffset(x, y)
+	p := img.Pix[i : i+4 : i+4]
+	for j, v := range []uint8{c.R, c.G, c.B, 255} {
+		p[j] = uint8(float64(p[j])*(1-a) + float64(v)*a + 0.5)
+	}
+}
+
+// box draws the box drawing characters, U+2500 to U+257F
+func (s shape) box(ch rune) {
+	cx, cy := (s.cell.Min.X+s.cell.Max.X)/2, (s.cell.Min.Y+s.cell.Max.Y)/2
+
+	switch ch {
+	case 0x2571: // ╱
+		s.diagonal(true)
+		return
+	case 0x2572: // ╲
+		s.diagonal(false)
+		return
+	case 0x2573: // ╳
+		s.diagonal(true)
+		s.diagonal(false)
+		return
+	}
+
+	var arms [4]int
+	for i, c := range boxArms[ch-0x2500] {
+		arms[i] = int(c - '0')
+	}
+
+	if n, ok := boxDashes[ch]; ok {
+		s.dashes(arms, n, cx, cy)
+		return
+	}
+	if ch >= 0x256D && ch <= 0x2570 {
+		s.arc(arms, cx, cy)
+		return
+	}
+	for d := range arms {
+		s.arm(arms, d, cx, cy)
+	}
+}
+
+// arm draws the line or lines of one arm, from the edge of the cell to
+// where it meets the arms across it
+func (s shape) arm(arms [4]int, d, cx, cy int) {
+	weight := arms[d]
+	if weight == boxNone {
+		return
+	}
+
+	horizontal := armY[d] == 0
+	sign := armX[d] + armY[d]
+	center, across := cx, cy
+	if !horizontal {
+		center, across = cy, cx
+	}
+	// The arms to either side, with the direction each lies in across the arm
+	sides := [2]int{(d + 1) % 4, (d + 3) % 4}
+	sideSign := func(side int) int { return armX[side] + armY[side] }
+	gap := 2 * s.light()
+
+	// line draws a line at an offset across the arm, reaching in to the
+	// far side of the band of thickness reach at offset reachAt along it
+	line := func(offset, thickness, reachAt, reach int) {
+		lo, hi := band(across+offset, thickness)
+		rlo, rhi := band(center+sign*reachAt, reach)
+		var from, to int
+		if sign > 0 {
+			from, to = rlo, s.cell.Max.X
+			if !horizontal {
+				to = s.cell.Max.Y
+			}
+		} else {
+			from, to = s.cell.Min.X, rhi
+			if !horizontal {
+				from = s.cell.Min.Y
+			}
+		}
+		if horizontal {
+			s.fillRect(image.Rect(from, lo, to, hi))
+		} else {
+			s.fillRect(image.Rect(lo, from, hi, to))
+		}
+	}
+
+	if weight != boxDouble {
+		// Reach over single lines across, or to the far line of a double
+		reachAt, reach := 0, 0
+		for _, side := range sides {
+			if arms[side] == boxDouble {
+				reachAt, reach = -gap, s.light()
+				break
+			}
+			reach = max(reach, s.thickness(arms[side]))
+		}
+		line(0, s.thickness(weight), reachAt, reach)
+		return
+	}
+
+	// Each line of a double arm stops at the arm on its side, or turns the
+	// corner into the arm opposite when there is none
+	for i, side := range sides {
+		other := sides[1-i]
+		reachAt, reach := 0, 0
+		switch {
+		case arms[side] == boxDouble:
+			reachAt, reach = gap, s.light()
+		case arms[side] != boxNone:
+			reach = s.thickness(arms[side])
+		case arms[other] == boxDouble:
+			reachAt, reach = -gap, s.light()
+		case arms[other] != boxNone:
+			reach = s.thickness(arms[other])
+		}
+		line(sideSign(side)*gap, s.light(), reachAt, reach)
+	}
+}
+
+// dashes draws a dashed horizontal or vertical line of n dashes
+func (s shape) dashes(arms [4]int, n, cx, cy int) {
+	if arms[1] != boxNone {
+		lo, hi := band(cy, s.thickness(arms[1]))
+		length := float64(s.cell.Dx())
+		for i := 0; i < n; i++ {
+			x0 := s.cell.Min.X + int(length*float64(i)/float64(n)+length/float64(4*n))
+			x1 := s.cell.Min.X + int(length*float64(i+1)/float64(n)-length/float64(4*n))
+			s.fillRect(image.Rect(x0, lo, max(x1, x0+1), hi))
+		}
+		return
+	}
+	lo, hi := band(cx, s.thickness(arms[0]))
+	length := float64(s.cell.Dy())
+	for i := 0; i < n; i++ {
+		y0 := s.cell.Min.Y + int(length*float64(i)/float64(n)+length/float64(4*n))
+		y1 := s.cell.Min.Y + int(length*float64(i+1)/float64(n)-length/float64(4*n))
+		s.fillRect(image.Rect(lo, y0, hi, max(y1, y0+1)))
+	}
+}
+
+// arc draws a rounded corner joining its two arms with a quarter circle
+func (s shape) arc(arms [4]int, cx, cy int) {
+	t := s.light()
+	dx, dy := 1, 1 // towards the arms
+	if arms[3] != boxNone {
+		dx = -1
+	}
+	if arms[0] != boxNone {
+		dy = -1
+	}
+
+	// Centres of the lines, as pixel coordinates
+	lx, _ := band(cx, t)
+	ly, _ := band(cy, t)
+	lineX, lineY := float64(lx)+float64(t)/2, float64(ly)+float64(t)/2
+
+	radius := float64(min(s.cell.Dx(), s.cell.Dy())) / 2
+	ox, oy := lineX+float64(dx)*radius, lineY+float64(dy)*radius
+	s.paint(func(px, py float64) float64 {
+		if (px-ox)*float64(dx) > 0 || (py-oy)*float64(dy) > 0 {
+			return -1
+		}
+		return float64(t)/2 - math.Abs(math.Hypot(px-ox, py-oy)-radius)
+	})
+
+	// Straight on from the ends of the arc to the edges
+	xlo, xhi := band(cx, t)
+	ylo, yhi := band(cy, t)
+	if dy > 0 {
+		s.fillRect(image.Rect(xlo, int(oy), xhi, s.cell.Max.Y))
+	} else {
+		s.fillRect(image.Rect(xlo, s.cell.Min.Y, xhi, int(math.Ceil(oy))))
+	}
+	if dx > 0 {
+		s.fillRect(image.Rect(int(ox), ylo, s.cell.Max.X, yhi))
+	} else {
+		s.fillRect(image.Rect(s.cell.Min.X, ylo, int(math.Ceil(ox)), yhi))
+	}
+}
+
+// diagonal draws a line between opposite corners, rising from bottom left
+// to top right or falling from top left to bottom right
+func (s shape) diagonal(rising bool) {
+	x0, y0 := float64(s.cell.Min.X), float64(s.cell.Min.Y)
+	x1, y1 := float64(s.cell.Max.X), float64(s.cell.Max.Y)
+	if rising {
+		y0, y1 = y1, y0
+	}
+	length := math.Hypot(x1-x0, y1-y0)
+	half := float64(s.light()) / 2
+	s.paint(func(px, py float64) float64 {
+		distance := math.Abs((x1-x0)*(py-y0)-(y1-y0)*(px-x0)) / length
+		return half - distance
+	})
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDrawShapeBlocks(t *testing.T) {
+	fg := color.RGBA{255, 255, 255, 255}
+	tests := []struct {
+		ch   rune
+		want image.Rectangle // the filled part of a 10x20 cell
+	}{
+		{'█', image.Rect(0, 0, 10, 20)},
+		{'▀', image.Rect(0, 0, 10, 10)},
+		{'▄', image.Rect(0, 10, 10, 20)},
+		{'▌', image.Rect(0, 0, 5, 20)},
+		{'▐', image.Rect(5, 0, 10, 20)},
+		{'▘', image.Rect(0, 0, 5, 10)},
+		{'▗', image.Rect(5, 10, 10, 20)},
+		{0x1FB00, image.Rect(0, 0, 5, 7)},    // top-left sextant
+		{0x1FB1E, image.Rect(5, 13, 10, 20)}, // bottom-right sextant, past the skipped ▌ pattern
+	}
+
+	for _, tt := range tests {
+		img := image.NewRGBA(image.Rect(0, 0, 10, 20))
+		if !drawShape(img, img.Bounds(), tt.ch, fg) {
+			t.Fatalf("drawShape(%q) = false", tt.ch)
+		}
+		for y := 0; y < 20; y++ {
+			for x := 0; x < 10; x++ {
+				filled := img.RGBAAt(x, y) == fg
+				if want := image.Pt(x, y).In(tt.want); filled != want {
+					t.Errorf("drawShape(%q) pixel (%d, %d) filled = %v, want %v", tt.ch, x, y, filled, want)
+				}
+			}
+		}
+	}
+}
+
+func TestDrawShapeBoxJoins(t *testing.T) {
+	fg := color.RGBA{255, 255, 255, 255}
+	img := image.NewRGBA(image.Rect(0, 0, 16, 32))
+	cell := img.Bounds()
+
+	// ┼ reaches every edge through the centre
+	drawShape(img, cell, '┼', fg)
+	for _, p := range []image.Point{{8, 0}, {8, 31}, {0, 16}, {15, 16}, {8, 16}} {
+		if img.RGBAAt(p.X, p.Y) != fg {
+			t.Errorf("┼ misses %v", p)
+		}
+	}
+
+	// ╔ leaves the inside of the corner open
+	img = image.NewRGBA(cell)
+	drawShape(img, cell, '╔', fg)
+	if img.RGBAAt(8, 16) == fg {
+		t.Error("╔ fills its centre")
+	}
+	if img.RGBAAt(15, 16-4) != fg || img.RGBAAt(15, 16+4) != fg {
+		t.Error("╔ is missing a horizontal line")
+	}
+
+	if drawShape(img, cell, 'A', fg) {
+		t.Error("drawShape('A') = true, want false")
+	}
+}
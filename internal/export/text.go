@@ -0,0 +1,106 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// PlainText returns text without its escape sequences, with tabs expanded
+// and control characters dropped
+func PlainText(text string) string {
+	var sb strings.Builder
+	for i, line := range ParseANSI(text) {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		for _, c := range line {
+			if c.Char != 0 {
+				sb.WriteRune(c.Char)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// ToText exports ASCII art as plain text, without colors
+func ToText(text, filename string) error {
+	if err := os.WriteFile(filename, []byte(PlainText(text)+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// ToANSI exports terminal text as it is, escape sequences and all, for
+// viewing with cat or less -R
+func ToANSI(text, filename string) error {
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if err := os.WriteFile(filename, []byte(text), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// ToMarkdown exports ASCII art as a fenced Markdown code block, the fence
+// longer than any run of backticks in the art
+func ToMarkdown(text, filename string) error {
+	plain := PlainText(text)
+	longest, run := 0, 0
+	for _, r := range plain {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(longest+1, 3))
+
+	doc := fmt.Sprintf("%stext\n%s\n%s\n", fence, plain, fence)
+	if err := os.WriteFile(filename, []byte(doc), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// jsonDocument is what ToJSON writes
+type jsonDocument struct {
+	Title    string            `json:"title,omitempty"`
+	Text     string            `json:"text"`
+	ANSI     string            `json:"ansi,omitempty"` // Only when the text has escapes
+	Width    int               `json:"width"`
+	Height   int               `json:"height"`
+	Created  time.Time         `json:"created"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// ToJSON exports ASCII art as JSON: the plain text, the text with its
+// escapes when it has any, its size in columns and lines, and the title
+// and metadata from opts
+func ToJSON(text, filename string, opts Options) error {
+	lines := ParseANSI(text)
+	doc := jsonDocument{
+		Title:    opts.Title,
+		Text:     PlainText(text),
+		Width:    maxLineWidth(lines),
+		Height:   len(lines),
+		Created:  time.Now().UTC().Truncate(time.Second),
+		Metadata: opts.Metadata,
+	}
+	if strings.ContainsRune(text, 0x1B) {
+		doc.ANSI = text
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
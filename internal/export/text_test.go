@@ -0,0 +1,84 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlainText(t *testing.T) {
+	got := PlainText("\033[1;31mA\033[0m\tB\n世界\n")
+	if want := "A       B\n世界"; got != want {
+		t.Errorf("PlainText = %q, want %q", got, want)
+	}
+}
+
+func TestToMarkdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "art.md")
+	if err := ToMarkdown("\033[32m``` fence\033[0m", path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	// The fence outruns the backticks in the art
+	if want := "````text\n``` fence\n````\n"; string(data) != want {
+		t.Errorf("ToMarkdown wrote %q, want %q", data, want)
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "art.json")
+	art := "\033[31mab\033[0m\nc"
+	if err := ToJSON(art, path, Options{Title: "T", Metadata: map[string]string{"font": "standard"}}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	var doc jsonDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "T" || doc.Text != "ab\nc" || doc.ANSI != art || doc.Width != 2 || doc.Height != 2 {
+		t.Errorf("ToJSON wrote %+v", doc)
+	}
+	if doc.Metadata["font"] != "standard" || doc.Created.IsZero() {
+		t.Errorf("metadata = %v, created = %v", doc.Metadata, doc.Created)
+	}
+
+	// Plain text has no separate ANSI copy
+	ToJSON("plain", path, Options{})
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), `"ansi"`) {
+		t.Errorf("plain text should have no ansi field: %s", data)
+	}
+}
+
+func TestToRTF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "art.rtf")
+	if err := ToRTF("\033[1;38;2;255;0;0m{x}\033[0m\n█", path, "#000000", "#ffffff"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	rtf := string(data)
+
+	for _, want := range []string{
+		`{\rtf1\ansi`,
+		`{\colortbl;\red255\green255\blue255;\red0\green0\blue0;\red255\green0\blue0;}`,
+		`{\cf3\cb2\highlight2\chshdng0\chcbpat2\b \{x\}}`, // red bold, braces escaped
+		`\u9608?`, // █ as a Unicode escape
+		`{\cf1\cb2\highlight2\chshdng0\chcbpat2 \u9608?  }`, // padded to the widest line
+	} {
+		if !strings.Contains(rtf, want) {
+			t.Errorf("RTF is missing %s:\n%s", want, rtf)
+		}
+	}
+	if strings.Count(rtf, "{") != strings.Count(rtf, "}") {
+		t.Errorf("RTF braces don't balance:\n%s", rtf)
+	}
+}
+
+func TestEscapeRTF(t *testing.T) {
+	if got, want := escapeRTF(`a\b😀`), `a\\b\u-10179?\u-8704?`; got != want {
+		t.Errorf("escapeRTF = %q, want %q", got, want)
+	}
+}
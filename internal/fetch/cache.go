@@ -0,0 +1,77 @@
+package fetch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// cacheEntry is a cached download: its metadata is kept as JSON next to
+// the data
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	ContentType  string `json:"content_type"`
+
+	data []byte
+}
+
+// cachePath returns the path of a URL's cache files, without extension
+func cachePath(dir, url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, hex.EncodeToString(sum[:]))
+}
+
+// loadCache returns the cached copy of a URL, or nil when there is none
+func loadCache(dir, url string) *cacheEntry {
+	path := cachePath(dir, url)
+	meta, err := os.ReadFile(path + ".json")
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(meta, &entry); err != nil || entry.URL != url {
+		return nil
+	}
+	if entry.data, err = os.ReadFile(path + ".data"); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// saveCache stores a download. Each file is written to a temporary name
+// and renamed, so a concurrent reader never sees half of it.
+func saveCache(dir string, entry *cacheEntry) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	path := cachePath(dir, entry.URL)
+	if err := writeAtomic(path+".data", entry.data); err != nil {
+		return err
+	}
+	return writeAtomic(path+".json", meta)
+}
+
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,15 @@
+// Package fetch downloads images over HTTP safely and caches them on disk.
+//
+// A Fetcher bounds every download with connect and read timeouts and a maximum size, and checks
+// the data itself (not the server's Content-Type) is an image. For use from a server it can refuse
+// to connect to private, loopback and link-local addresses; the check runs on the address being
+// dialed, so redirects and DNS names pointing inside the network are caught too. With a cache
+// directory, downloads that carry an ETag or Last-Modified date are kept on disk and revalidated
+// with a conditional request, so an unchanged image is not downloaded again.
+//
+// Example usage:
+//
+//	f := fetch.New(fetch.Options{CacheDir: fetch.DefaultCacheDir()})
+//	res, err := f.Fetch(ctx, "https://example.com/cat.png")
+//	img, _, err := image.Decode(bytes.NewReader(res.Data))
+package fetch
@@ -0,0 +1,200 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Defaults for the zero Options fields
+const (
+	DefaultConnectTimeout = 10 * time.Second
+	DefaultReadTimeout    = 30 * time.Second
+	DefaultMaxSize        = 32 << 20 // 32 MiB
+)
+
+// sniffLen is how much of the body content sniffing looks at
+const sniffLen = 512
+
+var (
+	// ErrBlockedAddress is returned when BlockPrivate refuses to connect
+	ErrBlockedAddress = errors.New("address is private, loopback or link-local")
+	// ErrTooLarge is returned when a download exceeds MaxSize
+	ErrTooLarge = errors.New("download exceeds the size limit")
+)
+
+// Options configures a Fetcher
+type Options struct {
+	ConnectTimeout time.Duration // Time to connect, including TLS (0 = DefaultConnectTimeout)
+	ReadTimeout    time.Duration // Time for the whole response (0 = DefaultReadTimeout)
+	MaxSize        int64         // Largest download in bytes (0 = DefaultMaxSize)
+	MIMEPrefix     string        // Sniffed content type the body must have ("" = "image/")
+	BlockPrivate   bool          // Refuse private, loopback and link-local addresses, for use from a server
+	CacheDir       string        // Directory for the on-disk cache ("" = no cache)
+}
+
+// Result is a fetched body
+type Result struct {
+	Data        []byte
+	ContentType string // sniffed from the data, not taken from the server
+	Cached      bool   // served from the cache after the server answered 304 Not Modified
+}
+
+// Fetcher downloads files over HTTP with timeouts, a size limit and
+// content sniffing, caching them on disk
+type Fetcher struct {
+	opts   Options
+	client *http.Client
+}
+
+// DefaultCacheDir returns the cache directory for fetched images under
+// the user cache dir, or "" when there is none
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "moji", "images")
+}
+
+// New returns a fetcher with the given options
+func New(opts Options) *Fetcher {
+	if opts.ConnectTimeout <= 0 {
+		opts.ConnectTimeout = DefaultConnectTimeout
+	}
+	if opts.ReadTimeout <= 0 {
+		opts.ReadTimeout = DefaultReadTimeout
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultMaxSize
+	}
+	if opts.MIMEPrefix == "" {
+		opts.MIMEPrefix = "image/"
+	}
+
+	dialer := &net.Dialer{Timeout: opts.ConnectTimeout}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   opts.ConnectTimeout,
+		ResponseHeaderTimeout: opts.ReadTimeout,
+	}
+	if opts.BlockPrivate {
+		// Checking the address being dialed, after DNS resolution, also
+		// covers redirects and names that resolve to internal hosts. A
+		// proxy would hide the real target, so none is used.
+		dialer.Control = blockPrivate
+		transport.Proxy = nil
+	}
+
+	return &Fetcher{
+		opts:   opts,
+		client: &http.Client{Transport: transport, Timeout: opts.ReadTimeout},
+	}
+}
+
+// blockPrivate is a net.Dialer Control function that refuses internal
+// addresses
+func blockPrivate(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isPrivate(ip) {
+		return fmt.Errorf("%s: %w", host, ErrBlockedAddress)
+	}
+	return nil
+}
+
+// isPrivate reports whether an address is internal to a host or network
+func isPrivate(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// Fetch downloads a URL. With a cache, a cached copy is revalidated with
+// its ETag or Last-Modified date and reused when the server reports it
+// unchanged.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Result, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q (use http or https)", u.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+
+	var cached *cacheEntry
+	if f.opts.CacheDir != "" {
+		if cached = loadCache(f.opts.CacheDir, rawURL); cached != nil {
+			if cached.ETag != "" {
+				req.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				req.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return &Result{Data: cached.data, ContentType: cached.ContentType, Cached: true}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: HTTP %d", rawURL, resp.StatusCode)
+	}
+	if resp.ContentLength > f.opts.MaxSize {
+		return nil, fmt.Errorf("failed to fetch %s: %d bytes: %w", rawURL, resp.ContentLength, ErrTooLarge)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.opts.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	if int64(len(data)) > f.opts.MaxSize {
+		return nil, fmt.Errorf("failed to fetch %s: over %d bytes: %w", rawURL, f.opts.MaxSize, ErrTooLarge)
+	}
+
+	// The server's Content-Type is often wrong or missing, so the data
+	// itself decides
+	contentType := http.DetectContentType(data[:min(len(data), sniffLen)])
+	if !strings.HasPrefix(contentType, f.opts.MIMEPrefix) {
+		return nil, fmt.Errorf("%s is not an image (detected %s)", rawURL, contentType)
+	}
+
+	if f.opts.CacheDir != "" {
+		etag, modified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag != "" || modified != "" {
+			// The cache is an optimisation, so failing to write it is not an error
+			_ = saveCache(f.opts.CacheDir, &cacheEntry{
+				URL:          rawURL,
+				ETag:         etag,
+				LastModified: modified,
+				ContentType:  contentType,
+				data:         data,
+			})
+		}
+	}
+
+	return &Result{Data: data, ContentType: contentType}, nil
+}
@@ -0,0 +1,174 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func pngBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFetchCache(t *testing.T) {
+	data := pngBytes(t)
+	var downloads, revalidations atomic.Int32
+	etag := `"v1"`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			revalidations.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/octet-stream") // wrong on purpose
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	f := New(Options{CacheDir: t.TempDir()})
+	ctx := context.Background()
+
+	res, err := f.Fetch(ctx, srv.URL+"/cat.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Cached || res.ContentType != "image/png" || !bytes.Equal(res.Data, data) {
+		t.Errorf("first fetch = cached %v, type %q, %d bytes", res.Cached, res.ContentType, len(res.Data))
+	}
+
+	res, err = f.Fetch(ctx, srv.URL+"/cat.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Cached || !bytes.Equal(res.Data, data) {
+		t.Error("second fetch should be served from the cache")
+	}
+	if downloads.Load() != 1 || revalidations.Load() != 1 {
+		t.Errorf("downloads = %d, revalidations = %d; want 1 and 1", downloads.Load(), revalidations.Load())
+	}
+
+	// A new ETag downloads again
+	etag = `"v2"`
+	if res, err = f.Fetch(ctx, srv.URL+"/cat.png"); err != nil || res.Cached {
+		t.Errorf("fetch after the ETag changed = cached %v, %v; want a download", res != nil && res.Cached, err)
+	}
+
+	// Without a cache directory every fetch downloads
+	uncached := New(Options{})
+	for i := 0; i < 2; i++ {
+		if res, err := uncached.Fetch(ctx, srv.URL+"/cat.png"); err != nil || res.Cached {
+			t.Errorf("uncached fetch %d = %+v, %v", i, res, err)
+		}
+	}
+}
+
+func TestFetchRejects(t *testing.T) {
+	data := pngBytes(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "image/png") // lies about HTML
+			w.Write([]byte("<!DOCTYPE html><html><body>not an image</body></html>"))
+		case "/big":
+			w.Write(bytes.Repeat(data, 100))
+		case "/big-chunked":
+			// Flushing before the end leaves the length unknown
+			w.Write(data)
+			w.(http.Flusher).Flush()
+			w.Write(bytes.Repeat(data, 100))
+		case "/slow":
+			time.Sleep(500 * time.Millisecond)
+			w.Write(data)
+		case "/missing":
+			http.NotFound(w, r)
+		default:
+			w.Write(data)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	f := New(Options{MaxSize: int64(len(data) * 10), ReadTimeout: 100 * time.Millisecond})
+
+	if _, err := f.Fetch(ctx, srv.URL+"/ok.png"); err != nil {
+		t.Fatalf("plain fetch failed: %v", err)
+	}
+	if _, err := f.Fetch(ctx, srv.URL+"/page"); err == nil || !strings.Contains(err.Error(), "not an image") {
+		t.Errorf("HTML page = %v, want a not-an-image error", err)
+	}
+	for _, path := range []string{"/big", "/big-chunked"} {
+		if _, err := f.Fetch(ctx, srv.URL+path); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%s = %v, want ErrTooLarge", path, err)
+		}
+	}
+	if _, err := f.Fetch(ctx, srv.URL+"/slow"); err == nil {
+		t.Error("a response slower than the read timeout should fail")
+	}
+	if _, err := f.Fetch(ctx, srv.URL+"/missing"); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("missing file = %v, want HTTP 404", err)
+	}
+	if _, err := f.Fetch(ctx, "file:///etc/passwd"); err == nil {
+		t.Error("file URLs should be rejected")
+	}
+}
+
+func TestFetchBlockPrivate(t *testing.T) {
+	data := pngBytes(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	// The test server listens on loopback
+	f := New(Options{BlockPrivate: true})
+	if _, err := f.Fetch(context.Background(), srv.URL); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("loopback fetch = %v, want ErrBlockedAddress", err)
+	}
+	if _, err := f.Fetch(context.Background(), strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)); !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("localhost fetch = %v, want ErrBlockedAddress", err)
+	}
+}
+
+func TestIsPrivate(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1":       true,
+		"10.1.2.3":        true,
+		"192.168.0.1":     true,
+		"172.16.5.4":      true,
+		"169.254.169.254": true,
+		"::1":             true,
+		"fd00::1":         true,
+		"0.0.0.0":         true,
+		"8.8.8.8":         false,
+		"2606:4700::1":    false,
+	} {
+		if got := isPrivate(parseIP(t, addr)); got != want {
+			t.Errorf("isPrivate(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func parseIP(t *testing.T, s string) net.IP {
+	t.Helper()
+	ip := net.ParseIP(s)
+	if ip == nil {
+		t.Fatalf("bad IP %q", s)
+	}
+	return ip
+}
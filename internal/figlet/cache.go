@@ -11,6 +11,7 @@ type cacheEntry struct {
 	hash  uint64
 	font  *Font
 	bytes int
+	elem  *list.Element // Position in the LRU list
 }
 
 // FontCache provides thread-safe LRU caching for parsed fonts
@@ -27,6 +28,16 @@ type CacheStats struct {
 	Hits      int
 	Misses    int
 	Evictions int
+	Size      int // Fonts currently cached
+	Capacity  int // Maximum fonts cached
+}
+
+// HitRate returns the fraction of lookups served from the cache
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
 }
 
 var defaultCache = &FontCache{
@@ -70,7 +81,7 @@ func (c *FontCache) Set(content string, font *Font) {
 	// If entry already exists, update it
 	if entry, exists := c.cache[hash]; exists {
 		entry.font = font
-		c.lru.MoveToFront(c.lru.Front()) // Refresh position
+		c.lru.MoveToFront(entry.elem) // Refresh position
 		return
 	}
 
@@ -80,8 +91,8 @@ func (c *FontCache) Set(content string, font *Font) {
 		font:  font,
 		bytes: len(content),
 	}
+	entry.elem = c.lru.PushFront(hash)
 	c.cache[hash] = entry
-	c.lru.PushFront(hash)
 
 	// Evict least recently used if over capacity
 	if len(c.cache) > c.capacity {
@@ -91,30 +102,35 @@ func (c *FontCache) Set(content string, font *Font) {
 
 // Get retrieves a font from the cache if it exists
 func (c *FontCache) Get(content string) (*Font, bool) {
-	c.mu.RLock()
 	hash := hashContent(content)
-	entry, exists := c.cache[hash]
-	c.mu.RUnlock()
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.cache[hash]
 	if !exists {
-		c.mu.Lock()
 		c.stats.Misses++
-		c.mu.Unlock()
 		return nil, false
 	}
 
-	c.mu.Lock()
 	c.stats.Hits++
-	// Move to front (most recently used)
-	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
-		if elem.Value == hash {
-			c.lru.MoveToFront(elem)
-			break
-		}
+	c.lru.MoveToFront(entry.elem) // Most recently used
+	return entry.font, true
+}
+
+// Parse returns the cached font for content, parsing and caching it on a miss
+func (c *FontCache) Parse(content string) (*Font, error) {
+	if font, found := c.Get(content); found {
+		return font, nil
 	}
-	c.mu.Unlock()
 
-	return entry.font, true
+	font, err := ParseFont(content)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Set(content, font)
+	return font, nil
 }
 
 // evictLRU removes the least recently used entry (must be called with lock held)
@@ -145,6 +161,8 @@ func (c *FontCache) Stats() CacheStats {
 		Hits:      c.stats.Hits,
 		Misses:    c.stats.Misses,
 		Evictions: c.stats.Evictions,
+		Size:      len(c.cache),
+		Capacity:  c.capacity,
 	}
 }
 
@@ -192,19 +210,5 @@ func GetCacheStats() CacheStats {
 
 // ParseFontCached parses a font with LRU caching using the default global cache
 func ParseFontCached(content string) (*Font, error) {
-	// Check cache first
-	if font, found := defaultCache.Get(content); found {
-		return font, nil
-	}
-
-	// Parse if not in cache
-	font, err := ParseFont(content)
-	if err != nil {
-		return nil, err
-	}
-
-	// Store in cache
-	defaultCache.Set(content, font)
-
-	return font, nil
+	return defaultCache.Parse(content)
 }
@@ -182,6 +182,43 @@ func TestCacheEviction(t *testing.T) {
 	}
 }
 
+func TestCacheSetRefreshesLRU(t *testing.T) {
+	cache := NewFontCache(2)
+	font := &Font{Header: Header{Height: 1}}
+
+	cache.Set("content1", font)
+	cache.Set("content2", font)
+	cache.Set("content1", font) // Refresh content1, leaving content2 least recently used
+	cache.Set("content3", font)
+
+	if _, found := cache.Get("content1"); !found {
+		t.Error("expected refreshed content1 to stay cached")
+	}
+	if _, found := cache.Get("content2"); found {
+		t.Error("expected content2 to be evicted")
+	}
+}
+
+func TestCacheParse(t *testing.T) {
+	cache := NewFontCache(2)
+	font1, err := cache.Parse(sampleFontContent)
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	font2, _ := cache.Parse(sampleFontContent)
+	if font1 != font2 {
+		t.Error("expected the second Parse to return the cached font")
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Size != 1 || stats.Capacity != 2 {
+		t.Errorf("Stats() = %+v, want 1 hit, 1 miss, 1/2 cached", stats)
+	}
+	if stats.HitRate() != 0.5 {
+		t.Errorf("HitRate() = %v, want 0.5", stats.HitRate())
+	}
+}
+
 func TestCacheLRUOrdering(t *testing.T) {
 	cache := NewFontCache(2)
 	font1 := &Font{Header: Header{Height: 1}}
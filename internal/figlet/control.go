@@ -0,0 +1,379 @@
+package figlet
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SignatureControl is the first line of a FIGlet control file
+const SignatureControl = "flc2a"
+
+// InputMode selects how input text is decoded into character codes before
+// a control file's mappings are applied
+type InputMode int
+
+const (
+	InputDefault  InputMode = iota // UTF-8, or Latin-1 bytes when the text is not valid UTF-8
+	InputUTF8                      // UTF-8 ("u" command)
+	InputDBCS                      // Double-byte: a byte >= 0x80 starts a two-byte code ("b" command)
+	InputHZ                        // HZ: "~{" and "~}" switch GB 2312 byte pairs on and off ("h" command)
+	InputShiftJIS                  // Shift-JIS, converted to EUC-JP codes ("j" command)
+)
+
+// String returns the input mode name
+func (m InputMode) String() string {
+	switch m {
+	case InputUTF8:
+		return "utf8"
+	case InputDBCS:
+		return "dbcs"
+	case InputHZ:
+		return "hz"
+	case InputShiftJIS:
+		return "shift-jis"
+	default:
+		return "default"
+	}
+}
+
+// charMapping maps the codes lo..hi to lo+offset..hi+offset
+type charMapping struct {
+	lo, hi, offset rune
+}
+
+// Control is a parsed FIGlet control file (.flc). It remaps input
+// characters before glyph lookup, for example to render Cyrillic input with
+// a font that stores Cyrillic glyphs at Latin code points.
+//
+// Mappings are grouped into stages separated by "f" commands. Within a
+// stage the first mapping that matches a character wins; each stage works
+// on the output of the previous one.
+type Control struct {
+	Mode   InputMode
+	stages [][]charMapping
+}
+
+// ParseControl parses a control file. Supported commands are "t" (single
+// characters or equal-length ranges, with figlet's backslash escapes),
+// number pairs, "f" (freeze: start a new stage) and the input mode commands
+// "b", "u", "h" and "j". ISO 2022 "g" commands are accepted but ignored;
+// the default input mode reads UTF-8 or Latin-1 instead.
+func ParseControl(content string) (*Control, error) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	if !scanner.Scan() || !strings.HasPrefix(strings.TrimSpace(scanner.Text()), SignatureControl) {
+		return nil, fmt.Errorf("not a FIGlet control file (missing %s signature)", SignatureControl)
+	}
+
+	c := &Control{stages: [][]charMapping{nil}}
+	lineNum := 1
+	for scanner.Scan() {
+		lineNum++
+		line := decodeLine(strings.TrimRight(scanner.Text(), "\r"), Header{})
+		if line == "" {
+			continue
+		}
+
+		var err error
+		switch cmd := line[0]; {
+		case cmd == '#':
+			continue
+		case cmd == 't':
+			err = c.parseTranslate(line[1:])
+		case cmd == '-' || (cmd >= '0' && cmd <= '9'):
+			err = c.parseNumberPair(line)
+		case cmd == 'f':
+			c.stages = append(c.stages, nil)
+		case cmd == 'b':
+			c.Mode = InputDBCS
+		case cmd == 'u':
+			c.Mode = InputUTF8
+		case cmd == 'h':
+			c.Mode = InputHZ
+		case cmd == 'j':
+			c.Mode = InputShiftJIS
+		}
+		// Like figlet, unknown commands (including "g") are ignored
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading control file: %w", err)
+	}
+	return c, nil
+}
+
+// parseTranslate parses the arguments of a "t" command: "t a-z A-Z" or "t ä ae"
+func (c *Control) parseTranslate(args string) error {
+	r := &controlReader{s: args}
+	fromLo, fromHi, err := r.readRange()
+	if err != nil {
+		return err
+	}
+	toLo, toHi, err := r.readRange()
+	if err != nil {
+		return err
+	}
+	if fromHi-fromLo != toHi-toLo {
+		return fmt.Errorf("ranges %q-%q and %q-%q differ in length", fromLo, fromHi, toLo, toHi)
+	}
+	c.add(fromLo, fromHi, toLo-fromLo)
+	return nil
+}
+
+// parseNumberPair parses a line of two character codes, such as "0xA1 0x0104"
+func (c *Control) parseNumberPair(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return fmt.Errorf("expected two character codes in %q", line)
+	}
+	from, err := parseCodeTag(fields[0])
+	if err != nil {
+		return err
+	}
+	to, err := parseCodeTag(fields[1])
+	if err != nil {
+		return err
+	}
+	c.add(rune(from), rune(from), rune(to-from))
+	return nil
+}
+
+// add appends a mapping to the current stage
+func (c *Control) add(lo, hi, offset rune) {
+	last := len(c.stages) - 1
+	c.stages[last] = append(c.stages[last], charMapping{lo, hi, offset})
+}
+
+// Map applies every stage to a single character code
+func (c *Control) Map(r rune) rune {
+	if c == nil {
+		return r
+	}
+	for _, stage := range c.stages {
+		for _, m := range stage {
+			if r >= m.lo && r <= m.hi {
+				r += m.offset
+				break
+			}
+		}
+	}
+	return r
+}
+
+// Translate decodes text according to the input mode and maps every
+// character. A nil Control returns text unchanged.
+func (c *Control) Translate(text string) string {
+	if c == nil {
+		return text
+	}
+	codes := c.Decode(text)
+	var sb strings.Builder
+	sb.Grow(len(text))
+	for _, r := range codes {
+		sb.WriteRune(c.Map(r))
+	}
+	return sb.String()
+}
+
+// Decode splits text into character codes according to the input mode
+func (c *Control) Decode(text string) []rune {
+	mode := InputDefault
+	if c != nil {
+		mode = c.Mode
+	}
+	switch mode {
+	case InputUTF8:
+		return []rune(text)
+	case InputDBCS:
+		return decodeDBCS(text)
+	case InputHZ:
+		return decodeHZ(text)
+	case InputShiftJIS:
+		return decodeShiftJIS(text)
+	}
+	if utf8.ValidString(text) {
+		return []rune(text)
+	}
+	return []rune(decodeLine(text, Header{}))
+}
+
+// JoinControls combines control files so that each one works on the output
+// of the previous one. The last input mode other than the default wins.
+func JoinControls(controls ...*Control) *Control {
+	joined := &Control{}
+	for _, c := range controls {
+		if c == nil {
+			continue
+		}
+		joined.stages = append(joined.stages, c.stages...)
+		if c.Mode != InputDefault {
+			joined.Mode = c.Mode
+		}
+	}
+	return joined
+}
+
+// decodeDBCS reads a byte >= 0x80 and the byte after it as one code
+func decodeDBCS(text string) []rune {
+	var codes []rune
+	for i := 0; i < len(text); i++ {
+		b := rune(text[i])
+		if b >= 0x80 && i+1 < len(text) {
+			i++
+			b = b<<8 | rune(text[i])
+		}
+		codes = append(codes, b)
+	}
+	return codes
+}
+
+// decodeHZ reads HZ-encoded GB 2312 text. Byte pairs between "~{" and "~}"
+// become EUC codes (both bytes with the high bit set); "~~" is a tilde and
+// "~" before a newline is a line continuation.
+func decodeHZ(text string) []rune {
+	var codes []rune
+	gb := false
+	for i := 0; i < len(text); i++ {
+		b := text[i]
+		if b == '~' && i+1 < len(text) {
+			switch text[i+1] {
+			case '{':
+				gb = true
+				i++
+				continue
+			case '}':
+				gb = false
+				i++
+				continue
+			case '~':
+				codes = append(codes, '~')
+				i++
+				continue
+			case '\n':
+				i++
+				continue
+			}
+		}
+		if gb && b != '\n' && i+1 < len(text) {
+			codes = append(codes, rune(b)<<8|rune(text[i+1])|0x8080)
+			i++
+			continue
+		}
+		codes = append(codes, rune(b))
+	}
+	return codes
+}
+
+// decodeShiftJIS reads Shift-JIS text, converting double-byte characters
+// to EUC-JP codes. Single bytes, including half-width katakana, are kept.
+func decodeShiftJIS(text string) []rune {
+	var codes []rune
+	for i := 0; i < len(text); i++ {
+		c1 := rune(text[i])
+		if !((c1 >= 0x81 && c1 <= 0x9F) || (c1 >= 0xE0 && c1 <= 0xEF)) || i+1 >= len(text) {
+			codes = append(codes, c1)
+			continue
+		}
+		i++
+		c2 := rune(text[i])
+		if c1 <= 0x9F {
+			c1 -= 0x70
+		} else {
+			c1 -= 0xB0
+		}
+		c1 <<= 1
+		if c2 < 0x9F {
+			c1--
+			if c2 > 0x7F {
+				c2 -= 0x20
+			} else {
+				c2 -= 0x1F
+			}
+		} else {
+			c2 -= 0x7E
+		}
+		codes = append(codes, (c1|0x80)<<8|(c2|0x80))
+	}
+	return codes
+}
+
+// controlReader reads the character arguments of a "t" command
+type controlReader struct {
+	s   string
+	pos int
+}
+
+// readRange reads a character or a range of characters ("a" or "a-z")
+func (r *controlReader) readRange() (lo, hi rune, err error) {
+	r.skipSpace()
+	if lo, err = r.readChar(); err != nil {
+		return 0, 0, err
+	}
+	if !strings.HasPrefix(r.s[r.pos:], "-") || r.pos+1 >= len(r.s) || isSpace(r.s[r.pos+1]) {
+		return lo, lo, nil
+	}
+	r.pos++
+	if hi, err = r.readChar(); err != nil {
+		return 0, 0, err
+	}
+	if hi < lo {
+		return 0, 0, fmt.Errorf("range %q-%q is reversed", lo, hi)
+	}
+	return lo, hi, nil
+}
+
+// readChar reads a literal character or a backslash escape: \a \b \e \f
+// \n \r \t \v, a character code (\65, \0x41, \0101, \-1), or any other
+// character after a backslash taken literally (such as "\ " for a space)
+func (r *controlReader) readChar() (rune, error) {
+	if r.pos >= len(r.s) {
+		return 0, fmt.Errorf("missing character in t command")
+	}
+	ch, size := utf8.DecodeRuneInString(r.s[r.pos:])
+	r.pos += size
+	if ch != '\\' {
+		return ch, nil
+	}
+	if r.pos >= len(r.s) {
+		return '\\', nil
+	}
+
+	next := r.s[r.pos]
+	escapes := map[byte]rune{'a': 7, 'b': 8, 'e': 27, 'f': 12, 'n': 10, 'r': 13, 't': 9, 'v': 11}
+	if code, ok := escapes[next]; ok {
+		r.pos++
+		return code, nil
+	}
+	if next == '-' || (next >= '0' && next <= '9') {
+		start := r.pos
+		r.pos++
+		for r.pos < len(r.s) && (isHexDigit(r.s[r.pos]) || r.s[r.pos] == 'x' || r.s[r.pos] == 'X') {
+			r.pos++
+		}
+		code, err := parseCodeTag(r.s[start:r.pos])
+		return rune(code), err
+	}
+	ch, size = utf8.DecodeRuneInString(r.s[r.pos:])
+	r.pos += size
+	return ch, nil
+}
+
+func (r *controlReader) skipSpace() {
+	for r.pos < len(r.s) && isSpace(r.s[r.pos]) {
+		r.pos++
+	}
+}
+
+func isSpace(b byte) bool {
+	return unicode.IsSpace(rune(b))
+}
+
+func isHexDigit(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
@@ -0,0 +1,89 @@
+package figlet
+
+import "testing"
+
+func TestParseControlTranslate(t *testing.T) {
+	c, err := ParseControl("flc2a\n# comment\n\nt a-z A-Z\nt \\  _\nt \\65 \\0x62\n0xE4 0x61 a with diaeresis\n")
+	if err != nil {
+		t.Fatalf("ParseControl() error: %v", err)
+	}
+
+	tests := map[rune]rune{
+		'a': 'A', 'z': 'Z', 'Q': 'Q', // range and unmapped
+		' ': '_', // escaped space
+		'A': 'b', // decimal and hex escapes
+		'ä': 'a', // number pair
+		'1': '1',
+	}
+	for in, want := range tests {
+		if got := c.Map(in); got != want {
+			t.Errorf("Map(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := c.Translate("hi there"); got != "HI_THERE" {
+		t.Errorf("Translate() = %q, want HI_THERE", got)
+	}
+}
+
+func TestParseControlStages(t *testing.T) {
+	// The first matching mapping in a stage wins; after "f" the next stage
+	// sees the output of the previous one
+	c, err := ParseControl("flc2a\nt a b\nt a c\nf\nt b x\n")
+	if err != nil {
+		t.Fatalf("ParseControl() error: %v", err)
+	}
+	if got := c.Map('a'); got != 'x' {
+		t.Errorf("Map('a') = %q, want 'x'", got)
+	}
+
+	joined := JoinControls(c, &Control{Mode: InputUTF8, stages: [][]charMapping{{{'x', 'x', 1}}}})
+	if got := joined.Map('a'); got != 'y' || joined.Mode != InputUTF8 {
+		t.Errorf("joined Map('a') = %q mode %s, want 'y' utf8", got, joined.Mode)
+	}
+}
+
+func TestParseControlErrors(t *testing.T) {
+	tests := []string{
+		"t a-z A-Z\n",          // missing signature
+		"flc2a\nt a-z A-C\n",   // ranges differ in length
+		"flc2a\nt z-a a-z\n",   // reversed range
+		"flc2a\nt a\n",         // missing target
+		"flc2a\n0x41\n",        // one number
+		"flc2a\n0x41 banana\n", // bad number
+	}
+	for _, content := range tests {
+		if _, err := ParseControl(content); err == nil {
+			t.Errorf("ParseControl(%q) should fail", content)
+		}
+	}
+}
+
+func TestControlInputModes(t *testing.T) {
+	tests := []struct {
+		mode InputMode
+		in   string
+		want []rune
+	}{
+		{InputDefault, "é", []rune{0xE9}},
+		{InputDefault, "\xe9t\xe9", []rune{0xE9, 't', 0xE9}}, // Latin-1 bytes
+		{InputUTF8, "ж", []rune{0x436}},
+		{InputDBCS, "a\xb0\xa1", []rune{'a', 0xB0A1}},
+		{InputHZ, "a~{0!~}~~", []rune{'a', 0xB0A1, '~'}},
+		{InputShiftJIS, "\x88\x9fA", []rune{0xB0A1, 'A'}}, // 亜 is EUC-JP B0A1
+	}
+	for _, tt := range tests {
+		got := (&Control{Mode: tt.mode}).Decode(tt.in)
+		if string(got) != string(tt.want) {
+			t.Errorf("%s Decode(%q) = %U, want %U", tt.mode, tt.in, got, tt.want)
+		}
+	}
+
+	c, err := ParseControl("flc2a\nj\n")
+	if err != nil || c.Mode != InputShiftJIS {
+		t.Errorf("j command: mode %v, err %v", c, err)
+	}
+	var nilControl *Control
+	if got := nilControl.Translate("abc"); got != "abc" {
+		t.Errorf("nil Translate() = %q", got)
+	}
+}
@@ -1,7 +1,8 @@
 // Package figlet provides FIGlet font parsing and text rendering.
 //
-// It parses FIGlet font files and renders text using the loaded font definitions with proper
-// character mapping and layout calculations.
+// It parses FIGlet (.flf) and TOIlet (.tlf) font files and renders text using the loaded font
+// definitions with proper character mapping and layout calculations. Control files (.flc) remap
+// input characters before glyph lookup, as figlet's -C option does.
 //
 // Example usage:
 //
@@ -5,22 +5,29 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
-// Font represents a FIGlet font
+// Font represents a FIGlet font. A parsed Font is never modified, so it can
+// render from several goroutines at once.
 type Font struct {
 	Header     Header
+	Comment    string // Comment block (author, history, license)
 	Characters map[rune][]string
 }
 
 // Header contains FIGlet font header information
 type Header struct {
-	HardBlank    rune
-	Height       int
-	Baseline     int
-	MaxLength    int
-	OldLayout    int
-	CommentLines int
+	Signature      string // "flf2a" for FIGlet fonts, "tlf2a" for TOIlet fonts
+	HardBlank      rune
+	Height         int
+	Baseline       int
+	MaxLength      int
+	OldLayout      int
+	CommentLines   int
+	PrintDirection int // 0 = left-to-right, 1 = right-to-left
+	FullLayout     int // Layout bitmask (derived from OldLayout when absent)
+	CodetagCount   int
 }
 
 // ParseFont parses a FIGlet font from string content
@@ -38,11 +45,13 @@ func ParseFont(content string) (*Font, error) {
 		return nil, err
 	}
 
-	// Skip comment lines
+	// Read comment lines
+	comments := make([]string, 0, header.CommentLines)
 	for i := 0; i < header.CommentLines; i++ {
 		if !scanner.Scan() {
 			return nil, fmt.Errorf("unexpected end of file in comments")
 		}
+		comments = append(comments, decodeLine(scanner.Text(), header))
 	}
 
 	// Parse characters
@@ -50,46 +59,151 @@ func ParseFont(content string) (*Font, error) {
 
 	// Standard ASCII characters 32-126
 	for charCode := 32; charCode <= 126; charCode++ {
-		lines := make([]string, 0, header.Height)
-		for h := 0; h < header.Height; h++ {
-			if !scanner.Scan() {
-				// Some fonts don't have all characters
-				break
-			}
-			line := scanner.Text()
-			// Remove end markers (@ or @@)
-			line = strings.TrimRight(line, "@")
-			// Replace hardblank with space for display
-			line = strings.ReplaceAll(line, string(header.HardBlank), " ")
-			lines = append(lines, line)
+		lines, ok := readGlyph(scanner, header)
+		if !ok {
+			// Some fonts don't have all characters
+			break
+		}
+		characters[rune(charCode)] = lines
+	}
+
+	// The seven required Deutsch characters follow. Fonts that don't support
+	// them provide empty glyphs, which are left out so they count as missing.
+	for _, charCode := range deutschChars {
+		lines, ok := readGlyph(scanner, header)
+		if !ok {
+			break
+		}
+		if !isEmptyGlyph(lines) {
+			characters[charCode] = lines
+		}
+	}
+
+	// Code-tagged characters: a line with the character code, then the glyph
+	for scanner.Scan() {
+		tag := strings.TrimSpace(scanner.Text())
+		if tag == "" {
+			continue
 		}
-		if len(lines) == header.Height {
-			characters[rune(charCode)] = lines
+		code, err := parseCodeTag(strings.Fields(tag)[0])
+		if err != nil {
+			break
+		}
+		lines, ok := readGlyph(scanner, header)
+		if !ok {
+			break
+		}
+		// -1 is reserved and may not be used as a character code
+		if code != -1 {
+			characters[rune(code)] = lines
 		}
 	}
 
 	return &Font{
 		Header:     header,
+		Comment:    strings.TrimSpace(strings.Join(comments, "\n")),
 		Characters: characters,
 	}, nil
 }
 
+// deutschChars are the required characters after ASCII: Ä Ö Ü ä ö ü ß
+var deutschChars = []rune{196, 214, 220, 228, 246, 252, 223}
+
+// readGlyph reads one FIGcharacter
+func readGlyph(scanner *bufio.Scanner, header Header) ([]string, bool) {
+	lines := make([]string, 0, header.Height)
+	for h := 0; h < header.Height; h++ {
+		if !scanner.Scan() {
+			return nil, false
+		}
+		lines = append(lines, trimEndmark(decodeLine(scanner.Text(), header)))
+	}
+	return lines, true
+}
+
+// decodeLine returns a glyph line as UTF-8. TOIlet fonts are always UTF-8;
+// FIGlet fonts predate it and often use Latin-1 bytes, so lines that are not
+// valid UTF-8 are decoded as Latin-1.
+func decodeLine(line string, header Header) string {
+	if header.Signature == SignatureTOIlet || utf8.ValidString(line) {
+		return line
+	}
+	runes := make([]rune, len(line))
+	for i := 0; i < len(line); i++ {
+		runes[i] = rune(line[i])
+	}
+	return string(runes)
+}
+
+// isEmptyGlyph reports whether every row of a glyph is empty
+func isEmptyGlyph(lines []string) bool {
+	for _, line := range lines {
+		if line != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCodeTag parses a character code in decimal, octal (leading 0) or
+// hexadecimal (leading 0x) notation, optionally negative
+func parseCodeTag(s string) (int64, error) {
+	neg := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(s, "-")
+
+	base := 10
+	switch {
+	case strings.HasPrefix(digits, "0x"), strings.HasPrefix(digits, "0X"):
+		base = 16
+		digits = digits[2:]
+	case len(digits) > 1 && digits[0] == '0':
+		base = 8
+		digits = digits[1:]
+	}
+
+	code, err := strconv.ParseInt(digits, base, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid character code %q", s)
+	}
+	if neg {
+		code = -code
+	}
+	return code, nil
+}
+
+// Font file signatures
+const (
+	SignatureFIGlet = "flf2a"
+	SignatureTOIlet = "tlf2a"
+)
+
 func parseHeader(line string) (Header, error) {
 	var header Header
 
-	// FIGlet header format: flf2a[hardblank] height baseline maxlen oldlayout commentlines [printdir hardblanksmush fullwidth]
-	if !strings.HasPrefix(line, "flf2a") {
+	// FIGlet header format: flf2a[hardblank] height baseline maxlen oldlayout commentlines [printdir fulllayout codetagcount]
+	// TOIlet fonts use the same format with a tlf2a signature and UTF-8 content
+	switch {
+	case strings.HasPrefix(line, SignatureFIGlet):
+		header.Signature = SignatureFIGlet
+	case strings.HasPrefix(line, SignatureTOIlet):
+		header.Signature = SignatureTOIlet
+	default:
 		return header, fmt.Errorf("invalid FIGlet font header")
 	}
 
-	// The hardblank is the character right after "flf2a"
+	// The hardblank is the character right after the signature, and may be
+	// multi-byte in TOIlet fonts
 	if len(line) < 6 {
 		return header, fmt.Errorf("header too short")
 	}
-	header.HardBlank = rune(line[5])
+	hardblank, size := utf8.DecodeRuneInString(line[5:])
+	if hardblank == utf8.RuneError && header.Signature == SignatureFIGlet {
+		hardblank, size = rune(line[5]), 1
+	}
+	header.HardBlank = hardblank
 
 	// Parse the rest of the header
-	parts := strings.Fields(line[6:])
+	parts := strings.Fields(line[5+size:])
 	if len(parts) < 4 {
 		return header, fmt.Errorf("incomplete header")
 	}
@@ -119,38 +233,50 @@ func parseHeader(line string) (Header, error) {
 		header.CommentLines, _ = strconv.Atoi(parts[4])
 	}
 
-	return header, nil
-}
+	if len(parts) >= 6 {
+		header.PrintDirection, _ = strconv.Atoi(parts[5])
+	}
 
-// Render renders text using the font
-func (f *Font) Render(text string) string {
-	if len(text) == 0 {
-		return ""
+	if len(parts) >= 7 {
+		header.FullLayout, err = strconv.Atoi(parts[6])
+		if err != nil {
+			return header, fmt.Errorf("invalid full layout: %v", err)
+		}
+	} else {
+		header.FullLayout = fullLayoutFromOld(header.OldLayout)
 	}
 
-	// Initialize output lines
-	outputLines := make([]string, f.Header.Height)
+	if len(parts) >= 8 {
+		header.CodetagCount, _ = strconv.Atoi(parts[7])
+	}
 
-	for _, char := range text {
-		charLines, ok := f.Characters[char]
-		if !ok {
-			// Use space for unknown characters
-			charLines = f.Characters[' ']
-			if charLines == nil {
-				// Create empty space
-				charLines = make([]string, f.Header.Height)
-				for i := range charLines {
-					charLines[i] = " "
-				}
-			}
-		}
+	return header, nil
+}
 
-		for i, line := range charLines {
-			if i < len(outputLines) {
-				outputLines[i] += line
-			}
-		}
+// fullLayoutFromOld converts an old-style layout value to the FullLayout bitmask
+func fullLayoutFromOld(old int) int {
+	switch {
+	case old < 0:
+		return 0
+	case old == 0:
+		return HorizontalFitting
+	default:
+		return (old & 31) | HorizontalSmushing
+	}
+}
+
+// trimEndmark strips trailing whitespace and the endmark character(s) from a
+// FIGcharacter line. The endmark is whatever character ends the line, usually @.
+func trimEndmark(line string) string {
+	line = strings.TrimRight(line, " \t")
+	if line == "" {
+		return line
 	}
+	endmark, _ := utf8.DecodeLastRuneInString(line)
+	return strings.TrimRight(line, string(endmark))
+}
 
-	return strings.Join(outputLines, "\n")
+// Render renders text using the font's own layout mode
+func (f *Font) Render(text string) string {
+	return f.RenderLayout(text, LayoutDefault)
 }
@@ -0,0 +1,190 @@
+package figlet
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// buildFont returns a height-1 font with ASCII glyphs, Deutsch glyphs and extra code-tagged lines
+func buildFont(deutsch []string, tagged string) string {
+	var sb strings.Builder
+	sb.WriteString("flf2a$ 1 1 4 -1 1\nTest font\n")
+	for c := 32; c <= 126; c++ {
+		sb.WriteString(string(rune(c)) + "@@\n")
+	}
+	for _, g := range deutsch {
+		sb.WriteString(g + "@@\n")
+	}
+	sb.WriteString(tagged)
+	return sb.String()
+}
+
+func TestParseFontDeutsch(t *testing.T) {
+	font, err := ParseFont(buildFont([]string{"AE", "OE", "UE", "ae", "oe", "", "ss"}, ""))
+	if err != nil {
+		t.Fatalf("ParseFont() error: %v", err)
+	}
+
+	if got := font.Characters['Ä']; len(got) != 1 || got[0] != "AE" {
+		t.Errorf("Ä = %q, want [AE]", got)
+	}
+	if got := font.Characters['ß']; len(got) != 1 || got[0] != "ss" {
+		t.Errorf("ß = %q, want [ss]", got)
+	}
+	if font.HasGlyph('ü') {
+		t.Error("empty Deutsch glyph for ü should be treated as missing")
+	}
+}
+
+func TestParseFontCodeTagged(t *testing.T) {
+	tagged := "160  NO-BREAK SPACE\nnb@@\n" +
+		"0x263A  WHITE SMILING FACE\n:)@@\n" +
+		"0101 octal A-grave\nAo@@\n" +
+		"-2 negative\nneg@@\n" +
+		"-1 reserved\nbad@@\n"
+	font, err := ParseFont(buildFont(make([]string, 7), tagged))
+	if err != nil {
+		t.Fatalf("ParseFont() error: %v", err)
+	}
+
+	tests := map[rune]string{
+		160:    "nb",
+		0x263A: ":)",
+		0101:   "Ao",
+		-2:     "neg",
+	}
+	for code, want := range tests {
+		got, ok := font.Characters[code]
+		if !ok || got[0] != want {
+			t.Errorf("code %d = %q, want %q", code, got, want)
+		}
+	}
+	if _, ok := font.Characters[-1]; ok {
+		t.Error("code -1 is reserved and should not be stored")
+	}
+}
+
+func TestParseCodeTag(t *testing.T) {
+	tests := map[string]int64{
+		"65":      65,
+		"0x41":    65,
+		"0X41":    65,
+		"0101":    65,
+		"-0x10":   -16,
+		"0":       0,
+		"-2":      -2,
+		"0x1F600": 0x1F600,
+	}
+	for in, want := range tests {
+		got, err := parseCodeTag(in)
+		if err != nil || got != want {
+			t.Errorf("parseCodeTag(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if _, err := parseCodeTag("abc"); err == nil {
+		t.Error("parseCodeTag should reject non-numeric tags")
+	}
+}
+
+func TestMissingGlyphStrategies(t *testing.T) {
+	font, err := ParseFont(buildFont(make([]string, 7), "0\n?@@\n"))
+	if err != nil {
+		t.Fatalf("ParseFont() error: %v", err)
+	}
+
+	if got := font.MissingGlyphs("aéa€"); string(got) != "é€" {
+		t.Errorf("MissingGlyphs() = %q, want %q", string(got), "é€")
+	}
+
+	out, err := font.RenderWithOptions("é", RenderOptions{Layout: LayoutFull})
+	if err != nil || out != "?" {
+		t.Errorf("substitute = %q, %v; want code 0 glyph %q", out, err, "?")
+	}
+
+	out, err = font.RenderWithOptions("é€", RenderOptions{Layout: LayoutFull, Missing: MissingTransliterate})
+	if err != nil || out != "eEUR" {
+		t.Errorf("transliterate = %q, %v; want %q", out, err, "eEUR")
+	}
+
+	_, err = font.RenderWithOptions("é", RenderOptions{Missing: MissingError})
+	var missingErr *MissingGlyphError
+	if !errors.As(err, &missingErr) || string(missingErr.Chars) != "é" {
+		t.Errorf("error strategy returned %v, want MissingGlyphError for é", err)
+	}
+}
+
+func TestParseMissingGlyph(t *testing.T) {
+	for _, m := range []MissingGlyph{MissingSubstitute, MissingTransliterate, MissingError} {
+		got, err := ParseMissingGlyph(m.String())
+		if err != nil || got != m {
+			t.Errorf("ParseMissingGlyph(%q) = %v, %v", m.String(), got, err)
+		}
+	}
+	if _, err := ParseMissingGlyph("guess"); err == nil {
+		t.Error("ParseMissingGlyph should reject unknown strategies")
+	}
+}
+
+// buildTOIletFont returns a height-2 TOIlet font with a multi-byte hardblank,
+// block glyphs for A and B and a multi-byte endmark on A
+func buildTOIletFont() string {
+	var sb strings.Builder
+	sb.WriteString("tlf2a▒ 2 2 4 -1 1\nTest TOIlet font\n")
+	for c := 32; c <= 126; c++ {
+		switch c {
+		case ' ':
+			sb.WriteString("▒▒@\n▒▒@@\n")
+		case 'A':
+			sb.WriteString("▄▀▄¤\n█▀█¤¤\n")
+		case 'B':
+			sb.WriteString("█▀▄@\n█▄▀@@\n")
+		default:
+			sb.WriteString(string(rune(c)) + "@\n" + string(rune(c)) + "@@\n")
+		}
+	}
+	return sb.String()
+}
+
+func TestParseFontTOIlet(t *testing.T) {
+	font, err := ParseFont(buildTOIletFont())
+	if err != nil {
+		t.Fatalf("ParseFont() error: %v", err)
+	}
+	if font.Header.Signature != SignatureTOIlet {
+		t.Errorf("Signature = %q, want %q", font.Header.Signature, SignatureTOIlet)
+	}
+	if font.Header.HardBlank != '▒' {
+		t.Errorf("HardBlank = %q, want '▒'", font.Header.HardBlank)
+	}
+	if got := font.Characters['A']; len(got) != 2 || got[1] != "█▀█" {
+		t.Errorf("A = %q, want multi-byte endmark stripped", got)
+	}
+
+	out := font.RenderLayout("A B", LayoutFull)
+	want := "▄▀▄  █▀▄\n█▀█  █▄▀"
+	if out != want {
+		t.Errorf("RenderLayout() =\n%s\nwant\n%s", out, want)
+	}
+
+	// Widths are counted in characters, not bytes
+	out, _ = font.RenderWithOptions("AB AB", RenderOptions{Layout: LayoutFull, Width: 6})
+	want = "▄▀▄█▀▄\n█▀██▄▀\n▄▀▄█▀▄\n█▀██▄▀"
+	if out != want {
+		t.Errorf("wrapped RenderWithOptions() =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestParseFontLatin1(t *testing.T) {
+	// Older FIGlet fonts store non-ASCII glyph characters as Latin-1 bytes
+	font, err := ParseFont(buildFont([]string{"\xc4", "", "", "", "", "", "\xdf"}, ""))
+	if err != nil {
+		t.Fatalf("ParseFont() error: %v", err)
+	}
+	if got := font.Characters['ß']; len(got) != 1 || got[0] != "ß" {
+		t.Errorf("ß = %q, want Latin-1 byte decoded", got)
+	}
+	if got := font.Characters['Ä']; len(got) != 1 || got[0] != "Ä" {
+		t.Errorf("Ä = %q, want Latin-1 byte decoded", got)
+	}
+}
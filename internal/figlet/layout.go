@@ -0,0 +1,363 @@
+package figlet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Horizontal layout bits of the FullLayout header field
+const (
+	RuleEqual          = 1   // Equal character smushing
+	RuleUnderscore     = 2   // Underscore smushing
+	RuleHierarchy      = 4   // Hierarchy smushing
+	RuleOppositePair   = 8   // Opposite pair smushing
+	RuleBigX           = 16  // Big X smushing
+	RuleHardblank      = 32  // Hardblank smushing
+	HorizontalFitting  = 64  // Kerning
+	HorizontalSmushing = 128 // Smushing (universal when no rules are set)
+
+	horizontalRules = 63
+)
+
+// Layout selects how adjacent FIGcharacters are joined horizontally
+type Layout int
+
+const (
+	LayoutDefault Layout = iota // Use the font's own layout
+	LayoutFull                  // Full width, no overlap
+	LayoutKerning               // Move characters together until they touch
+	LayoutSmush                 // Overlap characters by one column using the smushing rules
+)
+
+// String returns the layout name
+func (l Layout) String() string {
+	switch l {
+	case LayoutFull:
+		return "full"
+	case LayoutKerning:
+		return "kerning"
+	case LayoutSmush:
+		return "smush"
+	default:
+		return "default"
+	}
+}
+
+// ParseLayout parses a layout name (default, full, kerning, smush)
+func ParseLayout(name string) (Layout, error) {
+	switch strings.ToLower(name) {
+	case "", "default", "font":
+		return LayoutDefault, nil
+	case "full", "full-width", "fullwidth":
+		return LayoutFull, nil
+	case "kerning", "kern", "fitted", "fitting":
+		return LayoutKerning, nil
+	case "smush", "smushing", "smushed":
+		return LayoutSmush, nil
+	}
+	return LayoutDefault, fmt.Errorf("unknown layout %q (use default, full, kerning, smush)", name)
+}
+
+// ListLayouts returns the available layout names
+func ListLayouts() []string {
+	return []string{"default", "full", "kerning", "smush"}
+}
+
+// HorizontalLayout returns the layout the font uses by default
+func (h Header) HorizontalLayout() Layout {
+	switch {
+	case h.FullLayout&HorizontalSmushing != 0:
+		return LayoutSmush
+	case h.FullLayout&HorizontalFitting != 0:
+		return LayoutKerning
+	default:
+		return LayoutFull
+	}
+}
+
+// SmushRules returns the horizontal smushing rule bits the font defines
+func (h Header) SmushRules() int {
+	return h.FullLayout & horizontalRules
+}
+
+// RenderOptions controls how text is rendered with a font
+type RenderOptions struct {
+	Layout         Layout       // Horizontal layout override
+	VerticalLayout Layout       // How stacked lines are joined
+	Missing        MissingGlyph // What to do with characters the font lacks
+	Width          int          // Wrap to this many columns (0 = no wrapping)
+}
+
+// RenderLayout renders text with the given horizontal layout, stacking
+// lines at full height
+func (f *Font) RenderLayout(text string, layout Layout) string {
+	// Substituting missing glyphs never fails
+	out, _ := f.RenderWithOptions(text, RenderOptions{Layout: layout, VerticalLayout: LayoutFull})
+	return out
+}
+
+// RenderWithOptions renders text with layout, wrapping and missing glyph
+// options. Newlines in text and wrapping produce multiple FIGlines, which are
+// stacked using the vertical layout.
+func (f *Font) RenderWithOptions(text string, opts RenderOptions) (string, error) {
+	if len(text) == 0 {
+		return "", nil
+	}
+
+	runes, err := f.resolveMissing([]rune(text), opts.Missing)
+	if err != nil {
+		return "", err
+	}
+
+	figLines := f.layoutLines(runes, f.smushMode(opts.Layout), opts.Width)
+	rows := f.stackLines(figLines, f.verticalMode(opts.VerticalLayout))
+	return f.joinRows(rows), nil
+}
+
+// smushMode resolves the horizontal layout bits for a layout override
+func (f *Font) smushMode(layout Layout) int {
+	full := f.Header.FullLayout
+	switch layout {
+	case LayoutFull:
+		return 0
+	case LayoutKerning:
+		return HorizontalFitting
+	case LayoutSmush:
+		return (full & horizontalRules) | HorizontalSmushing
+	default:
+		return full & (horizontalRules | HorizontalFitting | HorizontalSmushing)
+	}
+}
+
+// assemble joins the glyphs for text into output rows, keeping hardblanks
+func (f *Font) assemble(text []rune, mode int) [][]rune {
+	if f.Header.PrintDirection == 1 {
+		reversed := make([]rune, len(text))
+		for i, r := range text {
+			reversed[len(text)-1-i] = r
+		}
+		text = reversed
+	}
+
+	rows := make([][]rune, f.Header.Height)
+	prevWidth := 0
+	for _, char := range text {
+		glyph, width := f.glyphRows(char)
+		rows = f.addChar(rows, glyph, width, prevWidth, mode)
+		prevWidth = width
+	}
+	return rows
+}
+
+// glyphRows returns the rows of a character padded to a common width
+func (f *Font) glyphRows(char rune) ([][]rune, int) {
+	charLines, ok := f.Characters[char]
+	if !ok {
+		// Fonts may define code 0 as the glyph for missing characters
+		charLines, ok = f.Characters[0]
+	}
+	if !ok {
+		// Use space for unknown characters
+		charLines = f.Characters[' ']
+	}
+
+	glyph := make([][]rune, f.Header.Height)
+	width := 0
+	for i := range glyph {
+		if charLines == nil {
+			glyph[i] = []rune{' '}
+		} else if i < len(charLines) {
+			glyph[i] = []rune(charLines[i])
+		}
+		if len(glyph[i]) > width {
+			width = len(glyph[i])
+		}
+	}
+	for i, row := range glyph {
+		for len(row) < width {
+			row = append(row, ' ')
+		}
+		glyph[i] = row
+	}
+	return glyph, width
+}
+
+// addChar appends a glyph to the output rows, overlapping as the mode allows
+func (f *Font) addChar(rows, glyph [][]rune, width, prevWidth, mode int) [][]rune {
+	amount := f.smushAmount(rows, glyph, width, prevWidth, mode)
+
+	for i := range rows {
+		line := rows[i]
+		for k := 0; k < amount && k < len(glyph[i]); k++ {
+			idx := len(line) - amount + k
+			if idx < 0 {
+				continue
+			}
+			if ch := f.smushChars(line[idx], glyph[i][k], prevWidth, width, mode); ch != 0 {
+				line[idx] = ch
+			}
+		}
+		if amount < len(glyph[i]) {
+			line = append(line, glyph[i][amount:]...)
+		}
+		rows[i] = line
+	}
+	return rows
+}
+
+// smushAmount returns how many columns the glyph can overlap the output rows
+func (f *Font) smushAmount(rows, glyph [][]rune, width, prevWidth, mode int) int {
+	if mode&(HorizontalFitting|HorizontalSmushing) == 0 {
+		return 0
+	}
+
+	maxSmush := width
+	for i, line := range rows {
+		// Find the last visible character of the output row
+		lineBound := len(line)
+		var left rune
+		for {
+			left = 0
+			if lineBound < len(line) {
+				left = line[lineBound]
+			}
+			if lineBound == 0 || (left != 0 && left != ' ') {
+				break
+			}
+			lineBound--
+		}
+
+		// Find the first visible character of the glyph row
+		charBound := 0
+		for charBound < len(glyph[i]) && glyph[i][charBound] == ' ' {
+			charBound++
+		}
+		var right rune
+		if charBound < len(glyph[i]) {
+			right = glyph[i][charBound]
+		}
+
+		amount := charBound + len(line) - 1 - lineBound
+		if left == 0 || left == ' ' {
+			amount++
+		} else if right != 0 && f.smushChars(left, right, prevWidth, width, mode) != 0 {
+			amount++
+		}
+
+		if amount < maxSmush {
+			maxSmush = amount
+		}
+	}
+	return maxSmush
+}
+
+// smushChars returns the character that results from overlapping left and
+// right, or 0 if they cannot be smushed
+func (f *Font) smushChars(left, right rune, prevWidth, width, mode int) rune {
+	hardblank := f.Header.HardBlank
+
+	if left == ' ' {
+		return right
+	}
+	if right == ' ' {
+		return left
+	}
+
+	// Characters of width 0 or 1 never overlap visible characters
+	if prevWidth < 2 || width < 2 {
+		return 0
+	}
+
+	if mode&HorizontalSmushing == 0 {
+		return 0
+	}
+
+	if mode&horizontalRules == 0 {
+		// Universal smushing: the visible character wins, the later one on ties
+		if left == hardblank {
+			return right
+		}
+		if right == hardblank {
+			return left
+		}
+		return right
+	}
+
+	if mode&RuleHardblank != 0 && left == hardblank && right == hardblank {
+		return left
+	}
+	if left == hardblank || right == hardblank {
+		return 0
+	}
+
+	if mode&RuleEqual != 0 && left == right {
+		return left
+	}
+
+	if mode&RuleUnderscore != 0 {
+		if left == '_' && strings.ContainsRune(`|/\[]{}()<>`, right) {
+			return right
+		}
+		if right == '_' && strings.ContainsRune(`|/\[]{}()<>`, left) {
+			return left
+		}
+	}
+
+	if mode&RuleHierarchy != 0 {
+		if r, ok := smushHierarchy(left, right); ok {
+			return r
+		}
+	}
+
+	if mode&RuleOppositePair != 0 {
+		switch string([]rune{left, right}) {
+		case "[]", "][", "{}", "}{", "()", ")(":
+			return '|'
+		}
+	}
+
+	if mode&RuleBigX != 0 {
+		switch {
+		case left == '/' && right == '\\':
+			return '|'
+		case left == '\\' && right == '/':
+			return 'Y'
+		case left == '>' && right == '<':
+			return 'X'
+		}
+	}
+
+	return 0
+}
+
+// hierarchyClasses lists the hierarchy smushing classes from lowest to highest
+var hierarchyClasses = []string{"|", `/\`, "[]", "{}", "()", "<>"}
+
+// smushHierarchy applies the hierarchy rule: the character from the higher class wins
+func smushHierarchy(left, right rune) (rune, bool) {
+	leftClass, rightClass := -1, -1
+	for i, class := range hierarchyClasses {
+		if strings.ContainsRune(class, left) {
+			leftClass = i
+		}
+		if strings.ContainsRune(class, right) {
+			rightClass = i
+		}
+	}
+	if leftClass < 0 || rightClass < 0 || leftClass == rightClass {
+		return 0, false
+	}
+	if leftClass > rightClass {
+		return left, true
+	}
+	return right, true
+}
+
+// joinRows converts assembled rows to a string, replacing hardblanks with spaces
+func (f *Font) joinRows(rows [][]rune) string {
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		lines[i] = strings.ReplaceAll(string(row), string(f.Header.HardBlank), " ")
+	}
+	return strings.Join(lines, "\n")
+}
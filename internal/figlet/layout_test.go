@@ -0,0 +1,145 @@
+package figlet
+
+import (
+	"strings"
+	"testing"
+)
+
+// layoutTestFont has two-column glyphs that touch, so only smushing overlaps them
+const layoutTestFont = `flf2a$ 2 2 4 15 1 0 191
+Layout test font
+ $@
+ $@@
+|\@
+|/@@
+/|@
+|/@@
+`
+
+// parseLayoutFont parses layoutTestFont, whose glyphs are space, '!' and '"'
+func parseLayoutFont(t *testing.T) *Font {
+	t.Helper()
+	font, err := ParseFont(layoutTestFont)
+	if err != nil {
+		t.Fatalf("ParseFont() error: %v", err)
+	}
+	return font
+}
+
+func TestParseHeaderLayout(t *testing.T) {
+	tests := []struct {
+		line   string
+		full   int
+		layout Layout
+	}{
+		{"flf2a$ 6 5 16 15 13 0 24463 229", 24463, LayoutSmush},
+		{"flf2a$ 8 8 20 -1 1", 0, LayoutFull},
+		{"flf2a$ 7 7 13 0 7", HorizontalFitting, LayoutKerning},
+		{"flf2a$ 6 5 16 15 10", 15 | HorizontalSmushing, LayoutSmush},
+		{"flf2a$ 6 5 16 63 10", 31 | HorizontalSmushing, LayoutSmush},
+	}
+	for _, tt := range tests {
+		h, err := parseHeader(tt.line)
+		if err != nil {
+			t.Fatalf("parseHeader(%q) error: %v", tt.line, err)
+		}
+		if h.FullLayout != tt.full {
+			t.Errorf("parseHeader(%q).FullLayout = %d, want %d", tt.line, h.FullLayout, tt.full)
+		}
+		if got := h.HorizontalLayout(); got != tt.layout {
+			t.Errorf("parseHeader(%q).HorizontalLayout() = %v, want %v", tt.line, got, tt.layout)
+		}
+	}
+}
+
+func TestTrimEndmark(t *testing.T) {
+	tests := map[string]string{
+		"abc@":    "abc",
+		"abc@@":   "abc",
+		"abc##  ": "abc",
+		" $ @":    " $ ",
+		"":        "",
+	}
+	for in, want := range tests {
+		if got := trimEndmark(in); got != want {
+			t.Errorf("trimEndmark(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	for _, name := range ListLayouts() {
+		l, err := ParseLayout(name)
+		if err != nil {
+			t.Errorf("ParseLayout(%q) error: %v", name, err)
+		}
+		if l.String() != name {
+			t.Errorf("ParseLayout(%q).String() = %q", name, l.String())
+		}
+	}
+	if _, err := ParseLayout("sideways"); err == nil {
+		t.Error("ParseLayout should reject unknown layouts")
+	}
+}
+
+func TestRenderLayoutWidths(t *testing.T) {
+	font := parseLayoutFont(t)
+
+	width := func(layout Layout) int {
+		return len([]rune(strings.Split(font.RenderLayout(`!"`, layout), "\n")[0]))
+	}
+
+	full := width(LayoutFull)
+	kern := width(LayoutKerning)
+	smush := width(LayoutSmush)
+
+	if full != 4 {
+		t.Errorf("full width = %d, want 4", full)
+	}
+	if kern != 4 {
+		t.Errorf("kerning width = %d, want 4 (glyphs already touch)", kern)
+	}
+	if smush != 3 {
+		t.Errorf("smush width = %d, want 3", smush)
+	}
+	if got := width(LayoutDefault); got != smush {
+		t.Errorf("default layout width = %d, want font's smush width %d", got, smush)
+	}
+}
+
+func TestSmushRules(t *testing.T) {
+	font := &Font{Header: Header{HardBlank: '$'}}
+	smush := HorizontalSmushing
+
+	tests := []struct {
+		name        string
+		left, right rune
+		mode        int
+		want        rune
+	}{
+		{"equal", '|', '|', smush | RuleEqual, '|'},
+		{"equal disabled", '|', '|', smush | RuleBigX, 0},
+		{"underscore", '_', '/', smush | RuleUnderscore, '/'},
+		{"hierarchy", '|', '{', smush | RuleHierarchy, '{'},
+		{"hierarchy same class", '(', ')', smush | RuleHierarchy, 0},
+		{"opposite pair", '[', ']', smush | RuleOppositePair, '|'},
+		{"big x slash", '/', '\\', smush | RuleBigX, '|'},
+		{"big x backslash", '\\', '/', smush | RuleBigX, 'Y'},
+		{"big x arrows", '>', '<', smush | RuleBigX, 'X'},
+		{"hardblank", '$', '$', smush | RuleHardblank, '$'},
+		{"hardblank blocks", '$', '|', smush | RuleEqual, 0},
+		{"universal", 'a', 'b', smush, 'b'},
+		{"universal hardblank", 'a', '$', smush, 'a'},
+		{"kerning never smushes", '|', '|', HorizontalFitting | RuleEqual, 0},
+		{"blank", ' ', 'x', 0, 'x'},
+	}
+	for _, tt := range tests {
+		if got := font.smushChars(tt.left, tt.right, 2, 2, tt.mode); got != tt.want {
+			t.Errorf("%s: smushChars(%q, %q) = %q, want %q", tt.name, tt.left, tt.right, got, tt.want)
+		}
+	}
+
+	if got := font.smushChars('|', '|', 1, 2, smush|RuleEqual); got != 0 {
+		t.Errorf("narrow characters should not smush, got %q", got)
+	}
+}
@@ -0,0 +1,255 @@
+package figlet
+
+import "strings"
+
+// Vertical layout bits of the FullLayout header field
+const (
+	RuleVEqual          = 256   // Equal character vertical smushing
+	RuleVUnderscore     = 512   // Underscore vertical smushing
+	RuleVHierarchy      = 1024  // Hierarchy vertical smushing
+	RuleVHorizontalLine = 2048  // "-" and "_" smush to "="
+	RuleVVerticalLine   = 4096  // "|" over "|" supersmushing
+	VerticalFitting     = 8192  // Vertical kerning
+	VerticalSmushing    = 16384 // Vertical smushing (universal when no rules are set)
+
+	verticalRules = RuleVEqual | RuleVUnderscore | RuleVHierarchy | RuleVHorizontalLine | RuleVVerticalLine
+)
+
+// verticalMode resolves the vertical layout bits for a layout override
+func (f *Font) verticalMode(layout Layout) int {
+	full := f.Header.FullLayout
+	switch layout {
+	case LayoutFull:
+		return 0
+	case LayoutKerning:
+		return VerticalFitting
+	case LayoutSmush:
+		return (full & verticalRules) | VerticalSmushing
+	default:
+		return full & (verticalRules | VerticalFitting | VerticalSmushing)
+	}
+}
+
+// layoutLines splits text at newlines and wraps each paragraph to width,
+// returning the assembled rows of every FIGline
+func (f *Font) layoutLines(text []rune, mode, width int) [][][]rune {
+	var figLines [][][]rune
+	for _, para := range strings.Split(string(text), "\n") {
+		para = strings.TrimSuffix(para, "\r")
+		for _, line := range f.wrapParagraph([]rune(para), mode, width) {
+			figLines = append(figLines, f.assemble(line, mode))
+		}
+	}
+	return figLines
+}
+
+// lineWidth returns the rendered width of text in columns
+func (f *Font) lineWidth(text []rune, mode int) int {
+	rows := f.assemble(text, mode)
+	if len(rows) == 0 {
+		return 0
+	}
+	return len(rows[0])
+}
+
+// wrapParagraph breaks text into lines that render within width, first at
+// word boundaries and then inside words that are too wide on their own
+func (f *Font) wrapParagraph(text []rune, mode, width int) [][]rune {
+	if width <= 0 || f.lineWidth(text, mode) <= width {
+		return [][]rune{text}
+	}
+
+	var lines [][]rune
+	var current []rune
+	for _, word := range strings.Fields(string(text)) {
+		wordRunes := []rune(word)
+
+		if len(current) > 0 {
+			candidate := append(append(append([]rune{}, current...), ' '), wordRunes...)
+			if f.lineWidth(candidate, mode) <= width {
+				current = candidate
+				continue
+			}
+			lines = append(lines, current)
+			current = nil
+		}
+
+		if f.lineWidth(wordRunes, mode) <= width {
+			current = wordRunes
+			continue
+		}
+
+		pieces := f.breakWord(wordRunes, mode, width)
+		lines = append(lines, pieces[:len(pieces)-1]...)
+		current = pieces[len(pieces)-1]
+	}
+	if len(current) > 0 || len(lines) == 0 {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// breakWord splits a word at character boundaries so each piece fits width.
+// A single character wider than width gets a line of its own.
+func (f *Font) breakWord(word []rune, mode, width int) [][]rune {
+	var pieces [][]rune
+	start := 0
+	for end := 1; end <= len(word); end++ {
+		if end-start > 1 && f.lineWidth(word[start:end], mode) > width {
+			pieces = append(pieces, word[start:end-1])
+			start = end - 1
+		}
+	}
+	return append(pieces, word[start:])
+}
+
+// stackLines joins FIGlines top to bottom, overlapping them as vmode allows.
+// Each FIGline keeps its own width so lines can be aligned independently.
+func (f *Font) stackLines(figLines [][][]rune, vmode int) [][]rune {
+	var out [][]rune
+	for _, rows := range figLines {
+		overlap := f.verticalSmushAmount(out, rows, vmode)
+		top := len(out) - overlap
+		for i := 0; i < overlap; i++ {
+			out[top+i] = f.smushRowsVertical(out[top+i], rows[i], vmode)
+		}
+		out = append(out, rows[overlap:]...)
+	}
+	return out
+}
+
+// verticalSmushAmount returns how many rows of lower can overlap upper
+func (f *Font) verticalSmushAmount(upper, lower [][]rune, vmode int) int {
+	if vmode&(VerticalFitting|VerticalSmushing) == 0 {
+		return 0
+	}
+
+	maxDist := len(upper)
+	if len(lower) < maxDist {
+		maxDist = len(lower)
+	}
+
+	dist := 0
+	for dist < maxDist {
+		next := dist + 1
+		result := verticalValid
+		for i := 0; i < next; i++ {
+			r := f.canSmushRowsVertical(upper[len(upper)-next+i], lower[i], vmode)
+			if r == verticalInvalid {
+				result = verticalInvalid
+				break
+			}
+			if r == verticalEnd {
+				result = verticalEnd
+			}
+		}
+		if result == verticalInvalid {
+			break
+		}
+		dist = next
+		if result == verticalEnd {
+			break
+		}
+	}
+	return dist
+}
+
+// Results of comparing two rows for vertical overlap
+const (
+	verticalValid   = iota // No collisions; overlap may continue
+	verticalEnd            // Collisions smush; overlap stops here
+	verticalInvalid        // Collisions cannot smush
+)
+
+// canSmushRowsVertical reports whether row bottom can overlap row top
+func (f *Font) canSmushRowsVertical(top, bottom []rune, vmode int) int {
+	result := verticalValid
+	for i := 0; i < len(top) && i < len(bottom); i++ {
+		a, b := top[i], bottom[i]
+		if a == ' ' || b == ' ' {
+			continue
+		}
+		if vmode&VerticalSmushing == 0 {
+			return verticalInvalid
+		}
+		if vmode&verticalRules == 0 {
+			return verticalEnd
+		}
+		if vmode&RuleVVerticalLine != 0 && a == '|' && b == '|' {
+			continue
+		}
+		if smushVerticalRules(a, b, vmode) == 0 {
+			return verticalInvalid
+		}
+		result = verticalEnd
+	}
+	return result
+}
+
+// smushRowsVertical merges two overlapping rows column by column
+func (f *Font) smushRowsVertical(top, bottom []rune, vmode int) []rune {
+	width := len(top)
+	if len(bottom) > width {
+		width = len(bottom)
+	}
+
+	merged := make([]rune, width)
+	for i := range merged {
+		a, b := ' ', ' '
+		if i < len(top) {
+			a = top[i]
+		}
+		if i < len(bottom) {
+			b = bottom[i]
+		}
+		if a != ' ' && b != ' ' && vmode&verticalRules != 0 {
+			if ch := smushVerticalRules(a, b, vmode); ch != 0 {
+				merged[i] = ch
+				continue
+			}
+		}
+		merged[i] = f.universalVertical(a, b)
+	}
+	return merged
+}
+
+// universalVertical keeps the lower character unless it is blank
+func (f *Font) universalVertical(top, bottom rune) rune {
+	if bottom == ' ' {
+		return top
+	}
+	if bottom == f.Header.HardBlank && top != ' ' {
+		return top
+	}
+	return bottom
+}
+
+// smushVerticalRules applies the enabled vertical smushing rules, returning 0
+// when none match
+func smushVerticalRules(top, bottom rune, vmode int) rune {
+	if vmode&RuleVEqual != 0 && top == bottom {
+		return top
+	}
+	if vmode&RuleVUnderscore != 0 {
+		if top == '_' && strings.ContainsRune(`|/\[]{}()<>`, bottom) {
+			return bottom
+		}
+		if bottom == '_' && strings.ContainsRune(`|/\[]{}()<>`, top) {
+			return top
+		}
+	}
+	if vmode&RuleVHierarchy != 0 {
+		if r, ok := smushHierarchy(top, bottom); ok {
+			return r
+		}
+	}
+	if vmode&RuleVHorizontalLine != 0 {
+		if (top == '-' && bottom == '_') || (top == '_' && bottom == '-') {
+			return '='
+		}
+	}
+	if vmode&RuleVVerticalLine != 0 && top == '|' && bottom == '|' {
+		return '|'
+	}
+	return 0
+}
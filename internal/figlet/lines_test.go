@@ -0,0 +1,99 @@
+package figlet
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderWrapsWords(t *testing.T) {
+	font, err := ParseFont(buildFont(make([]string, 7), ""))
+	if err != nil {
+		t.Fatalf("ParseFont() error: %v", err)
+	}
+
+	out, err := font.RenderWithOptions("the quick brown fox", RenderOptions{Layout: LayoutFull, Width: 10})
+	if err != nil {
+		t.Fatalf("RenderWithOptions() error: %v", err)
+	}
+	want := "the quick\nbrown fox"
+	if out != want {
+		t.Errorf("wrapped = %q, want %q", out, want)
+	}
+}
+
+func TestRenderBreaksLongWords(t *testing.T) {
+	font, _ := ParseFont(buildFont(make([]string, 7), ""))
+
+	out, _ := font.RenderWithOptions("abcdefgh ij", RenderOptions{Layout: LayoutFull, Width: 3})
+	want := "abc\ndef\ngh\nij"
+	if out != want {
+		t.Errorf("broken = %q, want %q", out, want)
+	}
+}
+
+func TestRenderExplicitNewlines(t *testing.T) {
+	font, _ := ParseFont(buildFont(make([]string, 7), ""))
+
+	out, _ := font.RenderWithOptions("ab\n\ncd", RenderOptions{Layout: LayoutFull, VerticalLayout: LayoutFull})
+	if out != "ab\n\ncd" {
+		t.Errorf("newlines = %q, want %q", out, "ab\n\ncd")
+	}
+}
+
+func TestRenderNoWrapWhenFits(t *testing.T) {
+	font, _ := ParseFont(buildFont(make([]string, 7), ""))
+
+	out, _ := font.RenderWithOptions("a  b", RenderOptions{Layout: LayoutFull, Width: 10})
+	if out != "a  b" {
+		t.Errorf("text that fits should be untouched, got %q", out)
+	}
+}
+
+func TestStackLinesVertical(t *testing.T) {
+	font := &Font{Header: Header{HardBlank: '$'}}
+	upper := [][]rune{[]rune(" _ "), []rune("| |"), []rune("   ")}
+	lower := [][]rune{[]rune("   "), []rune("|_|")}
+
+	join := func(rows [][]rune) string {
+		lines := make([]string, len(rows))
+		for i, r := range rows {
+			lines[i] = string(r)
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	full := font.stackLines([][][]rune{upper, lower}, 0)
+	if len(full) != 5 {
+		t.Errorf("full height stack has %d rows, want 5", len(full))
+	}
+
+	fitted := font.stackLines([][][]rune{upper, lower}, VerticalFitting)
+	if got := join(fitted); got != " _ \n| |\n|_|" {
+		t.Errorf("fitted = %q", got)
+	}
+
+	smushed := font.stackLines([][][]rune{upper, {[]rune("|_|")}}, VerticalSmushing|RuleVEqual)
+	if got := join(smushed); got != " _ \n| |\n|_|" {
+		t.Errorf("smushed = %q", got)
+	}
+}
+
+func TestSmushVerticalRules(t *testing.T) {
+	tests := []struct {
+		top, bottom rune
+		mode        int
+		want        rune
+	}{
+		{'|', '|', RuleVEqual, '|'},
+		{'_', '|', RuleVUnderscore, '|'},
+		{'|', '/', RuleVHierarchy, '/'},
+		{'-', '_', RuleVHorizontalLine, '='},
+		{'|', '|', RuleVVerticalLine, '|'},
+		{'a', 'b', verticalRules, 0},
+	}
+	for _, tt := range tests {
+		if got := smushVerticalRules(tt.top, tt.bottom, tt.mode); got != tt.want {
+			t.Errorf("smushVerticalRules(%q, %q, %d) = %q, want %q", tt.top, tt.bottom, tt.mode, got, tt.want)
+		}
+	}
+}
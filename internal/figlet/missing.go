@@ -0,0 +1,128 @@
+package figlet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MissingGlyph selects what happens to characters the font has no glyph for
+type MissingGlyph int
+
+const (
+	MissingSubstitute    MissingGlyph = iota // Use the font's code 0 glyph, or a blank
+	MissingTransliterate                     // Replace with an ASCII approximation, then substitute
+	MissingError                             // Fail with a MissingGlyphError
+)
+
+// String returns the strategy name
+func (m MissingGlyph) String() string {
+	switch m {
+	case MissingTransliterate:
+		return "transliterate"
+	case MissingError:
+		return "error"
+	default:
+		return "substitute"
+	}
+}
+
+// ParseMissingGlyph parses a missing glyph strategy name
+func ParseMissingGlyph(name string) (MissingGlyph, error) {
+	switch strings.ToLower(name) {
+	case "", "substitute", "sub":
+		return MissingSubstitute, nil
+	case "transliterate", "translit":
+		return MissingTransliterate, nil
+	case "error", "fail":
+		return MissingError, nil
+	}
+	return MissingSubstitute, fmt.Errorf("unknown missing glyph strategy %q (use substitute, transliterate, error)", name)
+}
+
+// MissingGlyphError reports characters a font cannot render
+type MissingGlyphError struct {
+	Chars []rune
+}
+
+// Error implements the error interface
+func (e *MissingGlyphError) Error() string {
+	quoted := make([]string, len(e.Chars))
+	for i, r := range e.Chars {
+		quoted[i] = fmt.Sprintf("%q (U+%04X)", r, r)
+	}
+	return "font has no glyph for " + strings.Join(quoted, ", ")
+}
+
+// HasGlyph reports whether the font defines a glyph for r
+func (f *Font) HasGlyph(r rune) bool {
+	_, ok := f.Characters[r]
+	return ok
+}
+
+// MissingGlyphs returns the distinct characters in text the font has no glyph for
+func (f *Font) MissingGlyphs(text string) []rune {
+	var missing []rune
+	seen := make(map[rune]bool)
+	for _, r := range text {
+		if r == '\n' || seen[r] || f.HasGlyph(r) {
+			continue
+		}
+		seen[r] = true
+		missing = append(missing, r)
+	}
+	return missing
+}
+
+// resolveMissing applies the missing glyph strategy to text
+func (f *Font) resolveMissing(text []rune, strategy MissingGlyph) ([]rune, error) {
+	switch strategy {
+	case MissingError:
+		if missing := f.MissingGlyphs(string(text)); len(missing) > 0 {
+			return nil, &MissingGlyphError{Chars: missing}
+		}
+	case MissingTransliterate:
+		out := make([]rune, 0, len(text))
+		for _, r := range text {
+			if !f.HasGlyph(r) {
+				if repl, ok := transliterations[r]; ok {
+					out = append(out, []rune(repl)...)
+					continue
+				}
+			}
+			out = append(out, r)
+		}
+		return out, nil
+	}
+	return text, nil
+}
+
+// transliterations maps common non-ASCII characters to ASCII approximations
+var transliterations = map[rune]string{
+	// Latin-1 Supplement
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "Ae", 'Å': "A", 'Æ': "AE", 'Ç': "C",
+	'È': "E", 'É': "E", 'Ê': "E", 'Ë': "E", 'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I",
+	'Ð': "D", 'Ñ': "N", 'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "Oe", 'Ø': "O",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "Ue", 'Ý': "Y", 'Þ': "Th", 'ß': "ss",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "ae", 'å': "a", 'æ': "ae", 'ç': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ð': "d", 'ñ': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "oe", 'ø': "o",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "ue", 'ý': "y", 'þ': "th", 'ÿ': "y",
+	'¡': "!", '¿': "?", '«': "<<", '»': ">>", '×': "x", '÷': "/", '©': "(c)", '®': "(R)",
+	'°': "o", '±': "+-", '·': ".", ' ': " ",
+
+	// Latin Extended-A
+	'Ā': "A", 'ā': "a", 'Ă': "A", 'ă': "a", 'Ą': "A", 'ą': "a", 'Ć': "C", 'ć': "c",
+	'Č': "C", 'č': "c", 'Ď': "D", 'ď': "d", 'Đ': "D", 'đ': "d", 'Ē': "E", 'ē': "e",
+	'Ė': "E", 'ė': "e", 'Ę': "E", 'ę': "e", 'Ě': "E", 'ě': "e", 'Ğ': "G", 'ğ': "g",
+	'Ī': "I", 'ī': "i", 'Į': "I", 'į': "i", 'İ': "I", 'ı': "i", 'Ķ': "K", 'ķ': "k",
+	'Ĺ': "L", 'ĺ': "l", 'Ľ': "L", 'ľ': "l", 'Ł': "L", 'ł': "l", 'Ń': "N", 'ń': "n",
+	'Ň': "N", 'ň': "n", 'Ō': "O", 'ō': "o", 'Ő': "O", 'ő': "o", 'Œ': "OE", 'œ': "oe",
+	'Ŕ': "R", 'ŕ': "r", 'Ř': "R", 'ř': "r", 'Ś': "S", 'ś': "s", 'Ş': "S", 'ş': "s",
+	'Š': "S", 'š': "s", 'Ţ': "T", 'ţ': "t", 'Ť': "T", 'ť': "t", 'Ū': "U", 'ū': "u",
+	'Ů': "U", 'ů': "u", 'Ű': "U", 'ű': "u", 'Ų': "U", 'ų': "u", 'Ÿ': "Y", 'Ź': "Z",
+	'ź': "z", 'Ż': "Z", 'ż': "z", 'Ž': "Z", 'ž': "z",
+
+	// Punctuation
+	'‘': "'", '’': "'", '‚': ",", '“': "\"", '”': "\"", '„': "\"", '–': "-", '—': "-",
+	'…': "...", '•': "*", '€': "EUR", '£': "GBP", '¥': "JPY", '™': "TM",
+}
@@ -0,0 +1,120 @@
+package figlet
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// WriteFont writes a font in FIGfont format: the header, comment lines, the
+// required ASCII and Deutsch characters, then the remaining characters as
+// code-tagged glyphs in ascending order
+func WriteFont(w io.Writer, f *Font) error {
+	h := f.Header
+	if h.Height <= 0 {
+		return fmt.Errorf("font height must be positive")
+	}
+	signature := h.Signature
+	if signature == "" {
+		signature = SignatureFIGlet
+	}
+	hardblank := h.HardBlank
+	if hardblank == 0 {
+		hardblank = '$'
+	}
+
+	var tagged []rune
+	for code := range f.Characters {
+		if !isRequiredChar(code) {
+			tagged = append(tagged, code)
+		}
+	}
+	sort.Slice(tagged, func(i, j int) bool { return tagged[i] < tagged[j] })
+
+	maxLength := 0
+	for _, lines := range f.Characters {
+		for _, line := range lines {
+			if n := utf8.RuneCountInString(line); n > maxLength {
+				maxLength = n
+			}
+		}
+	}
+
+	var comments []string
+	if f.Comment != "" {
+		comments = strings.Split(f.Comment, "\n")
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s%c %d %d %d %d %d %d %d %d\n", signature, hardblank, h.Height, h.Baseline,
+		maxLength+2, h.OldLayout, len(comments), h.PrintDirection, h.FullLayout, len(tagged))
+	for _, line := range comments {
+		fmt.Fprintln(bw, line)
+	}
+
+	for code := rune(32); code <= 126; code++ {
+		writeGlyph(bw, f.Characters[code], h.Height, hardblank)
+	}
+	for _, code := range deutschChars {
+		// Missing Deutsch characters are written as empty glyphs
+		writeGlyph(bw, f.Characters[code], h.Height, hardblank)
+	}
+	for _, code := range tagged {
+		fmt.Fprintf(bw, "%d  U+%04X\n", code, code)
+		writeGlyph(bw, f.Characters[code], h.Height, hardblank)
+	}
+	return bw.Flush()
+}
+
+// isRequiredChar reports whether code is written without a code tag
+func isRequiredChar(code rune) bool {
+	if code >= 32 && code <= 126 {
+		return true
+	}
+	for _, c := range deutschChars {
+		if code == c {
+			return true
+		}
+	}
+	return false
+}
+
+// writeGlyph writes the rows of one FIGcharacter, padding missing rows and
+// picking an endmark that doesn't clash with the last character of any row
+func writeGlyph(w io.Writer, lines []string, height int, hardblank rune) {
+	endmark := glyphEndmark(lines, hardblank)
+	for i := 0; i < height; i++ {
+		line := ""
+		if i < len(lines) {
+			line = lines[i]
+		}
+		fmt.Fprintf(w, "%s%c", line, endmark)
+		if i == height-1 {
+			fmt.Fprintf(w, "%c", endmark)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// glyphEndmark returns the first candidate endmark that no row ends with
+func glyphEndmark(lines []string, hardblank rune) rune {
+	for _, mark := range "@#%&*|" {
+		if mark == hardblank {
+			continue
+		}
+		clash := false
+		for _, line := range lines {
+			if last, _ := utf8.DecodeLastRuneInString(strings.TrimRight(line, " \t")); last == mark {
+				clash = true
+				break
+			}
+		}
+		if !clash {
+			return mark
+		}
+	}
+	return '@'
+}
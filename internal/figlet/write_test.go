@@ -0,0 +1,74 @@
+package figlet
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteFontRoundTrip(t *testing.T) {
+	font := &Font{
+		Header: Header{
+			HardBlank:  '$',
+			Height:     2,
+			Baseline:   2,
+			OldLayout:  0,
+			FullLayout: HorizontalFitting,
+		},
+		Comment:    "Test font\nsecond line",
+		Characters: make(map[rune][]string),
+	}
+	for c := rune(32); c <= 126; c++ {
+		font.Characters[c] = []string{string(c) + " ", "██"}
+	}
+	font.Characters[' '] = []string{"$$", "$$"}
+	font.Characters['@'] = []string{" @", "@@"} // Rows ending in the usual endmark
+	font.Characters['ß'] = []string{"ß ", "▀▄"}
+	font.Characters['€'] = []string{"€=", "▀▀"}
+	font.Characters[0x1F600] = []string{":)", "  "}
+
+	var sb strings.Builder
+	if err := WriteFont(&sb, font); err != nil {
+		t.Fatalf("WriteFont() error: %v", err)
+	}
+
+	parsed, err := ParseFont(sb.String())
+	if err != nil {
+		t.Fatalf("ParseFont() of written font error: %v\n%s", err, sb.String())
+	}
+	if parsed.Header.Height != 2 || parsed.Header.FullLayout != HorizontalFitting || parsed.Header.CodetagCount != 2 {
+		t.Errorf("header = %+v", parsed.Header)
+	}
+	if parsed.Comment != font.Comment {
+		t.Errorf("Comment = %q, want %q", parsed.Comment, font.Comment)
+	}
+	if !reflect.DeepEqual(parsed.Characters, font.Characters) {
+		for code, want := range font.Characters {
+			if got := parsed.Characters[code]; !reflect.DeepEqual(got, want) {
+				t.Errorf("char %q = %q, want %q", code, got, want)
+			}
+		}
+		if len(parsed.Characters) != len(font.Characters) {
+			t.Errorf("parsed %d characters, want %d", len(parsed.Characters), len(font.Characters))
+		}
+	}
+}
+
+func TestWriteFontEmptyDeutsch(t *testing.T) {
+	font := &Font{Header: Header{Height: 1}, Characters: map[rune][]string{'A': {"A"}}}
+
+	var sb strings.Builder
+	if err := WriteFont(&sb, font); err != nil {
+		t.Fatalf("WriteFont() error: %v", err)
+	}
+	parsed, err := ParseFont(sb.String())
+	if err != nil {
+		t.Fatalf("ParseFont() error: %v", err)
+	}
+	if parsed.HasGlyph('Ä') {
+		t.Error("missing Deutsch characters should stay missing")
+	}
+	if got := parsed.Characters['A']; len(got) != 1 || got[0] != "A" {
+		t.Errorf("A = %q", got)
+	}
+}
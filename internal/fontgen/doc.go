@@ -0,0 +1,12 @@
+// Package fontgen creates FIGlet fonts from TrueType and OpenType fonts.
+//
+// Each glyph is rasterised at a height chosen so the font's line height fills the requested
+// number of terminal rows, then converted to text using full blocks, half blocks or an ASCII
+// density ramp. The embedded Go fonts can be used when no font file is given.
+//
+// Example usage:
+//
+//	ttf, _ := fontgen.GoFont("gobold")
+//	font, err := fontgen.FromTTF(ttf, fontgen.Options{Height: 6, Charset: fontgen.CharsetHalfBlock})
+//	figlet.WriteFont(file, font)
+package fontgen
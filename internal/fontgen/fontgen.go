@@ -0,0 +1,297 @@
+package fontgen
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ddmoney420/moji/internal/figlet"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Charset selects the characters glyphs are drawn with
+type Charset string
+
+const (
+	CharsetBlocks    Charset = "blocks"    // █ for filled cells
+	CharsetHalfBlock Charset = "halfblock" // ▀ ▄ █ for twice the vertical detail
+	CharsetASCII     Charset = "ascii"     // Density ramp of ASCII characters
+)
+
+// ParseCharset parses a charset name
+func ParseCharset(name string) (Charset, error) {
+	switch Charset(strings.ToLower(name)) {
+	case "", CharsetBlocks, "block":
+		return CharsetBlocks, nil
+	case CharsetHalfBlock, "half", "halfblocks":
+		return CharsetHalfBlock, nil
+	case CharsetASCII:
+		return CharsetASCII, nil
+	}
+	return "", fmt.Errorf("unknown charset %q (use blocks, halfblock, ascii)", name)
+}
+
+// asciiRamp orders ASCII characters from lightest to darkest. The hardblank
+// ($) is left out.
+const asciiRamp = " .:-=+*#%@"
+
+// hardblank is the hardblank character of generated fonts
+const hardblank = '$'
+
+// Options controls font generation
+type Options struct {
+	Height    int     // Glyph height in terminal rows
+	Charset   Charset // Characters glyphs are drawn with
+	Chars     string  // Characters to include besides ASCII and Ä Ö Ü ä ö ü ß
+	Threshold float64 // Coverage at which a cell counts as filled (0 = 0.5)
+	Source    string  // Source font name for the comment block
+}
+
+// goFonts are the embedded Go fonts by name
+var goFonts = map[string][]byte{
+	"goregular":  goregular.TTF,
+	"gobold":     gobold.TTF,
+	"gomono":     gomono.TTF,
+	"gomonobold": gomonobold.TTF,
+}
+
+// GoFont returns an embedded Go font by name
+func GoFont(name string) ([]byte, bool) {
+	ttf, ok := goFonts[strings.ToLower(name)]
+	return ttf, ok
+}
+
+// GoFontNames returns the names of the embedded Go fonts
+func GoFontNames() []string {
+	names := make([]string, 0, len(goFonts))
+	for name := range goFonts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FromTTF rasterises a TrueType or OpenType font into a FIGlet font.
+// Characters the source font has no glyph for are left out.
+func FromTTF(data []byte, opts Options) (*figlet.Font, error) {
+	if opts.Height <= 0 {
+		return nil, fmt.Errorf("height must be positive")
+	}
+	if opts.Charset == "" {
+		opts.Charset = CharsetBlocks
+	}
+	if opts.Threshold <= 0 || opts.Threshold > 1 {
+		opts.Threshold = 0.5
+	}
+
+	src, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+
+	// Every row covers two pixels, so the line height must fill twice the rows
+	pixels := opts.Height * 2
+	face, err := newFace(src, pixels)
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+	ascent := face.Metrics().Ascent.Round()
+
+	var buf sfnt.Buffer
+	characters := make(map[rune][]string)
+	for _, r := range fontRunes(opts.Chars) {
+		if r != ' ' {
+			if idx, err := src.GlyphIndex(&buf, r); err != nil || idx == 0 {
+				continue
+			}
+		}
+		bitmap, ok := rasterise(face, r, pixels, ascent)
+		if !ok {
+			continue
+		}
+		if r == ' ' {
+			characters[r] = blankGlyph(bitmap.Bounds().Dx(), opts.Height)
+			continue
+		}
+		characters[r] = cellRows(bitmap, opts.Charset, opts.Threshold)
+	}
+	if len(characters) <= 1 {
+		return nil, fmt.Errorf("font has no glyphs for the requested characters")
+	}
+
+	return &figlet.Font{
+		Header: figlet.Header{
+			Signature:  figlet.SignatureFIGlet,
+			HardBlank:  hardblank,
+			Height:     opts.Height,
+			Baseline:   (ascent + 1) / 2,
+			OldLayout:  -1, // Full width: advances already include the font's spacing
+			FullLayout: 0,
+		},
+		Comment:    comment(src, &buf, opts),
+		Characters: characters,
+	}, nil
+}
+
+// newFace returns a face whose ascent plus descent is close to pixels
+func newFace(src *opentype.Font, pixels int) (font.Face, error) {
+	size := float64(pixels)
+	face, err := opentype.NewFace(src, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load font face: %w", err)
+	}
+
+	m := face.Metrics()
+	lineHeight := float64(m.Ascent+m.Descent) / 64
+	if lineHeight <= 0 || math.Abs(lineHeight-size) < 0.5 {
+		return face, nil
+	}
+	face.Close()
+
+	size = size * size / lineHeight
+	face, err = opentype.NewFace(src, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load font face: %w", err)
+	}
+	return face, nil
+}
+
+// fontRunes returns the characters to generate: ASCII, the Deutsch
+// characters and any extra characters
+func fontRunes(extra string) []rune {
+	seen := make(map[rune]bool)
+	var runes []rune
+	add := func(r rune) {
+		if !seen[r] && r >= ' ' {
+			seen[r] = true
+			runes = append(runes, r)
+		}
+	}
+	for r := rune(32); r <= 126; r++ {
+		add(r)
+	}
+	for _, r := range "ÄÖÜäöüß" + extra {
+		add(r)
+	}
+	return runes
+}
+
+// rasterise draws one glyph into an alpha mask pixels high, wide enough for
+// both its advance and any ink outside it
+func rasterise(face font.Face, r rune, pixels, ascent int) (*image.Alpha, bool) {
+	bounds, advance, ok := face.GlyphBounds(r)
+	if !ok {
+		return nil, false
+	}
+
+	left := 0
+	if x := bounds.Min.X.Floor(); x < 0 {
+		left = x
+	}
+	right := advance.Ceil()
+	if x := bounds.Max.X.Ceil(); x > right {
+		right = x
+	}
+	width := right - left
+	if width <= 0 {
+		width = 1
+	}
+
+	img := image.NewAlpha(image.Rect(0, 0, width, pixels))
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.Opaque,
+		Face: face,
+		Dot:  fixed.P(-left, ascent),
+	}
+	d.DrawString(string(r))
+	return img, true
+}
+
+// cellRows converts a glyph mask to text, one cell per column and two pixel rows
+func cellRows(img *image.Alpha, charset Charset, threshold float64) []string {
+	b := img.Bounds()
+	rows := make([]string, 0, b.Dy()/2)
+	for y := b.Min.Y; y+1 < b.Max.Y; y += 2 {
+		var sb strings.Builder
+		for x := b.Min.X; x < b.Max.X; x++ {
+			top := float64(img.AlphaAt(x, y).A) / 255
+			bottom := float64(img.AlphaAt(x, y+1).A) / 255
+			sb.WriteRune(cellChar(top, bottom, charset, threshold))
+		}
+		rows = append(rows, sb.String())
+	}
+	return rows
+}
+
+// cellChar returns the character for a cell with the given top and bottom coverage
+func cellChar(top, bottom float64, charset Charset, threshold float64) rune {
+	switch charset {
+	case CharsetHalfBlock:
+		switch {
+		case top >= threshold && bottom >= threshold:
+			return '█'
+		case top >= threshold:
+			return '▀'
+		case bottom >= threshold:
+			return '▄'
+		}
+		return ' '
+	case CharsetASCII:
+		ramp := []rune(asciiRamp)
+		i := int((top + bottom) / 2 * float64(len(ramp)))
+		if i >= len(ramp) {
+			i = len(ramp) - 1
+		}
+		return ramp[i]
+	default:
+		if (top+bottom)/2 >= threshold {
+			return '█'
+		}
+		return ' '
+	}
+}
+
+// blankGlyph returns a glyph of hardblanks so spaces survive kerning and
+// smushing layout overrides
+func blankGlyph(width, height int) []string {
+	if width < 1 {
+		width = 1
+	}
+	rows := make([]string, height)
+	for i := range rows {
+		rows[i] = strings.Repeat(string(hardblank), width)
+	}
+	return rows
+}
+
+// comment builds the comment block, keeping the source font's copyright
+func comment(src *opentype.Font, buf *sfnt.Buffer, opts Options) string {
+	name := opts.Source
+	if family, err := src.Name(buf, sfnt.NameIDFamily); err == nil && family != "" {
+		if name == "" {
+			name = family
+		} else {
+			name = fmt.Sprintf("%s (%s)", family, name)
+		}
+	}
+
+	lines := []string{
+		fmt.Sprintf("Generated by moji from %s", name),
+		fmt.Sprintf("Height %d rows, charset %s", opts.Height, opts.Charset),
+	}
+	if copyright, err := src.Name(buf, sfnt.NameIDCopyright); err == nil && copyright != "" {
+		lines = append(lines, strings.Split(strings.ReplaceAll(copyright, "\r", ""), "\n")...)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,127 @@
+package fontgen
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ddmoney420/moji/internal/figlet"
+)
+
+func generate(t *testing.T, name string, opts Options) *figlet.Font {
+	t.Helper()
+	ttf, ok := GoFont(name)
+	if !ok {
+		t.Fatalf("GoFont(%q) not found", name)
+	}
+	font, err := FromTTF(ttf, opts)
+	if err != nil {
+		t.Fatalf("FromTTF() error: %v", err)
+	}
+	return font
+}
+
+func TestFromTTFGlyphs(t *testing.T) {
+	font := generate(t, "gomono", Options{Height: 6})
+
+	if font.Header.Height != 6 || font.Header.HardBlank != '$' {
+		t.Errorf("header = %+v", font.Header)
+	}
+	for r := rune(32); r <= 126; r++ {
+		if !font.HasGlyph(r) {
+			t.Errorf("missing glyph for %q", r)
+		}
+	}
+	if !font.HasGlyph('ß') {
+		t.Error("missing glyph for ß")
+	}
+
+	// A monospace source font gives every glyph the same width
+	width := utf8.RuneCountInString(font.Characters['M'][0])
+	for _, r := range "il.W@" {
+		lines := font.Characters[r]
+		if len(lines) != 6 {
+			t.Errorf("%q has %d rows, want 6", r, len(lines))
+		}
+		if got := utf8.RuneCountInString(lines[0]); got != width {
+			t.Errorf("%q is %d columns wide, want %d", r, got, width)
+		}
+	}
+	if space := font.Characters[' '][0]; strings.Trim(space, "$") != "" {
+		t.Errorf("space glyph = %q, want hardblanks", space)
+	}
+}
+
+func TestFromTTFCharsets(t *testing.T) {
+	tests := map[Charset]string{
+		CharsetBlocks:    " █",
+		CharsetHalfBlock: " ▀▄█",
+		CharsetASCII:     asciiRamp,
+	}
+	for charset, allowed := range tests {
+		font := generate(t, "goregular", Options{Height: 5, Charset: charset})
+		inked := false
+		for r, lines := range font.Characters {
+			if r == ' ' {
+				continue
+			}
+			for _, line := range lines {
+				for _, c := range line {
+					if !strings.ContainsRune(allowed, c) {
+						t.Fatalf("%s: glyph %q uses %q", charset, r, c)
+					}
+					inked = inked || c != ' '
+				}
+			}
+		}
+		if !inked {
+			t.Errorf("%s: glyphs are empty", charset)
+		}
+	}
+}
+
+func TestFromTTFExtraChars(t *testing.T) {
+	font := generate(t, "goregular", Options{Height: 4, Chars: "€😀"})
+	if !font.HasGlyph('€') {
+		t.Error("extra character € should be generated")
+	}
+	if font.HasGlyph('😀') {
+		t.Error("characters the source font lacks should be left out")
+	}
+
+	var sb strings.Builder
+	if err := figlet.WriteFont(&sb, font); err != nil {
+		t.Fatalf("WriteFont() error: %v", err)
+	}
+	parsed, err := figlet.ParseFont(sb.String())
+	if err != nil {
+		t.Fatalf("ParseFont() of generated font error: %v", err)
+	}
+	if !parsed.HasGlyph('€') || parsed.Header.CodetagCount != 1 {
+		t.Errorf("€ should be written as a code-tagged character, codetag count %d", parsed.Header.CodetagCount)
+	}
+	if !strings.Contains(parsed.Comment, "Go") {
+		t.Errorf("comment %q should name the source font", parsed.Comment)
+	}
+}
+
+func TestFromTTFErrors(t *testing.T) {
+	if _, err := FromTTF([]byte("not a font"), Options{Height: 4}); err == nil {
+		t.Error("FromTTF should reject invalid font data")
+	}
+	ttf, _ := GoFont("goregular")
+	if _, err := FromTTF(ttf, Options{}); err == nil {
+		t.Error("FromTTF should reject a zero height")
+	}
+}
+
+func TestParseCharset(t *testing.T) {
+	for _, c := range []Charset{CharsetBlocks, CharsetHalfBlock, CharsetASCII} {
+		if got, err := ParseCharset(string(c)); err != nil || got != c {
+			t.Errorf("ParseCharset(%q) = %q, %v", c, got, err)
+		}
+	}
+	if _, err := ParseCharset("braille"); err == nil {
+		t.Error("ParseCharset should reject unknown charsets")
+	}
+}
@@ -1,9 +1,11 @@
 package gradient
 
 import (
-	"fmt"
 	"math"
 	"strings"
+
+	"github.com/ddmoney420/moji/internal/ansi"
+	"github.com/ddmoney420/moji/internal/terminal"
 )
 
 // Theme defines a color theme
@@ -150,7 +152,7 @@ func Apply(text string, themeName string, mode string) string {
 	}
 
 	lines := strings.Split(text, "\n")
-	var result strings.Builder
+	result := ansi.NewWriter(terminal.TrueColor)
 
 	totalChars := 0
 	for _, line := range lines {
@@ -181,10 +183,11 @@ func Apply(text string, themeName string, mode string) string {
 			}
 
 			color := interpolateTheme(theme, progress)
-			result.WriteString(fmt.Sprintf("\033[38;2;%d;%d;%dm%c\033[0m", color.R, color.G, color.B, r))
+			result.SetFg(color.R, color.G, color.B)
+			result.WriteRune(r)
 			charIndex++
 		}
-		result.WriteString("\n")
+		result.WriteRune('\n')
 	}
 
 	return strings.TrimSuffix(result.String(), "\n")
@@ -198,7 +201,7 @@ func ApplyPerLine(text string, themeName string) string {
 	}
 
 	lines := strings.Split(text, "\n")
-	var result strings.Builder
+	result := ansi.NewWriter(terminal.TrueColor)
 
 	for _, line := range lines {
 		runes := []rune(line)
@@ -214,9 +217,10 @@ func ApplyPerLine(text string, themeName string) string {
 			}
 
 			color := interpolateTheme(theme, progress)
-			result.WriteString(fmt.Sprintf("\033[38;2;%d;%d;%dm%c\033[0m", color.R, color.G, color.B, r))
+			result.SetFg(color.R, color.G, color.B)
+			result.WriteRune(r)
 		}
-		result.WriteString("\n")
+		result.WriteRune('\n')
 	}
 
 	return strings.TrimSuffix(result.String(), "\n")
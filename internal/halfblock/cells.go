@@ -0,0 +1,344 @@
+package halfblock
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/ddmoney420/moji/internal/ansi"
+	"github.com/ddmoney420/moji/internal/dither"
+	"github.com/ddmoney420/moji/internal/terminal"
+)
+
+// Mode is a sub-cell rendering mode, set by how many pixels each character
+// cell holds
+type Mode string
+
+const (
+	ModeHalfBlock Mode = "halfblock" // 1x2 pixels: ▀ ▄ █
+	ModeQuadrant  Mode = "quadrant"  // 2x2 pixels: ▘ ▝ ▖ ▗ and their combinations
+	ModeSextant   Mode = "sextant"   // 2x3 pixels: block sextants (U+1FB00)
+	ModeBraille   Mode = "braille"   // 2x4 dots: braille patterns (U+2800)
+)
+
+// Modes returns the sub-cell mode names
+func Modes() []string {
+	return []string{string(ModeHalfBlock), string(ModeQuadrant), string(ModeSextant), string(ModeBraille)}
+}
+
+// ParseMode parses a sub-cell mode name
+func ParseMode(name string) (Mode, error) {
+	switch m := Mode(strings.ToLower(name)); m {
+	case ModeHalfBlock, ModeQuadrant, ModeSextant, ModeBraille:
+		return m, nil
+	case "half":
+		return ModeHalfBlock, nil
+	case "quad":
+		return ModeQuadrant, nil
+	}
+	return "", fmt.Errorf("unknown mode %q (use %s)", name, strings.Join(Modes(), ", "))
+}
+
+// CellSize returns the pixels per character cell as columns and rows
+func (m Mode) CellSize() (int, int) {
+	switch m {
+	case ModeQuadrant:
+		return 2, 2
+	case ModeSextant:
+		return 2, 3
+	case ModeBraille:
+		return 2, 4
+	default:
+		return 1, 2
+	}
+}
+
+// Glyph returns the character for a cell whose lit pixels are set in bits.
+// Pixels are numbered left to right, then top to bottom, so bit 0 is the
+// top-left pixel.
+func (m Mode) Glyph(bits int) rune {
+	switch m {
+	case ModeQuadrant:
+		return quadrantGlyphs[bits&0xF]
+	case ModeSextant:
+		return sextantGlyph(bits & 0x3F)
+	case ModeBraille:
+		return brailleGlyph(bits & 0xFF)
+	default:
+		return []rune{EmptyBlock, UpperHalf, LowerHalf, FullBlock}[bits&0x3]
+	}
+}
+
+// quadrantGlyphs is indexed by top-left=1, top-right=2, bottom-left=4, bottom-right=8
+var quadrantGlyphs = []rune(" ▘▝▀▖▌▞▛▗▚▐▜▄▙▟█")
+
+// sextantGlyph maps a 2x3 pattern to the Symbols for Legacy Computing
+// sextants, which skip the patterns that already exist as ▌ and ▐
+func sextantGlyph(bits int) rune {
+	switch bits {
+	case 0:
+		return EmptyBlock
+	case 21:
+		return '▌'
+	case 42:
+		return '▐'
+	case 63:
+		return FullBlock
+	}
+	index := bits - 1
+	if bits > 21 {
+		index--
+	}
+	if bits > 42 {
+		index--
+	}
+	return rune(0x1FB00 + index)
+}
+
+// brailleDots maps pixel order (row by row) to braille dot bits
+var brailleDots = []int{0x01, 0x08, 0x02, 0x10, 0x04, 0x20, 0x40, 0x80}
+
+// brailleGlyph maps a 2x4 pattern to a braille character
+func brailleGlyph(bits int) rune {
+	dots := 0
+	for i, dot := range brailleDots {
+		if bits&(1<<i) != 0 {
+			dots |= dot
+		}
+	}
+	return rune(0x2800 + dots)
+}
+
+// CellOptions configures RenderCells
+type CellOptions struct {
+	Mode      Mode
+	Width     int              // Output width in cells (0 = 80)
+	Height    int              // Output height in cells (0 = keep the aspect ratio)
+	Color     bool             // Block modes get fg/bg colors per cell, braille a fg color
+	Invert    bool             // Light pixels off, dark pixels on
+	Threshold float64          // Brightness above which a pixel is on (0 = 0.5)
+	Dither    dither.Algorithm // Dither pixels instead of thresholding them
+
+	ColorLevel  terminal.ColorLevel // Palette for Color: Color256 or Basic (TrueColor or 0 = 24-bit)
+	ColorDither dither.Algorithm    // Dithering of pixel colors reduced to the palette
+	BgColor     bool                // Braille: color the background with the unlit dots
+}
+
+// pixel is the average color and brightness of one sub-cell pixel
+type pixel struct {
+	r, g, b    float64
+	brightness float64
+}
+
+// RenderCells draws an image with sub-cell glyphs, giving each character
+// cell the resolution of its mode. Without color, or in braille mode, a
+// pixel is on when it is brighter than the threshold (or after dithering).
+// With color, block modes split each cell into its lighter and darker
+// pixels and draw them with the foreground and background colors.
+func RenderCells(img image.Image, opts CellOptions) string {
+	bounds := img.Bounds()
+	imgWidth := bounds.Dx()
+	imgHeight := bounds.Dy()
+	if imgWidth == 0 || imgHeight == 0 {
+		return ""
+	}
+
+	width := opts.Width
+	if width <= 0 {
+		width = 80
+	}
+	height := opts.Height
+	if height <= 0 {
+		// Character cells are about twice as tall as they are wide
+		height = int(float64(width) * float64(imgHeight) / float64(imgWidth) / 2)
+		if height < 1 {
+			height = 1
+		}
+	}
+
+	cw, ch := opts.Mode.CellSize()
+	pixels := samplePixels(img, width*cw, height*ch)
+
+	if opts.Color && opts.ColorLevel.PaletteStep() > 0 {
+		quantizePixels(pixels, opts)
+	}
+
+	var on [][]int
+	blockColor := opts.Color && opts.Mode != ModeBraille
+	if !blockColor {
+		on = pixelsOn(pixels, opts)
+	}
+
+	w := ansi.NewWriter(opts.ColorLevel)
+	for cy := 0; cy < height; cy++ {
+		for cx := 0; cx < width; cx++ {
+			cell := make([]pixel, 0, cw*ch)
+			bits := 0
+			for py := 0; py < ch; py++ {
+				for px := 0; px < cw; px++ {
+					x, y := cx*cw+px, cy*ch+py
+					cell = append(cell, pixels[y][x])
+					if on != nil && on[y][x] == 1 {
+						bits |= 1 << (py*cw + px)
+					}
+				}
+			}
+
+			switch {
+			case blockColor:
+				writeColorCell(w, opts.Mode, cell)
+			case opts.Color:
+				fg := averagePixels(cell, bits, true)
+				w.SetFg(uint8(fg.r), uint8(fg.g), uint8(fg.b))
+				if opts.BgColor {
+					bg := averagePixels(cell, bits, false)
+					w.SetBg(uint8(bg.r), uint8(bg.g), uint8(bg.b))
+				}
+				w.WriteRune(opts.Mode.Glyph(bits))
+			default:
+				w.WriteRune(opts.Mode.Glyph(bits))
+			}
+		}
+		w.WriteRune('\n')
+	}
+	return w.String()
+}
+
+// samplePixels averages the image down to a width x height grid
+func samplePixels(img image.Image, width, height int) [][]pixel {
+	bounds := img.Bounds()
+	imgWidth, imgHeight := bounds.Dx(), bounds.Dy()
+
+	pixels := make([][]pixel, height)
+	for y := 0; y < height; y++ {
+		pixels[y] = make([]pixel, width)
+		y0 := bounds.Min.Y + y*imgHeight/height
+		y1 := bounds.Min.Y + (y+1)*imgHeight/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*imgWidth/width
+			x1 := bounds.Min.X + (x+1)*imgWidth/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var p pixel
+			count := 0.0
+			for sy := y0; sy < y1 && sy < bounds.Max.Y; sy++ {
+				for sx := x0; sx < x1 && sx < bounds.Max.X; sx++ {
+					r, g, b, _ := img.At(sx, sy).RGBA()
+					p.r += float64(r >> 8)
+					p.g += float64(g >> 8)
+					p.b += float64(b >> 8)
+					count++
+				}
+			}
+			if count > 0 {
+				p.r, p.g, p.b = p.r/count, p.g/count, p.b/count
+			}
+			p.brightness = (0.299*p.r + 0.587*p.g + 0.114*p.b) / 255.0
+			pixels[y][x] = p
+		}
+	}
+	return pixels
+}
+
+// pixelsOn thresholds or dithers pixel brightness to 0 (off) or 1 (on)
+func pixelsOn(pixels [][]pixel, opts CellOptions) [][]int {
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = 0.5
+	}
+
+	// Quantize splits at 0.5, so shift the values by the threshold
+	values := make([][]float64, len(pixels))
+	for y, row := range pixels {
+		values[y] = make([]float64, len(row))
+		for x, p := range row {
+			b := p.brightness
+			if opts.Invert {
+				b = 1 - b
+			}
+			values[y][x] = b + 0.5 - threshold
+		}
+	}
+	return dither.Quantize(values, opts.Dither, 2)
+}
+
+// quantizePixels reduces pixel colors to the palette of opts.ColorLevel,
+// dithering them across the pixel grid. Brightness is left as sampled, so
+// the cell shapes don't change.
+func quantizePixels(pixels [][]pixel, opts CellOptions) {
+	colors := make([][][3]float64, len(pixels))
+	for y, row := range pixels {
+		colors[y] = make([][3]float64, len(row))
+		for x, p := range row {
+			colors[y][x] = [3]float64{p.r, p.g, p.b}
+		}
+	}
+
+	nearest := func(c [3]float64) [3]float64 {
+		r, g, b := terminal.NearestColor(opts.ColorLevel, uint8(c[0]+0.5), uint8(c[1]+0.5), uint8(c[2]+0.5))
+		return [3]float64{float64(r), float64(g), float64(b)}
+	}
+	for y, row := range dither.QuantizeColors(colors, opts.ColorDither, opts.ColorLevel.PaletteStep(), nearest) {
+		for x, c := range row {
+			pixels[y][x].r, pixels[y][x].g, pixels[y][x].b = c[0], c[1], c[2]
+		}
+	}
+}
+
+// writeColorCell splits a cell into pixels brighter than its mean and the
+// rest, drawing the bright ones in the foreground color over the others
+func writeColorCell(w *ansi.Writer, mode Mode, cell []pixel) {
+	mean := 0.0
+	for _, p := range cell {
+		mean += p.brightness
+	}
+	mean /= float64(len(cell))
+
+	bits := 0
+	for i, p := range cell {
+		if p.brightness > mean {
+			bits |= 1 << i
+		}
+	}
+
+	fg := averagePixels(cell, bits, true)
+	bg := averagePixels(cell, bits, false)
+	glyph := mode.Glyph(bits)
+	if bits == 0 {
+		// A flat cell: draw it as a full block in its only color
+		fg, glyph = bg, FullBlock
+	}
+	w.SetFg(uint8(fg.r), uint8(fg.g), uint8(fg.b))
+	w.SetBg(uint8(bg.r), uint8(bg.g), uint8(bg.b))
+	w.WriteRune(glyph)
+}
+
+// averagePixels averages the pixels that are on (or off) in bits, falling
+// back to the whole cell when there are none
+func averagePixels(cell []pixel, bits int, on bool) pixel {
+	var avg pixel
+	count := 0.0
+	for i, p := range cell {
+		if (bits&(1<<i) != 0) == on {
+			avg.r += p.r
+			avg.g += p.g
+			avg.b += p.b
+			count++
+		}
+	}
+	if count == 0 {
+		for _, p := range cell {
+			avg.r += p.r
+			avg.g += p.g
+			avg.b += p.b
+		}
+		count = float64(len(cell))
+	}
+	avg.r, avg.g, avg.b = avg.r/count, avg.g/count, avg.b/count
+	return avg
+}
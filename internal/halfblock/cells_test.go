@@ -0,0 +1,156 @@
+package halfblock
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/ddmoney420/moji/internal/terminal"
+)
+
+// leftHalfImage is white on the left half and black on the right
+func leftHalfImage(w, h int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w/2; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	return img
+}
+
+func TestModeGlyphs(t *testing.T) {
+	tests := []struct {
+		mode Mode
+		bits int
+		want rune
+	}{
+		{ModeHalfBlock, 1, '▀'},
+		{ModeHalfBlock, 2, '▄'},
+		{ModeQuadrant, 1 | 8, '▚'},
+		{ModeQuadrant, 2 | 4 | 8, '▟'},
+		{ModeSextant, 0, ' '},
+		{ModeSextant, 1, '\U0001FB00'},
+		{ModeSextant, 21, '▌'},
+		{ModeSextant, 22, '\U0001FB14'},
+		{ModeSextant, 62, '\U0001FB3B'},
+		{ModeSextant, 63, '█'},
+		{ModeBraille, 0, '⠀'},
+		{ModeBraille, 1 | 4 | 16 | 64, '⡇'},
+		{ModeBraille, 0xFF, '⣿'},
+	}
+	for _, tt := range tests {
+		if got := tt.mode.Glyph(tt.bits); got != tt.want {
+			t.Errorf("%s.Glyph(%d) = %q, want %q", tt.mode, tt.bits, got, tt.want)
+		}
+	}
+
+	// Every sextant pattern gets its own glyph
+	seen := make(map[rune]int)
+	for bits := 0; bits < 64; bits++ {
+		g := ModeSextant.Glyph(bits)
+		if prev, ok := seen[g]; ok {
+			t.Errorf("sextant patterns %d and %d share %q", prev, bits, g)
+		}
+		seen[g] = bits
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, name := range Modes() {
+		if m, err := ParseMode(name); err != nil || string(m) != name {
+			t.Errorf("ParseMode(%q) = %q, %v", name, m, err)
+		}
+	}
+	if m, err := ParseMode("QUAD"); err != nil || m != ModeQuadrant {
+		t.Errorf("ParseMode(QUAD) = %q, %v", m, err)
+	}
+	if _, err := ParseMode("hexagon"); err == nil {
+		t.Error("ParseMode should reject unknown modes")
+	}
+}
+
+func TestRenderCellsPatterns(t *testing.T) {
+	img := leftHalfImage(40, 40)
+	want := map[Mode]rune{
+		ModeHalfBlock: '█', // the cell covering the left half is fully lit
+		ModeQuadrant:  '▌',
+		ModeSextant:   '▌',
+		ModeBraille:   '⡇',
+	}
+	for mode, glyph := range want {
+		// One cell column per image half for halfblock, whose cells are 1 pixel wide
+		width := 1
+		if mode == ModeHalfBlock {
+			width = 2
+		}
+		out := RenderCells(img, CellOptions{Mode: mode, Width: width, Height: 2})
+		lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("%s: got %d lines, want 2", mode, len(lines))
+		}
+		if first := []rune(lines[0])[0]; first != glyph {
+			t.Errorf("%s: first cell = %q, want %q", mode, first, glyph)
+		}
+	}
+}
+
+func TestRenderCellsInvertAndThreshold(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = 100 // about 0.39
+	}
+	opts := CellOptions{Mode: ModeBraille, Width: 4, Height: 2}
+	if out := RenderCells(img, opts); strings.Trim(out, "⠀\n") != "" {
+		t.Errorf("pixels below the threshold should be off, got %q", out)
+	}
+	opts.Threshold = 0.3
+	if out := RenderCells(img, opts); strings.Trim(out, "⣿\n") != "" {
+		t.Errorf("pixels above a lower threshold should be on, got %q", out)
+	}
+	opts.Invert = true
+	if out := RenderCells(img, opts); strings.Trim(out, "⣿\n") != "" {
+		t.Errorf("inverted dark pixels should be on, got %q", out)
+	}
+}
+
+func TestRenderCellsColor(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	img.Set(1, 0, color.RGBA{255, 0, 0, 255})
+	// Bottom row stays black
+
+	out := RenderCells(img, CellOptions{Mode: ModeQuadrant, Width: 1, Height: 1, Color: true})
+	if !strings.Contains(out, "\033[38;2;255;0;0;48;2;0;0;0m▀") {
+		t.Errorf("quadrant color cell = %q, want red ▀ on black", out)
+	}
+
+	flat := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	for i := range flat.Pix {
+		flat.Pix[i] = 200
+	}
+	out = RenderCells(flat, CellOptions{Mode: ModeSextant, Width: 1, Height: 1, Color: true})
+	if !strings.Contains(out, "\033[38;2;200;200;200;48;2;200;200;200m█") {
+		t.Errorf("flat color cell = %q, want a full block", out)
+	}
+}
+
+func TestRenderCellsPalette(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{250, 10, 10, 255})
+	img.Set(1, 0, color.RGBA{250, 10, 10, 255})
+
+	out := RenderCells(img, CellOptions{Mode: ModeQuadrant, Width: 1, Height: 1, Color: true, ColorLevel: terminal.Color256})
+	if !strings.Contains(out, "\033[38;5;196;48;5;16m▀") {
+		t.Errorf("256-color cell = %q, want xterm red ▀ on black", out)
+	}
+	if strings.Contains(out, "38;2") {
+		t.Errorf("256-color output should have no 24-bit escapes: %q", out)
+	}
+
+	out = RenderCells(img, CellOptions{Mode: ModeBraille, Width: 1, Height: 1, Color: true, Threshold: 0.2, ColorLevel: terminal.Basic})
+	if !strings.Contains(out, "\033[91m") {
+		t.Errorf("16-color braille cell = %q, want a red foreground", out)
+	}
+}
@@ -1,11 +1,13 @@
 // Package halfblock provides half-block ASCII rendering for higher vertical resolution.
 //
 // It uses half-block characters (▀▄) to achieve 2x vertical resolution compared to standard
-// ASCII, with support for grayscale and color rendering using bilinear sampling.
+// ASCII, with support for grayscale and color rendering using bilinear sampling. RenderCells
+// generalizes this to quadrant (2x2), sextant (2x3) and braille (2x4) cells.
 //
 // Example usage:
 //
 //	result := halfblock.RenderGrayscale(img)
 //	result := halfblock.RenderColor(img)
 //	result := halfblock.RenderWithCharset(img, charset)
+//	result := halfblock.RenderCells(img, halfblock.CellOptions{Mode: halfblock.ModeQuadrant, Color: true})
 package halfblock
@@ -1,10 +1,12 @@
 package halfblock
 
 import (
-	"fmt"
 	"image"
 	"image/color"
 	"strings"
+
+	"github.com/ddmoney420/moji/internal/ansi"
+	"github.com/ddmoney420/moji/internal/terminal"
 )
 
 // Half-block characters for 2x vertical resolution
@@ -86,7 +88,7 @@ func RenderColor(img image.Image, width int) string {
 		height = 1
 	}
 
-	var sb strings.Builder
+	w := ansi.NewWriter(terminal.TrueColor)
 
 	for y := 0; y < height*2; y += 2 {
 		for x := 0; x < width; x++ {
@@ -99,13 +101,14 @@ func RenderColor(img image.Image, width int) string {
 			r2, g2, b2 := getColor(img, srcX, srcY2, bounds)
 
 			// Use upper half block with foreground=top color, background=bottom color
-			sb.WriteString(fmt.Sprintf("\033[38;2;%d;%d;%dm\033[48;2;%d;%d;%dm%c\033[0m",
-				r1, g1, b1, r2, g2, b2, UpperHalf))
+			w.SetFg(r1, g1, b1)
+			w.SetBg(r2, g2, b2)
+			w.WriteRune(UpperHalf)
 		}
-		sb.WriteString("\n")
+		w.WriteRune('\n')
 	}
 
-	return sb.String()
+	return w.String()
 }
 
 // RenderWithCharset renders using half-blocks for shape and charset for detail
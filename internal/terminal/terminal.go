@@ -1,6 +1,7 @@
 package terminal
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
@@ -342,50 +343,168 @@ func Reset() string {
 	return "\033[0m"
 }
 
-// RGBTo256 converts RGB to closest 256-color palette index
-func RGBTo256(r, g, b uint8) int {
-	// Check grayscale ramp first (232-255)
-	if r == g && g == b {
-		if r < 8 {
-			return 16 // black
-		}
-		if r > 248 {
-			return 231 // white
-		}
-		return 232 + int((float64(r)-8)/10)
+// xterm's default RGB values for the 16 system colors
+var palette16 = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the channel values of the 6x6x6 color cube (16-231)
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// PaletteRGB returns the RGB value of a 256-color palette index, using
+// xterm's defaults for the 16 system colors
+func PaletteRGB(index int) (uint8, uint8, uint8) {
+	switch {
+	case index < 0:
+		return 0, 0, 0
+	case index < 16:
+		c := palette16[index]
+		return c[0], c[1], c[2]
+	case index < 232:
+		i := index - 16
+		return cubeLevels[i/36], cubeLevels[i/6%6], cubeLevels[i%6]
+	case index < 256:
+		v := uint8(8 + 10*(index-232))
+		return v, v, v
+	default:
+		return 255, 255, 255
 	}
+}
 
-	// 6x6x6 color cube (16-231)
-	ri := int(float64(r)/255.0*5.0 + 0.5)
-	gi := int(float64(g)/255.0*5.0 + 0.5)
-	bi := int(float64(b)/255.0*5.0 + 0.5)
+// RGBTo256 converts RGB to the closest color of the cube (16-231) or the
+// grayscale ramp (232-255). The system colors are left out, since themes
+// redefine them.
+func RGBTo256(r, g, b uint8) int {
+	ri, gi, bi := nearestCubeLevel(r), nearestCubeLevel(g), nearestCubeLevel(b)
+	cube := 16 + 36*ri + 6*gi + bi
+
+	avg := (int(r) + int(g) + int(b)) / 3
+	step := (avg - 8 + 5) / 10
+	if step < 0 {
+		step = 0
+	}
+	if step > 23 {
+		step = 23
+	}
+	gray := 232 + step
 
-	return 16 + 36*ri + 6*gi + bi
+	if colorDistance(r, g, b, cube) <= colorDistance(r, g, b, gray) {
+		return cube
+	}
+	return gray
 }
 
-// RGBTo16 converts RGB to closest 16-color palette index
+// RGBTo16 converts RGB to the closest of the 16 system colors, using
+// xterm's default values
 func RGBTo16(r, g, b uint8) int {
-	// Simple threshold-based conversion
-	bright := (int(r) + int(g) + int(b)) > 382
+	best := 0
+	for index := 1; index < len(palette16); index++ {
+		if colorDistance(r, g, b, index) < colorDistance(r, g, b, best) {
+			best = index
+		}
+	}
+	return best
+}
 
-	red := r > 127
-	green := g > 127
-	blue := b > 127
+// nearestCubeLevel returns the index of the cube level closest to v
+func nearestCubeLevel(v uint8) int {
+	best := 0
+	for i, level := range cubeLevels {
+		if absDiff(v, level) < absDiff(v, cubeLevels[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// colorDistance is a perceptually weighted squared distance to a palette color
+func colorDistance(r, g, b uint8, index int) int {
+	pr, pg, pb := PaletteRGB(index)
+	dr, dg, db := int(r)-int(pr), int(g)-int(pg), int(b)-int(pb)
+	return 3*dr*dr + 4*dg*dg + 2*db*db
+}
 
-	color := 0
-	if red {
-		color |= 1
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
 	}
-	if green {
-		color |= 2
+	return int(b - a)
+}
+
+// NearestColor returns the color a terminal at the given level shows for
+// RGB. Truecolor (and NoColor) return it unchanged.
+func NearestColor(level ColorLevel, r, g, b uint8) (uint8, uint8, uint8) {
+	switch level {
+	case Color256:
+		return PaletteRGB(RGBTo256(r, g, b))
+	case Basic:
+		return PaletteRGB(RGBTo16(r, g, b))
 	}
-	if blue {
-		color |= 4
+	return r, g, b
+}
+
+// PaletteStep returns the typical distance between neighbouring palette
+// colors on one channel, or 0 when any RGB color can be shown
+func (c ColorLevel) PaletteStep() float64 {
+	switch c {
+	case Color256:
+		return 255.0 / 5 // the cube has six levels per channel
+	case Basic:
+		return 128
 	}
+	return 0
+}
 
-	if bright && color > 0 {
-		color |= 8
+// ColorCode returns the escape that sets the foreground (or background)
+// color to RGB at the given level, falling back to 24-bit color for
+// TrueColor and NoColor
+func ColorCode(level ColorLevel, r, g, b uint8, background bool) string {
+	return "\033[" + ColorParams(level, r, g, b, background) + "m"
+}
+
+// ColorParams returns the SGR parameters of ColorCode without the escape
+// around them, so several can be joined into one sequence
+func ColorParams(level ColorLevel, r, g, b uint8, background bool) string {
+	switch level {
+	case Color256:
+		if background {
+			return "48;5;" + strconv.Itoa(RGBTo256(r, g, b))
+		}
+		return "38;5;" + strconv.Itoa(RGBTo256(r, g, b))
+	case Basic:
+		index := RGBTo16(r, g, b)
+		base := 30
+		if background {
+			base = 40
+		}
+		if index >= 8 {
+			return strconv.Itoa(base + 60 + index - 8)
+		}
+		return strconv.Itoa(base + index)
 	}
+	if background {
+		return "48;2;" + strconv.Itoa(int(r)) + ";" + strconv.Itoa(int(g)) + ";" + strconv.Itoa(int(b))
+	}
+	return "38;2;" + strconv.Itoa(int(r)) + ";" + strconv.Itoa(int(g)) + ";" + strconv.Itoa(int(b))
+}
 
-	return color
+// ParseColorLevel parses a color level name: auto (detect), truecolor,
+// 256, 16 or none
+func ParseColorLevel(name string) (ColorLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "auto":
+		return Detect().ColorLevel, nil
+	case "truecolor", "24bit", "24-bit", "16m":
+		return TrueColor, nil
+	case "256", "256color", "8bit":
+		return Color256, nil
+	case "16", "16color", "basic", "ansi":
+		return Basic, nil
+	case "none", "0", "no", "off":
+		return NoColor, nil
+	}
+	return NoColor, fmt.Errorf("unknown color level %q (use auto, truecolor, 256, 16, none)", name)
 }
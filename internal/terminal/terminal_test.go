@@ -917,29 +917,58 @@ func TestRGBTo16Conversion(t *testing.T) {
 
 // Test RGBTo16 color accuracy
 func TestRGBTo16Colors(t *testing.T) {
-	// Black (0,0,0) - all values below threshold
+	// Black (0,0,0) - exact match for black
 	black := RGBTo16(0, 0, 0)
 	if black != 0 {
 		t.Errorf("RGBTo16(0, 0, 0) = %d, want 0 (black)", black)
 	}
 
-	// Red (255,0,0) - only red above threshold, sum > 382 (bright)
+	// Red (255,0,0) - exact match for xterm's bright red
 	red := RGBTo16(255, 0, 0)
-	if red != 1 {
-		t.Errorf("RGBTo16(255, 0, 0) = %d, want 1 (red)", red)
+	if red != 9 {
+		t.Errorf("RGBTo16(255, 0, 0) = %d, want 9 (bright red)", red)
 	}
 
-	// Green (0,255,0) - only green above threshold, sum > 382 (bright)
+	// Green (0,255,0) - exact match for xterm's bright green
 	green := RGBTo16(0, 255, 0)
-	if green != 2 {
-		t.Errorf("RGBTo16(0, 255, 0) = %d, want 2 (green)", green)
+	if green != 10 {
+		t.Errorf("RGBTo16(0, 255, 0) = %d, want 10 (bright green)", green)
 	}
 
-	// Blue (0,0,255) - only blue above threshold, sum > 382 (bright)
+	// Blue (0,0,255) - xterm's blue (0,0,238) is closer than bright blue (92,92,255)
 	blue := RGBTo16(0, 0, 255)
 	if blue != 4 {
 		t.Errorf("RGBTo16(0, 0, 255) = %d, want 4 (blue)", blue)
 	}
+
+	// Every system color maps to itself
+	for i, c := range palette16 {
+		if got := RGBTo16(c[0], c[1], c[2]); got != i {
+			t.Errorf("RGBTo16(%d, %d, %d) = %d, want %d", c[0], c[1], c[2], got, i)
+		}
+	}
+}
+
+// Test RGBTo16 maps grays to the nearest of black, gray, white and bright white
+func TestRGBTo16Grays(t *testing.T) {
+	tests := []struct {
+		v    uint8
+		want int
+	}{
+		{30, 0},
+		{100, 8},
+		{127, 8},
+		{128, 8},
+		{160, 8},
+		{200, 7},
+		{229, 7},
+		{250, 15},
+	}
+	for _, tt := range tests {
+		if got := RGBTo16(tt.v, tt.v, tt.v); got != tt.want {
+			t.Errorf("RGBTo16(%d, %d, %d) = %d, want %d", tt.v, tt.v, tt.v, got, tt.want)
+		}
+	}
 }
 
 // Test ColorLevel priority: COLORTERM > NO_COLOR > FORCE_COLOR
@@ -1284,3 +1313,88 @@ func TestDetectSixelRxvt256(t *testing.T) {
 		t.Error("Expected no Sixel support for rxvt")
 	}
 }
+
+func TestPaletteRGB(t *testing.T) {
+	tests := []struct {
+		index   int
+		r, g, b uint8
+	}{
+		{1, 205, 0, 0},
+		{16, 0, 0, 0},
+		{196, 255, 0, 0},
+		{231, 255, 255, 255},
+		{232, 8, 8, 8},
+		{255, 238, 238, 238},
+	}
+	for _, tt := range tests {
+		r, g, b := PaletteRGB(tt.index)
+		if r != tt.r || g != tt.g || b != tt.b {
+			t.Errorf("PaletteRGB(%d) = %d,%d,%d; want %d,%d,%d", tt.index, r, g, b, tt.r, tt.g, tt.b)
+		}
+	}
+}
+
+func TestRGBTo256Nearest(t *testing.T) {
+	tests := []struct {
+		r, g, b uint8
+		want    int
+	}{
+		{255, 0, 0, 196},
+		{95, 135, 175, 67},   // exact cube color
+		{100, 100, 100, 241}, // closer to the grey ramp than the cube
+		{248, 248, 248, 231}, // used to overflow past 255
+		{250, 250, 250, 231},
+	}
+	for _, tt := range tests {
+		if got := RGBTo256(tt.r, tt.g, tt.b); got != tt.want {
+			t.Errorf("RGBTo256(%d, %d, %d) = %d, want %d", tt.r, tt.g, tt.b, got, tt.want)
+		}
+	}
+	for v := 0; v < 256; v++ {
+		if got := RGBTo256(uint8(v), uint8(v), uint8(v)); got < 16 || got > 255 {
+			t.Fatalf("RGBTo256(%d, %d, %d) = %d, out of range", v, v, v, got)
+		}
+	}
+}
+
+func TestColorCode(t *testing.T) {
+	tests := []struct {
+		level      ColorLevel
+		background bool
+		want       string
+	}{
+		{TrueColor, false, "\033[38;2;255;0;0m"},
+		{NoColor, true, "\033[48;2;255;0;0m"},
+		{Color256, false, "\033[38;5;196m"},
+		{Color256, true, "\033[48;5;196m"},
+		{Basic, false, "\033[91m"},
+		{Basic, true, "\033[101m"},
+	}
+	for _, tt := range tests {
+		if got := ColorCode(tt.level, 255, 0, 0, tt.background); got != tt.want {
+			t.Errorf("ColorCode(%v, background=%v) = %q, want %q", tt.level, tt.background, got, tt.want)
+		}
+	}
+
+	if r, g, b := NearestColor(Basic, 200, 10, 10); r != 205 || g != 0 || b != 0 {
+		t.Errorf("NearestColor(Basic) = %d,%d,%d; want 205,0,0", r, g, b)
+	}
+}
+
+func TestParseColorLevel(t *testing.T) {
+	tests := map[string]ColorLevel{
+		"truecolor": TrueColor,
+		"24bit":     TrueColor,
+		"256":       Color256,
+		"16":        Basic,
+		"none":      NoColor,
+	}
+	for name, want := range tests {
+		if got, err := ParseColorLevel(name); err != nil || got != want {
+			t.Errorf("ParseColorLevel(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseColorLevel("65536"); err == nil {
+		t.Error("ParseColorLevel should reject unknown levels")
+	}
+}
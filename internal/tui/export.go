@@ -1,5 +1,5 @@
 // export.go handles export modal UI, file browser navigation, and export operations
-// for PNG, SVG, HTML, and TXT formats.
+// in every format registered with the export package.
 package tui
 
 import (
@@ -17,7 +17,7 @@ import (
 // ExportModal handles file path selection and export operations
 type ExportModal struct {
 	active      bool
-	format      ExportFormat
+	format      int // index into export.Exporters()
 	input       textinput.Model
 	currentDir  string
 	files       []os.DirEntry
@@ -29,8 +29,8 @@ type ExportModal struct {
 // openExportModal initializes and displays the export modal
 func (m *Model) openExportModal() {
 	m.exportModal.active = true
-	m.exportModal.format = exportPNG
-	m.exportModal.input.SetValue(generateFilename("png"))
+	m.exportModal.format = 0
+	m.exportModal.input.SetValue(generateFilename(formatExtension(export.Exporters()[0])))
 	m.exportModal.input.Focus()
 	m.exportModal.showBrowser = false
 	m.loadDirectory()
@@ -63,21 +63,15 @@ func (m Model) handleExportModalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "1":
-		m.exportModal.format = exportPNG
-		m.exportModal.input.SetValue(changeExtension(m.exportModal.input.Value(), "png"))
-		return m, nil
-	case "2":
-		m.exportModal.format = exportSVG
-		m.exportModal.input.SetValue(changeExtension(m.exportModal.input.Value(), "svg"))
-		return m, nil
-	case "3":
-		m.exportModal.format = exportHTML
-		m.exportModal.input.SetValue(changeExtension(m.exportModal.input.Value(), "html"))
-		return m, nil
-	case "4":
-		m.exportModal.format = exportTXT
-		m.exportModal.input.SetValue(changeExtension(m.exportModal.input.Value(), "txt"))
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		// Digits pick a format only while they can't be typed into the filename
+		if i := int(msg.String()[0] - '1'); m.exportModal.showBrowser && i < len(export.Exporters()) {
+			m.selectExportFormat(i)
+			return m, nil
+		}
+
+	case "ctrl+f":
+		m.selectExportFormat((m.exportModal.format + 1) % len(export.Exporters()))
 		return m, nil
 
 	case "enter":
@@ -112,16 +106,20 @@ func (m Model) handleExportModalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case "up", "k":
-		if m.exportModal.showBrowser && m.exportModal.selectedIdx > 0 {
-			m.exportModal.selectedIdx--
+		if m.exportModal.showBrowser {
+			if m.exportModal.selectedIdx > 0 {
+				m.exportModal.selectedIdx--
+			}
+			return m, nil
 		}
-		return m, nil
 
 	case "down", "j":
-		if m.exportModal.showBrowser && m.exportModal.selectedIdx < len(m.exportModal.files)-1 {
-			m.exportModal.selectedIdx++
+		if m.exportModal.showBrowser {
+			if m.exportModal.selectedIdx < len(m.exportModal.files)-1 {
+				m.exportModal.selectedIdx++
+			}
+			return m, nil
 		}
-		return m, nil
 	}
 
 	// Handle text input
@@ -134,6 +132,19 @@ func (m Model) handleExportModalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// selectExportFormat picks the i'th registered format and gives the
+// filename its extension
+func (m *Model) selectExportFormat(i int) {
+	m.exportModal.format = i
+	ext := formatExtension(export.Exporters()[i])
+	m.exportModal.input.SetValue(changeExtension(m.exportModal.input.Value(), ext))
+}
+
+// formatExtension returns a format's usual extension without the dot
+func formatExtension(e export.Exporter) string {
+	return strings.TrimPrefix(e.Extensions()[0], ".")
+}
+
 // doExport performs the actual file export
 func (m *Model) doExport() {
 	filename := m.exportModal.input.Value()
@@ -147,19 +158,13 @@ func (m *Model) doExport() {
 		filename = filepath.Join(m.exportModal.currentDir, filename)
 	}
 
-	plain := stripANSI(m.preview)
-	var err error
-
-	switch m.exportModal.format {
-	case exportPNG:
-		err = export.ToPNG(plain, filename, "#282a36", "#f8f8f2")
-	case exportSVG:
-		err = export.ToSVG(plain, filename, "#282a36", "#f8f8f2")
-	case exportHTML:
-		err = export.ToHTML(plain, filename, "#282a36", "#f8f8f2", m.textInput.Value())
-	case exportTXT:
-		err = os.WriteFile(filename, []byte(plain), 0644)
-	}
+	// Formats with color keep the preview's; text formats get it plain
+	exporter := export.Exporters()[m.exportModal.format]
+	err := exporter.Export(m.preview, filename, export.Options{
+		Title:    m.textInput.Value(),
+		PNG:      export.DefaultPNGOptions(),
+		Metadata: map[string]string{"tab": tabNames[m.currentTab]},
+	})
 
 	if err != nil {
 		m.statusMsg = fmt.Sprintf("Error: %v", err)
@@ -179,23 +184,19 @@ func (m Model) renderExportModal() string {
 
 	// Format selection
 	b.WriteString(subtitleStyle.Render("Format: "))
-	formats := []struct {
-		key  string
-		name string
-		ext  string
-	}{
-		{"1", "PNG", "png"},
-		{"2", "SVG", "svg"},
-		{"3", "HTML", "html"},
-		{"4", "TXT", "txt"},
-	}
-	for i, f := range formats {
+	for i, e := range export.Exporters() {
 		style := dimStyle
-		if i == int(m.exportModal.format-1) {
+		if i == m.exportModal.format {
 			style = selectedStyle
 		}
-		b.WriteString(style.Render(fmt.Sprintf("[%s]%s ", f.key, f.name)))
+		label := strings.ToUpper(e.Name())
+		if i < 9 && m.exportModal.showBrowser {
+			label = fmt.Sprintf("[%d]%s", i+1, label)
+		}
+		b.WriteString(style.Render(label + " "))
 	}
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render(export.Exporters()[m.exportModal.format].Description() + "  [Ctrl+F] Next format"))
 	b.WriteString("\n\n")
 
 	// Path input
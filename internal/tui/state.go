@@ -28,17 +28,6 @@ const (
 	tabCount
 )
 
-// ExportFormat represents supported export formats
-type ExportFormat int
-
-const (
-	exportNone ExportFormat = iota
-	exportPNG
-	exportSVG
-	exportHTML
-	exportTXT
-)
-
 var tabNames = []string{
 	"Banner", "Kaomoji", "ArtDB", "Filters", "Effects", "Gradient", "QR", "Patterns", "Speech", "Calendar", "Sysinfo",
 }
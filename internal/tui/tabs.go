@@ -205,9 +205,23 @@ func (m Model) renderBannerTab() string {
 	b.WriteString("  ")
 	b.WriteString(styleList)
 
+	if len(m.filteredFonts) > 0 {
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render(fontSummary(m.fontMeta[m.filteredFonts[m.selectedFont]])))
+	}
+
 	return b.String()
 }
 
+// fontSummary describes a font in one line for the banner tab
+func fontSummary(meta banner.FontMeta) string {
+	parts := []string{meta.Desc, meta.Category, fmt.Sprintf("%d rows", meta.Height), meta.Layout}
+	if len(meta.Tags) > 0 {
+		parts = append(parts, strings.Join(meta.Tags, ", "))
+	}
+	return "  " + strings.Join(parts, " · ")
+}
+
 func (m Model) renderKaomojiTab() string {
 	var b strings.Builder
 
@@ -36,7 +36,7 @@ type Model struct {
 
 	// Banner tab
 	fonts         []string
-	fontDescs     []string
+	fontMeta      []banner.FontMeta
 	filteredFonts []int
 	colorStyles   []string
 	selectedFont  int
@@ -111,13 +111,11 @@ func NewModel() Model {
 	si.Width = 25
 
 	// Get fonts
-	fontInfos := banner.ListFonts()
-	fonts := make([]string, len(fontInfos))
-	fontDescs := make([]string, len(fontInfos))
-	filteredFonts := make([]int, len(fontInfos))
-	for i, f := range fontInfos {
+	fontMeta := banner.Catalog()
+	fonts := make([]string, len(fontMeta))
+	filteredFonts := make([]int, len(fontMeta))
+	for i, f := range fontMeta {
 		fonts[i] = f.Name
-		fontDescs[i] = f.Desc
 		filteredFonts[i] = i
 	}
 
@@ -191,7 +189,7 @@ func NewModel() Model {
 		textInput:       ti,
 		searchInput:     si,
 		fonts:           fonts,
-		fontDescs:       fontDescs,
+		fontMeta:        fontMeta,
 		filteredFonts:   filteredFonts,
 		colorStyles:     colorStyles,
 		kaomojiList:     kaomojiList,
@@ -268,12 +266,13 @@ func (m *Model) filterCurrentList() {
 	}
 }
 
-// filterFonts filters the font list based on search input
+// filterFonts filters the font list by name, category or tag
 func (m *Model) filterFonts() {
 	search := strings.ToLower(m.searchInput.Value())
 	m.filteredFonts = m.filteredFonts[:0]
 	for i, font := range m.fonts {
-		if search == "" || strings.Contains(strings.ToLower(font), search) {
+		if search == "" || strings.Contains(strings.ToLower(font), search) ||
+			(banner.FontFilter{Category: search}).Match(m.fontMeta[i]) {
 			m.filteredFonts = append(m.filteredFonts, i)
 		}
 	}
@@ -59,3 +59,27 @@ func TestCtrlCQuits(t *testing.T) {
 		t.Fatalf("Expected QuitMsg from ctrl+c, got %T", msg)
 	}
 }
+
+func TestExportFilenameAcceptsDigits(t *testing.T) {
+	m := NewModel()
+	m.openExportModal()
+	m.exportModal.input.SetValue("")
+
+	for _, r := range "art2k.txt" {
+		updated, _ := m.handleExportModalKey(tea.KeyMsg(tea.Key{Type: tea.KeyRunes, Runes: []rune{r}}))
+		m = updated.(Model)
+	}
+	if got := m.exportModal.input.Value(); got != "art2k.txt" {
+		t.Errorf("filename = %q, want %q", got, "art2k.txt")
+	}
+	if m.exportModal.format != 0 {
+		t.Errorf("typing a digit changed the format to %d", m.exportModal.format)
+	}
+
+	m.exportModal.showBrowser = true
+	m.exportModal.input.Blur()
+	updated, _ := m.handleExportModalKey(tea.KeyMsg(tea.Key{Type: tea.KeyRunes, Runes: []rune{'2'}}))
+	if m = updated.(Model); m.exportModal.format != 1 {
+		t.Errorf("digit with the browser open selected format %d, want 1", m.exportModal.format)
+	}
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"os"
 
+	"github.com/ddmoney420/moji/internal/banner"
+	"github.com/ddmoney420/moji/internal/cast"
+	"github.com/ddmoney420/moji/internal/config"
 	"github.com/ddmoney420/moji/internal/tui"
 	"github.com/ddmoney420/moji/internal/ux"
 	"github.com/spf13/cobra"
@@ -16,6 +19,7 @@ var (
 	alignFlag   string
 	widthFlag   int
 	outputFlag  string
+	formatFlag  string
 	jsonFlag    bool
 	bgColorFlag string
 	fgColorFlag string
@@ -23,8 +27,21 @@ var (
 	verboseFlag bool
 	noColorFlag bool
 	watchFlag   bool
+	recordFlag  string
+
+	// SAUCE metadata for .ans output
+	sauceTitleFlag  string
+	sauceAuthorFlag string
+	sauceGroupFlag  string
+
+	// Text rendering for .png output
+	fontSizeFlag float64
+	paddingFlag  int
 )
 
+// recorder captures stdout for --record until moji exits
+var recorder *cast.Recorder
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "moji [name]",
@@ -48,6 +65,20 @@ Run 'moji --help' for full command list, or 'moji doctor' to check your setup.`,
 			if noColorFlag {
 				ux.NoColor = true
 			}
+			if recordFlag != "" {
+				r, err := cast.Record(recordFlag, cmd.CommandPath())
+				if err != nil {
+					ux.Error("Failed to start recording: %v", err)
+					os.Exit(1)
+				}
+				recorder = r
+			}
+			if cfg, err := config.Load(); err == nil {
+				banner.SetFontPaths(cfg.FontPaths)
+				if cfg.FontCacheSize > 0 {
+					banner.SetCacheCapacity(cfg.FontCacheSize)
+				}
+			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			if len(args) == 1 {
@@ -66,6 +97,7 @@ Run 'moji --help' for full command list, or 'moji doctor' to check your setup.`,
 	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "Suppress non-essential output")
 	rootCmd.PersistentFlags().BoolVarP(&verboseFlag, "verbose", "v", false, "Enable verbose output")
 	rootCmd.PersistentFlags().BoolVar(&noColorFlag, "no-color", false, "Disable colored output")
+	rootCmd.PersistentFlags().StringVar(&recordFlag, "record", "", "Record the output as an asciinema .cast file")
 
 	// Interactive TUI command
 	interactiveCmd := &cobra.Command{
@@ -75,7 +107,7 @@ Run 'moji --help' for full command list, or 'moji doctor' to check your setup.`,
 		Long:    `Launch an interactive TUI for creating ASCII art with live preview, font selection, and more.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			if err := tui.Run(); err != nil {
-				os.Exit(1)
+				exit(1)
 			}
 		},
 	}
@@ -90,6 +122,7 @@ Run 'moji --help' for full command list, or 'moji doctor' to check your setup.`,
 		// Banner
 		newBannerCmd(),
 		newListFontsCmd(),
+		newFontsCmd(),
 		newPreviewCmd(),
 		// Effects & Filters
 		newEffectsCmd(),
@@ -101,6 +134,7 @@ Run 'moji --help' for full command list, or 'moji doctor' to check your setup.`,
 		newArtdbCmd(),
 		newFortuneCmd(),
 		newSayCmd(),
+		newViewCmd(),
 		// Convert
 		newConvertCmd(),
 		newListCharsetsCmd(),
@@ -113,6 +147,7 @@ Run 'moji --help' for full command list, or 'moji doctor' to check your setup.`,
 		newPatternCmd(),
 		newListPatternsCmd(),
 		newAnimateCmd(),
+		newPlayCmd(),
 		newSysinfoCmd(),
 		newTreeCmd(),
 		newCalCmd(),
@@ -125,6 +160,21 @@ Run 'moji --help' for full command list, or 'moji doctor' to check your setup.`,
 	)
 
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		exit(1)
+	}
+	exit(0)
+}
+
+// exit finishes any recording and exits
+func exit(code int) {
+	if recorder != nil {
+		if err := recorder.Stop(); err != nil {
+			ux.Error("Failed to save recording: %v", err)
+			code = 1
+		} else {
+			ux.Success("Recorded to %s", recordFlag)
+		}
+		recorder = nil
 	}
+	os.Exit(code)
 }